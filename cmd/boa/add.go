@@ -16,97 +16,405 @@ package main
 import (
 	"fmt"
 	"os"
-	"time"
+	"path/filepath"
+	"strings"
 
 	"github.com/oncilla/boa/pkg/gen"
+	"github.com/oncilla/boa/pkg/source"
+	"github.com/oncilla/boa/pkg/tmpl"
 	"github.com/spf13/cobra"
 )
 
 func newAdd(pather CommandPather) *cobra.Command {
 	var flags struct {
-		author  string
-		license string
-		path    string
-		flags   []string
+		author    string
+		license   string
+		path      string
+		parent    string
+		flags     []string
+		flag      []string
+		args      []string
+		register  bool
+		vars      map[string]string
+		test      bool
+		config    bool
+		fromType  string
+		fromFunc  string
+		call      string
+		preview   preview
+		overwrite overwrite
 	}
 
-	help := `  %[1]s add ping --flags count:int,interval:duration
-  %[1]s add pong --license apache`
+	help := `  %[1]s add ping --flags 'count:int[1..]=4,interval:duration=1s'
+  %[1]s add get --flags 'url:string(regexp=^https://)!,out:path(dir)'
+  %[1]s add greet --flags 'name:string=alice,n!'
+  %[1]s add greet --flag 'name:string short=n required usage="who to greet"'
+  %[1]s add copy --args src:path,dst:path,extra:...string
+  %[1]s add serve --config --flags 'addr:ip=127.0.0.1,port:uint16[1..]=8080'
+  %[1]s add serve --from-type ./internal/server.Options --call Run
+  %[1]s add --from-func ./internal/greet.Greet
+  %[1]s add pong --license apache
+  %[1]s add add --parent remote
+  %[1]s add remote/add/all`
 
 	var cmd = &cobra.Command{
-		Use:     "add <command name>",
+		Use:     "add <command path>",
 		Aliases: []string{"cmd", "command"},
 		Short:   "Add a command to a cobra application",
 		Long: `Add a command to the cobra application.
 
 The command is added to the main package of the cobra application. If main is
 not located in the current working directory, supply the path with the appropriate
-flag.
+flag. If the project manifest (.boa.yaml) is found in the working directory or
+one of its parents, the path, author and license default to the values in the
+manifest.
 
 The license header is automatically added to the file. If you choose to not add
 a license header, supply 'none' to the flag. Likewise, the copyright line can
 be disabled by supplying an empty author name.
 
-The generated command needs to be registered with the root command in the main
-function.
+Subcommands of existing commands are added by supplying the parent with the
+appropriate flag, or by specifying the full command path separated by '/'. For
+example, both 'add --parent remote' and 'remote/add' create the command
+'remote add' in the file remote_add.go with the constructor newRemoteAdd.
+Deeper nesting is supported by separating the parents with '/' as well.
+
+A table-driven test is generated alongside the command, e.g., remote_add_test.go
+with TestRemoteAdd. It executes the command once without flags and once for
+every generated flag, and checks that unknown flags are rejected. To skip the
+test, set the 'test' flag to false.
+
+The command is rendered from the command.go.tmpl template, the test from the
+command_test.go.tmpl template. The built-in templates can be overridden in the
+user or project template directory, see 'boa init --help'. Custom variables
+from the manifest are available to the templates, and can be overridden with
+the 'var' flag.
+
+The generated command is registered with its parent by adding it to the
+AddCommand call in the main function, or in the constructor of the parent
+command. To skip the registration, set the 'register' flag to false.
+
+Existing commands are only regenerated if the 'force' or 'backup' flag is set.
+The former overwrites the file, the latter keeps a copy with the .bak suffix.
+With the 'skip-existing' flag, existing files are kept.
+
+To preview the command without writing it, set the 'dry-run' flag to print the
+generated files, or the 'diff' flag to print the diff against the files on
+disk. For existing commands, the rendered file is compared to the one on disk.
+In both cases, the command fails if the files differ from the ones on disk.
 
 This command supports adding flags to the generated command. To do so, specify
-the desired flags as a comma separated list of 'name:type' pairs. In addition
-to the basic go types, 'net.IP' and 'time.Duration' are supported with the type
-identifiers 'ip' and 'duration'.
+the desired flags as a comma separated list in the compact notation:
+
+  name:type[=default][,short][!]
+
+In addition to the basic go types, 'net.IP' and 'time.Duration' are supported
+with the type identifiers 'ip' and 'duration'. Enum flags list their allowed
+values separated by '|', e.g., 'mode:enum(fast|safe)'. Unless set otherwise,
+the first value is the default. The allowed values are offered as shell
+completion. The optional default is followed by the optional one letter
+shorthand, and an exclamation mark marks the flag as required. Defaults that contain ',' or '!' must be
+quoted, e.g., 'tags:[]string="a,b"'.
 
 For example:
 
-  names:[]string,addr:ip,interval:duration
+  names:[]string,addr:ip=127.0.0.1,a,interval:duration=1m!
 
 Creates a command that supports the following flags:
 
   Flags:
-        --names    strings    names description
-        --addr     ip         addr description
-        --interval duration   interval description
+    -a, --addr ip             addr description (default 127.0.0.1)
+        --interval duration   interval description (default 1m0s)
+        --names strings       names description
+
+The type can be followed by a constraint. Numeric and duration flags accept an
+inclusive range, e.g., 'port:uint16[1..65535]' or 'timeout:duration[1s..]'.
+Path flags check that the path exists, or that it is a file or a directory,
+e.g., 'file:path(exists)'. String flags can be restricted by a regular
+expression, e.g., 'url:string(regexp=^https://)'. Regular expressions that
+contain ')' must be quoted, e.g., with backticks. The generated command checks
+the constraints of the flags that are set on the command line before it
+silences the usage message. Defaults are checked on generation. Without an
+explicit default, the zero value must satisfy the constraint, unless the flag
+is required.
+
+Alternatively, each flag can be specified with the repeatable 'flag' flag in
+the long notation. It supports setting the usage text:
+
+  name:string default=alice short=n required usage="who to greet"
+
+Positional arguments are specified as comma separated list of 'name:type'
+pairs. Optional arguments are marked with a trailing '?', and the last
+argument can take all remaining values by prefixing its type with '...':
+
+  src:path,dst:path?,extra:...string
+
+The usage line, the validation of the number of arguments and the parsing
+into a struct with one field per argument are generated. The supported types
+are string, path, bool, int, int64, uint64, float64 and duration. Path
+arguments are completed with file names.
+
+With the 'config' flag, the flags are generated as fields of a config struct,
+e.g., serveConfig with the defaults returned by defaultServeConfig. The config
+is wired up with pkg/boa, such that the values can also be set by environment
+variables and a config file, which is passed with the generated 'config' flag.
+The environment variables are prefixed with the application name from the
+manifest, or the name of the main package directory, e.g., MY_APP_PORT. The
+constraints are checked for all values that differ from the defaults.
+Shorthands, required flags and slices of types other than bool, int, int32,
+int64, uint and string are not supported in config structs. The command is
+rendered from the command_config.go.tmpl template.
+
+With the 'from-type' flag, the flags are generated from the exported fields of
+an existing struct type, e.g., './internal/server.Options'. The package is
+referenced by its import path, or by its directory relative to the working
+directory. It is loaded and type-checked from source. Each field becomes a flag
+named after the field with dashes, e.g., 'shutdown-timeout' for
+ShutdownTimeout, with the usage taken from the doc comment of the field. Besides the supported flag
+types, named types thereof and types that implement pflag.Value, such as the
+types in pkg/boa/flag, are supported. The generated command builds the options
+from the flags and passes them to the function named with the 'call' flag. The
+function is located in the same package and has the signature:
+
+  func([context.Context, ]Options) error
+
+The options can also be passed by pointer, and the error result is optional.
+The context is the one of the command.
+
+With the 'from-func' flag, a command is generated that wraps an existing
+function, e.g., './internal/greet.Greet'. Unless the command path is supplied,
+the command is named after the function, e.g., 'do-thing' for DoThing. The
+parameters of the function are mapped as follows: A leading context.Context is
+the context of the command. An options struct, or a pointer to it, declared in
+the same package is built from flags, as with the 'from-type' flag. Parameters
+of the types string, bool, int, int64, uint64, float64 and time.Duration become
+positional arguments. A variadic parameter takes all remaining arguments.
+
+The function returns at most one value, optionally followed by an error. The
+value is printed in the format selected with the generated 'output' flag,
+either text or json. A returned error is returned by the command. If it
+implements 'ExitCode() int', the main function generated by 'boa init' exits
+with that code.
 		`,
-		Args:    cobra.ExactArgs(1),
+		Args:    cobra.MaximumNArgs(1),
 		Example: fmt.Sprintf(help, pather.CommandPath()),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			path := flags.path
-			if path == "" {
-				var err error
-				if path, err = os.Getwd(); err != nil {
-					return err
-				}
+			path, m, err := project(cmd, flags.path, map[string]*string{
+				"author":  &flags.author,
+				"license": &flags.license,
+			})
+			if err != nil {
+				return err
+			}
+			policy, err := flags.overwrite.policy()
+			if err != nil {
+				return err
+			}
+			generated := flags.fromType != "" || flags.fromFunc != ""
+			switch {
+			case flags.fromType != "" && flags.fromFunc != "":
+				return fmt.Errorf("the 'from-type' and 'from-func' flags are mutually exclusive")
+			case flags.fromType != "" && flags.call == "":
+				return fmt.Errorf("the 'from-type' flag requires the 'call' flag")
+			case flags.fromType == "" && flags.call != "":
+				return fmt.Errorf("the 'call' flag requires the 'from-type' flag")
+			case generated && (len(flags.flags) > 0 || len(flags.flag) > 0 || flags.config):
+				return fmt.Errorf("the 'from-type' and 'from-func' flags cannot be combined " +
+					"with the 'flags', 'flag' and 'config' flags")
+			case flags.fromFunc != "" && len(flags.args) > 0:
+				return fmt.Errorf("the 'from-func' flag cannot be combined with the 'args' flag")
+			case len(args) == 0 && flags.fromFunc == "":
+				return fmt.Errorf("missing command path, it can only be omitted with the 'from-func' flag")
 			}
 			cmd.SilenceUsage = true
 			license, err := gen.FindLicense(flags.license)
 			if err != nil {
 				return err
 			}
-			cmdFlags, imports, err := gen.ParseFlags(flags.flags)
+			var wrapped gen.Command
+			switch {
+			case flags.fromType != "":
+				wrapped, err = fromType(flags.fromType, flags.call)
+			case flags.fromFunc != "":
+				wrapped, err = fromFunc(flags.fromFunc)
+			default:
+				wrapped.Flags, wrapped.Imports, err = gen.ParseFlags(flags.flags, flags.flag)
+				if err != nil {
+					return err
+				}
+				var argImports []string
+				wrapped.Args, argImports, err = gen.ParseArgs(flags.args)
+				wrapped.Imports = append(wrapped.Imports, argImports...)
+			}
+			if err != nil {
+				return err
+			}
+			cmdPath := wrapped.Name
+			if len(args) > 0 {
+				cmdPath = args[0]
+			}
+			parents, name, err := gen.ParseCommandPath(strings.Trim(flags.parent+"/"+cmdPath, "/"))
+			if err != nil {
+				return err
+			}
+			templates, err := loadTemplates(tmpl.Builtin(), projectTemplateDir(m, path))
 			if err != nil {
 				return err
 			}
 			g := gen.Command{
 				Copyright: gen.Copyright{
-					Year:   time.Now().Year(),
+					Year:   now().Year(),
 					Author: flags.author,
 				},
-				License: license,
-				Name:    args[0],
-				Flags:   cmdFlags,
-				Imports: imports,
+				License:   license,
+				Name:      name,
+				Parents:   parents,
+				Flags:     wrapped.Flags,
+				Args:      wrapped.Args,
+				Config:    flags.config,
+				EnvPrefix: gen.EnvPrefix(appName(m, path)),
+				Call:      wrapped.Call,
+				Imports:   wrapped.Imports,
+				Vars:      vars(m, flags.vars),
+				Templates: templates,
+			}
+			pkg, err := source.Load(path)
+			if err != nil {
+				return err
+			}
+			// Existing commands can only be regenerated in their own file.
+			_, fn, exists := pkg.Func(g.Constructor())
+			inFile := exists && filepath.Base(pkg.Fset.Position(fn.Pos()).Filename) == g.FileName()
+			if exists && !flags.preview.enabled() && (policy == source.Fail || !inFile) {
+				return fmt.Errorf("constructor %s already exists in %s",
+					g.Constructor(), pkg.Position(fn))
+			}
+			if _, _, ok := pkg.Func(g.ParentConstructor()); !ok && len(parents) > 0 {
+				return fmt.Errorf("parent command %s not found: constructor %s does not exist",
+					strings.Join(parents, " "), g.ParentConstructor())
+			}
+			file := filepath.Join(path, g.FileName())
+			change, err := g.Render(file)
+			if err != nil {
+				return err
+			}
+			changes := []source.Change{change}
+			if flags.test {
+				test, err := g.RenderTest(filepath.Join(path, g.TestFileName()))
+				if err != nil {
+					return err
+				}
+				changes = append(changes, test)
+			}
+			// Existing commands are already registered.
+			var updates []source.Change
+			var registerErr error
+			if flags.register && !exists {
+				parent, out, err := pkg.Register(g.ParentConstructor(), g.Constructor())
+				if err == nil {
+					updates = append(updates, source.Change{Path: parent.Path, Old: parent.Src, New: out})
+					// The test of the parent must not pass the placeholder
+					// argument once the parent has subcommands.
+					test, ok, err := pkg.NestedTest(g.ParentConstructor())
+					if err != nil {
+						return err
+					}
+					if ok {
+						updates = append(updates, test)
+					}
+				}
+				registerErr = err
+			}
+			if flags.preview.enabled() {
+				return flags.preview.show(cmd.OutOrStdout(), path, append(changes, updates...))
 			}
-			name := fmt.Sprintf("%s/%s.go", path, args[0])
-			if err := g.Create(name); err != nil {
+			if err := writeChanges(cmd.OutOrStdout(), policy, changes, updates...); err != nil {
 				return err
 			}
-			fmt.Println("Created command at", name)
-			fmt.Println("Make sure to register it with the root command")
+			for i, kind := range []string{"command", "test"}[:len(changes)] {
+				if changes[i].Old == nil {
+					fmt.Fprintf(cmd.OutOrStdout(), "Created %s at %s\n", kind, changes[i].Path)
+				}
+			}
+			switch {
+			case exists:
+			case !flags.register:
+				fmt.Fprintln(cmd.OutOrStdout(), "Make sure to register it with its parent command")
+			case registerErr != nil:
+				fmt.Fprintln(cmd.OutOrStdout(), "Could not register the command automatically:", registerErr)
+				fmt.Fprintln(cmd.OutOrStdout(), "Make sure to register it with its parent command")
+			default:
+				fmt.Fprintln(cmd.OutOrStdout(), "Registered command in", updates[0].Path)
+			}
 			return nil
 		},
 	}
 	cmd.Flags().StringVarP(&flags.author, "author", "a", "YOUR_NAME", "author name for copyright attribution")
 	cmd.Flags().StringVarP(&flags.license, "license", "l", "apache", "name of license for the project")
 	cmd.Flags().StringVarP(&flags.path, "path", "p", "", "path to main package")
-	cmd.Flags().StringSliceVar(&flags.flags, "flags", nil, `flags to generate as comma separated list`)
+	cmd.Flags().StringVar(&flags.parent, "parent", "", "path of the parent command, e.g., remote/add")
+	cmd.Flags().StringArrayVar(&flags.flags, "flags", nil, "flags to generate as comma separated list")
+	cmd.Flags().StringArrayVar(&flags.flag, "flag", nil, "flag to generate in the long notation, can be repeated")
+	cmd.Flags().StringArrayVar(&flags.args, "args", nil, "positional arguments to generate as comma separated list")
+	cmd.Flags().BoolVar(&flags.register, "register", true, "register the command with its parent command")
+	cmd.Flags().BoolVar(&flags.test, "test", true, "generate a test for the command")
+	cmd.Flags().BoolVar(&flags.config, "config", false, "generate the flags as fields of a config struct")
+	cmd.Flags().StringVar(&flags.fromType, "from-type", "", "generate the flags from a struct type, e.g., ./internal/server.Options")
+	cmd.Flags().StringVar(&flags.call, "call", "", "function that is called with the struct built from the flags")
+	cmd.Flags().StringVar(&flags.fromFunc, "from-func", "", "generate a command that wraps a function, e.g., ./internal/greet.Greet")
+	cmd.Flags().StringToStringVar(&flags.vars, "var", nil, "custom template variables as key=value pairs")
+	flags.preview.register(cmd.Flags())
+	flags.overwrite.register(cmd.Flags())
 	return cmd
 }
+
+// fromType generates the flags from the struct type referenced by ref, and the
+// call of the function with the given name. The package of the type is loaded
+// relative to the working directory. The imports include the package.
+func fromType(ref, fn string) (gen.Command, error) {
+	pkg, r, err := loadRef(ref)
+	if err != nil {
+		return gen.Command{}, err
+	}
+	flags, imports, err := pkg.StructFlags(r.Name)
+	if err != nil {
+		return gen.Command{}, err
+	}
+	call, err := pkg.Call(fn, r.Name)
+	if err != nil {
+		return gen.Command{}, err
+	}
+	return gen.Command{
+		Flags:   flags,
+		Imports: append(imports, pkg.Path),
+		Call:    call,
+	}, nil
+}
+
+// fromFunc generates the command that wraps the function referenced by ref.
+// The package of the function is loaded relative to the working directory.
+func fromFunc(ref string) (gen.Command, error) {
+	pkg, r, err := loadRef(ref)
+	if err != nil {
+		return gen.Command{}, err
+	}
+	return pkg.WrapFunc(r.Name)
+}
+
+// loadRef loads the package of the referenced declaration relative to the
+// working directory.
+func loadRef(ref string) (*gen.GoPackage, gen.Ref, error) {
+	r, err := gen.ParseRef(ref)
+	if err != nil {
+		return nil, gen.Ref{}, err
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, gen.Ref{}, err
+	}
+	pkg, err := gen.LoadPackage(wd, r.Package)
+	if err != nil {
+		return nil, gen.Ref{}, err
+	}
+	return pkg, r, nil
+}
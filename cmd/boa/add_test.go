@@ -14,11 +14,14 @@
 package main
 
 import (
+	"bytes"
 	"flag"
 	"io/ioutil"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -28,15 +31,33 @@ import (
 
 var update = flag.Bool("update", false, "set to true to regenerate golden files")
 
+func TestMain(m *testing.M) {
+	// The golden files are generated with a fixed copyright year.
+	now = func() time.Time { return time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC) }
+	boaVersion = func() string { return "v0.0.0-20200101000000-000000000000" }
+	// The user templates must not influence the generated files.
+	config, err := ioutil.TempDir("", "config")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv("XDG_CONFIG_HOME", config)
+	code := m.Run()
+	os.RemoveAll(config)
+	os.Exit(code)
+}
+
 func TestAdd(t *testing.T) {
 	var dir string
 	if !*update {
-		var err error
-		dir, err = ioutil.TempDir("", "add")
+		tmp, err := ioutil.TempDir("", "add")
 		require.NoError(t, err)
 		defer func() {
-			require.NoError(t, os.RemoveAll(dir))
+			require.NoError(t, os.RemoveAll(tmp))
 		}()
+		// The environment variable prefix is derived from the directory
+		// name, which must match the golden directory.
+		dir = filepath.Join(tmp, "add")
+		require.NoError(t, os.MkdirAll(dir, 0755))
 	} else {
 		dir = "testdata/add"
 		require.NoError(t, os.RemoveAll(dir))
@@ -48,12 +69,55 @@ func TestAdd(t *testing.T) {
 		"--author", "my-name",
 		"--license", "apache",
 		"--path", dir,
-		"--flags", "addr:ip,port:uint16",
+		"--flags", "addr:ip,port:uint16[1..65535]=8080,p,mode:enum(fast|safe),config:path(file)",
+		"--flag", `name:string default=alice short=n required usage="who to greet"`,
 		"serve",
 	})
 	err := cmd.Execute()
 	require.NoError(t, err)
 
+	cmd = newAdd(boa.Pather("parent path"))
+	cmd.SetArgs([]string{
+		"--author", "my-name",
+		"--license", "apache",
+		"--path", dir,
+		"--args", "src:path,count:int?,extra:...duration",
+		"copy",
+	})
+	require.NoError(t, cmd.Execute())
+
+	cmd = newAdd(boa.Pather("parent path"))
+	cmd.SetArgs([]string{
+		"--author", "my-name",
+		"--license", "apache",
+		"--path", dir,
+		"--config",
+		"--flags", "addr:ip=127.0.0.1,port:uint16[1..65535]=8080,level:enum(info|debug)",
+		"--flag", `shutdownTimeout:duration default=10s usage="time to wait for connections to close"`,
+		"listen",
+	})
+	require.NoError(t, cmd.Execute())
+
+	cmd = newAdd(boa.Pather("parent path"))
+	cmd.SetArgs([]string{
+		"--author", "my-name",
+		"--license", "apache",
+		"--path", dir,
+		"--from-type", "../../pkg/gen/testdata/options.Options",
+		"--call", "Run",
+		"start",
+	})
+	require.NoError(t, cmd.Execute())
+
+	cmd = newAdd(boa.Pather("parent path"))
+	cmd.SetArgs([]string{
+		"--author", "my-name",
+		"--license", "apache",
+		"--path", dir,
+		"--from-func", "../../pkg/gen/testdata/options.Greet",
+	})
+	require.NoError(t, cmd.Execute())
+
 	files, err := filepath.Glob("testdata/add/*")
 	require.NoError(t, err)
 
@@ -66,3 +130,193 @@ func TestAdd(t *testing.T) {
 		assert.Equal(t, string(golden), string(created))
 	}
 }
+
+func TestAddRegister(t *testing.T) {
+	dir, err := ioutil.TempDir("", "add-register")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	initCmd := newInit(boa.Pather("boa"))
+	initCmd.SetArgs([]string{"--path", dir, "app"})
+	require.NoError(t, initCmd.Execute())
+
+	cmd := newAdd(boa.Pather("boa"))
+	cmd.SetArgs([]string{"--path", dir, "greet"})
+	require.NoError(t, cmd.Execute())
+
+	root, err := ioutil.ReadFile(filepath.Join(dir, "app.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(root), `	cmd.AddCommand(
+		newCompletion(cmd),
+		newGreet(cmd),
+		newVersion(cmd),
+	)`)
+
+	// Adding a command with an existing constructor fails, even if the file
+	// name is different.
+	require.NoError(t, os.Rename(filepath.Join(dir, "greet.go"), filepath.Join(dir, "hello.go")))
+	cmd = newAdd(boa.Pather("boa"))
+	cmd.SetArgs([]string{"--path", dir, "greet"})
+	err = cmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "newGreet already exists in "+filepath.Join(dir, "hello.go"))
+}
+
+func TestAddNested(t *testing.T) {
+	dir, err := ioutil.TempDir("", "add-nested")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	initCmd := newInit(boa.Pather("boa"))
+	initCmd.SetArgs([]string{"--path", dir, "app"})
+	require.NoError(t, initCmd.Execute())
+
+	for _, args := range [][]string{
+		{"remote"},
+		{"add", "--parent", "remote"},
+		{"remote/add/all", "--test=false"},
+	} {
+		cmd := newAdd(boa.Pather("boa"))
+		cmd.SetArgs(append([]string{"--path", dir}, args...))
+		require.NoError(t, cmd.Execute())
+	}
+
+	remote, err := ioutil.ReadFile(filepath.Join(dir, "remote.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(remote), `cmd.AddCommand(newRemoteAdd(boa.Pather(pather.CommandPath() + " " + cmd.Name())))`)
+
+	add, err := ioutil.ReadFile(filepath.Join(dir, "remote_add.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(add), "func newRemoteAdd(pather CommandPather) *cobra.Command {")
+	// The placeholder argument is dropped once subcommands are registered.
+	assert.Contains(t, string(add), `Use:     "add",`)
+	assert.Contains(t, string(add), `cmd.AddCommand(newRemoteAddAll(boa.Pather(pather.CommandPath() + " " + cmd.Name())))`)
+
+	all, err := ioutil.ReadFile(filepath.Join(dir, "remote_add_all.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(all), "func newRemoteAddAll(pather CommandPather) *cobra.Command {")
+
+	test, err := ioutil.ReadFile(filepath.Join(dir, "remote_add_test.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(test), "func TestRemoteAdd(t *testing.T) {")
+	assert.Contains(t, string(test), `cmd := newRemoteAdd(&cobra.Command{Use: "app"})`)
+	_, err = os.Stat(filepath.Join(dir, "remote_add_all_test.go"))
+	assert.True(t, os.IsNotExist(err))
+
+	// Adding a command to a missing parent fails.
+	cmd := newAdd(boa.Pather("boa"))
+	cmd.SetArgs([]string{"--path", dir, "--parent", "origin", "add"})
+	err = cmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "newOrigin does not exist")
+}
+
+func TestAddNestedGoTest(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs go test on the generated code")
+	}
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go not found")
+	}
+	// The project is created inside this module, such that the generated
+	// code resolves the boa packages without network access. Directories
+	// starting with '_' are ignored by './...'.
+	dir, err := ioutil.TempDir(filepath.Join("..", ".."), "_nested")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	initCmd := newInit(boa.Pather("boa"))
+	initCmd.SetOut(ioutil.Discard)
+	initCmd.SetArgs([]string{"--path", dir, "--manifest=false", "app"})
+	require.NoError(t, initCmd.Execute())
+	for _, args := range [][]string{
+		{"remote"},
+		{"add", "--parent", "remote"},
+	} {
+		cmd := newAdd(boa.Pather("boa"))
+		cmd.SetOut(ioutil.Discard)
+		cmd.SetArgs(append([]string{"--path", dir}, args...))
+		require.NoError(t, cmd.Execute())
+	}
+
+	remoteTest, err := ioutil.ReadFile(filepath.Join(dir, "remote_test.go"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(remoteTest), `"arg"`)
+
+	goTest := exec.Command(goBin, "test", ".")
+	goTest.Dir = dir
+	out, err := goTest.CombinedOutput()
+	assert.NoError(t, err, string(out))
+}
+
+func TestAddPreview(t *testing.T) {
+	dir, cleanup := initProject(t)
+	defer cleanup()
+
+	var out bytes.Buffer
+	cmd := newAdd(boa.Pather("boa"))
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--path", dir, "--dry-run", "greet"})
+	assert.Equal(t, errDiffer, cmd.Execute())
+	assert.Contains(t, out.String(), "==> greet.go <==\n")
+	assert.Contains(t, out.String(), "func newGreet(pather CommandPather) *cobra.Command {")
+	assert.Contains(t, out.String(), "==> app.go <==\n")
+	assert.Contains(t, out.String(), "==> greet_test.go <==\n")
+	_, err := os.Stat(filepath.Join(dir, "greet.go"))
+	assert.True(t, os.IsNotExist(err))
+
+	out.Reset()
+	cmd = newAdd(boa.Pather("boa"))
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--path", dir, "--diff", "greet"})
+	assert.Equal(t, errDiffer, cmd.Execute())
+	assert.Contains(t, out.String(), "--- /dev/null\n+++ b/greet.go\n")
+	assert.Contains(t, out.String(), "--- a/app.go\n+++ b/app.go\n")
+	assert.Contains(t, out.String(), "+\t\tnewGreet(cmd),\n")
+
+	cmd = newAdd(boa.Pather("boa"))
+	cmd.SetOut(ioutil.Discard)
+	cmd.SetArgs([]string{"--path", dir, "greet"})
+	require.NoError(t, cmd.Execute())
+
+	// The existing command matches the rendered one.
+	out.Reset()
+	cmd = newAdd(boa.Pather("boa"))
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--path", dir, "--diff", "greet"})
+	require.NoError(t, cmd.Execute())
+	assert.Empty(t, out.String())
+
+	// Drift is detected.
+	cmd = newAdd(boa.Pather("boa"))
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--path", dir, "--diff", "--flags", "name:string", "greet"})
+	assert.Equal(t, errDiffer, cmd.Execute())
+	assert.Contains(t, out.String(), "-\t\tsample bool\n+\t\tname string\n")
+}
+
+func TestAddForce(t *testing.T) {
+	dir, cleanup := initProject(t, "greet")
+	defer cleanup()
+
+	add := func(args ...string) error {
+		cmd := newAdd(boa.Pather("boa"))
+		cmd.SetOut(ioutil.Discard)
+		cmd.SetArgs(append([]string{"--path", dir}, args...))
+		return cmd.Execute()
+	}
+	err := add("--flags", "name:string", "greet")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "newGreet already exists in "+filepath.Join(dir, "greet.go"))
+
+	require.NoError(t, add("--force", "--flags", "name:string", "greet"))
+	greet, err := ioutil.ReadFile(filepath.Join(dir, "greet.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(greet), "name string")
+
+	// The command is not registered twice.
+	app, err := ioutil.ReadFile(filepath.Join(dir, "app.go"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, bytes.Count(app, []byte("newGreet(cmd)")))
+}
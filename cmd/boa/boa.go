@@ -16,10 +16,45 @@ package main
 import (
 	"fmt"
 	"os"
+	"regexp"
+	"runtime/debug"
+	"time"
 
+	"github.com/oncilla/boa/pkg/gen"
 	"github.com/spf13/cobra"
 )
 
+// now returns the current time. It is overwritten in tests to get
+// reproducible copyright attributions.
+var now = time.Now
+
+// boaVersion returns the module version of the running binary, if it is a
+// tagged release. Binaries built from a local checkout report "(devel)", or a
+// pseudo-version derived from the VCS state with Go 1.24 and later. Neither is
+// published, thus the empty string is returned. It is overwritten in tests to
+// get reproducible go.mod files.
+var boaVersion = func() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok || info.Main.Path != gen.BoaModule || !releaseVersion(info.Main.Version) {
+		return ""
+	}
+	return info.Main.Version
+}
+
+var (
+	// semverRE matches semantic versions without build metadata. Builds
+	// from modified trees have the '+dirty' metadata.
+	semverRE = regexp.MustCompile(`^v(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)(-[0-9A-Za-z.-]+)?$`)
+	// pseudoRE matches pseudo-versions, e.g., v0.0.0-20200101000000-abcdef123456.
+	pseudoRE = regexp.MustCompile(`^v[0-9]+\.(0\.0-|\d+\.\d+-([^+]*\.)?0\.)\d{14}-[A-Za-z0-9]+$`)
+)
+
+// releaseVersion indicates whether the version is a tagged release version,
+// i.e., a valid semantic version that is not a pseudo-version.
+func releaseVersion(v string) bool {
+	return semverRE.MatchString(v) && !pseudoRE.MatchString(v)
+}
+
 // CommandPather returns the path to a command.
 type CommandPather interface {
 	CommandPath() string
@@ -34,7 +69,12 @@ func main() {
 	cmd.AddCommand(
 		newAdd(cmd),
 		newCompletion(cmd),
+		newGenerate(cmd),
 		newInit(cmd),
+		newRemove(cmd),
+		newRename(cmd),
+		newTemplates(cmd),
+		newTree(cmd),
 		newVersion(cmd),
 	)
 	if err := cmd.Execute(); err != nil {
@@ -0,0 +1,157 @@
+// Copyright 2020 oncilla
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/spf13/pflag"
+
+	"github.com/oncilla/boa/pkg/source"
+)
+
+// errDiffer is returned in preview mode if the generated files differ from the
+// files on disk. It results in a non-zero exit code, e.g., to detect drift in
+// CI.
+var errDiffer = errors.New("generated files differ from the files on disk")
+
+// preview holds the flags of the generators that show the generated files
+// instead of writing them.
+type preview struct {
+	dryRun bool
+	diff   bool
+}
+
+// register adds the preview flags to the flag set.
+func (p *preview) register(fs *pflag.FlagSet) {
+	fs.BoolVar(&p.dryRun, "dry-run", false, "print the generated files instead of writing them")
+	fs.BoolVar(&p.diff, "diff", false, "print the diff against the files on disk instead of writing them")
+}
+
+// enabled reports whether the generated files are shown instead of written.
+func (p preview) enabled() bool {
+	return p.dryRun || p.diff
+}
+
+// show prints the generated files, or the diff against the files on disk if
+// the diff flag is set. Removed files are only listed. The file names are
+// relative to dir. It returns errDiffer if any of the files differ.
+func (p preview) show(w io.Writer, dir string, changes []source.Change) error {
+	differ := false
+	for _, c := range changes {
+		differ = differ || c.Changed()
+		if p.diff {
+			fmt.Fprint(w, c.Diff(dir))
+			continue
+		}
+		if c.New == nil {
+			fmt.Fprintf(w, "==> %s (removed) <==\n", c.Name(dir))
+			continue
+		}
+		fmt.Fprintf(w, "==> %s <==\n", c.Name(dir))
+		if _, err := w.Write(c.New); err != nil {
+			return err
+		}
+	}
+	if differ {
+		return errDiffer
+	}
+	return nil
+}
+
+// overwrite holds the flags of the generators that determine how existing
+// files are handled.
+type overwrite struct {
+	force  bool
+	skip   bool
+	backup bool
+}
+
+// register adds the overwrite flags to the flag set.
+func (o *overwrite) register(fs *pflag.FlagSet) {
+	fs.BoolVar(&o.force, "force", false, "overwrite existing files")
+	fs.BoolVar(&o.skip, "skip-existing", false, "keep existing files and only create the missing ones")
+	fs.BoolVar(&o.backup, "backup", false, "back up existing files with the "+
+		source.BackupSuffix+" suffix before overwriting them")
+}
+
+// policy returns the policy selected by the flags.
+func (o overwrite) policy() (source.Policy, error) {
+	policy, set := source.Fail, 0
+	if o.force {
+		policy, set = source.Force, set+1
+	}
+	if o.skip {
+		policy, set = source.SkipExisting, set+1
+	}
+	if o.backup {
+		policy, set = source.Backup, set+1
+	}
+	if set > 1 {
+		return source.Fail, errors.New("only one of force, skip-existing and backup can be set")
+	}
+	return policy, nil
+}
+
+// writeChanges resolves the generated files according to the policy and
+// writes them to disk, together with the updates of existing files, e.g., the
+// registration of a command with its parent. Either all files are written, or
+// none of them. Generated files that already existed are reported.
+func writeChanges(w io.Writer, policy source.Policy, changes []source.Change, updates ...source.Change) error {
+	resolved, err := policy.Resolve(changes)
+	if err != nil {
+		return err
+	}
+	if err := source.Apply(append(resolved, updates...)); err != nil {
+		return err
+	}
+	applied := map[string]bool{}
+	for _, c := range resolved {
+		applied[c.Path] = true
+	}
+	for _, c := range changes {
+		switch {
+		case c.Old == nil:
+		case !c.Changed():
+			fmt.Fprintln(w, "Unchanged", c.Path)
+		case !applied[c.Path]:
+			fmt.Fprintln(w, "Skipped existing", c.Path)
+		case applied[c.Path+source.BackupSuffix]:
+			fmt.Fprintf(w, "Overwrote %s, backup at %s\n", c.Path, c.Path+source.BackupSuffix)
+		default:
+			fmt.Fprintln(w, "Overwrote", c.Path)
+		}
+	}
+	return nil
+}
+
+// applyChanges applies the changes to disk and reports them.
+func applyChanges(w io.Writer, changes []source.Change) error {
+	if err := source.Apply(changes); err != nil {
+		return err
+	}
+	for _, c := range changes {
+		switch {
+		case c.Old == nil:
+			fmt.Fprintln(w, "Created", c.Path)
+		case c.New == nil:
+			fmt.Fprintln(w, "Removed", c.Path)
+		default:
+			fmt.Fprintln(w, "Updated", c.Path)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,295 @@
+// Copyright 2020 oncilla
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/oncilla/boa/pkg/gen"
+	"github.com/oncilla/boa/pkg/source"
+	"github.com/oncilla/boa/pkg/tmpl"
+	"github.com/spf13/cobra"
+)
+
+// errDrift is returned in preview mode if the source differs from the spec.
+var errDrift = errors.New("source differs from the spec")
+
+func newGenerate(pather CommandPather) *cobra.Command {
+	var flags struct {
+		author  string
+		license string
+		path    string
+		file    string
+		test    bool
+		vars    map[string]string
+		preview preview
+	}
+
+	var cmd = &cobra.Command{
+		Use:     "generate",
+		Aliases: []string{"gen"},
+		Short:   "Generate the commands described by a spec file",
+		Long: `Generate the commands described by a spec file.
+
+The spec file describes the command tree of the cobra application in YAML. Each
+command has a name, and optionally a short and long description, aliases,
+flags, positional arguments and subcommands:
+
+  commands:
+    - name: remote
+      short: Manage the remotes
+      commands:
+        - name: add
+          short: Add a remote
+          aliases: [new]
+          args: [name:string, url:string]
+          flags:
+            - name: fetch
+              type: bool
+              short: f
+              usage: fetch the remote after adding it
+            - name: timeout
+              type: duration[1s..]
+              default: 30s
+
+The flag types and constraints, and the argument notation are the ones of
+'boa add'. Besides name and type, flags support the attributes default, short,
+required and usage of the long notation. Optional arguments in flow sequences
+must be quoted, e.g., [src:path, 'dst:path?'].
+
+Commands whose constructor does not exist yet are created in the same way as
+by 'boa add', including their test file unless the 'test' flag is false.
+Commands that are not registered with their parent are registered. Existing
+command files are never modified, such that hand-written code is preserved.
+Commands in the source that are not in the spec are left alone, e.g., the
+completion and version commands of 'boa init'.
+
+Instead, the spec is compared to the existing commands, and the drift is
+reported. The usage line, the short description if set, the aliases and the
+type, shorthand, default and usage of the flags are compared. Running the
+command again on an unchanged spec does not modify anything.
+
+To preview the generated files and registrations without writing them, set the
+'dry-run' or 'diff' flag. In this case, the command fails if any files would
+be modified, or the source differs from the spec, e.g., to detect drift in CI.`,
+		Args: cobra.NoArgs,
+		Example: fmt.Sprintf(`  %[1]s generate
+  %[1]s generate -f cli.yaml --diff`, pather.CommandPath()),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, m, err := project(cmd, flags.path, map[string]*string{
+				"author":  &flags.author,
+				"license": &flags.license,
+			})
+			if err != nil {
+				return err
+			}
+			cmd.SilenceUsage = true
+			cli, err := gen.LoadCLI(flags.file)
+			if err != nil {
+				return err
+			}
+			cmds, err := cli.Flatten()
+			if err != nil {
+				return err
+			}
+			license, err := gen.FindLicense(flags.license)
+			if err != nil {
+				return err
+			}
+			templates, err := loadTemplates(tmpl.Builtin(), projectTemplateDir(m, path))
+			if err != nil {
+				return err
+			}
+			pkg, err := source.Load(path)
+			if err != nil {
+				return err
+			}
+			if _, _, ok := pkg.Func("main"); !ok {
+				return fmt.Errorf("no main function found in %s", path)
+			}
+
+			p := &pending{pkg: pkg, old: map[string][]byte{}}
+			misplaced := map[string]string{}
+			for _, c := range cmds {
+				c.Copyright = gen.Copyright{Year: now().Year(), Author: flags.author}
+				c.License = license
+				c.Vars = vars(m, flags.vars)
+				c.Templates = templates
+				if err := p.create(c, path, flags.test); err != nil {
+					return err
+				}
+				if parent, ok := pkg.Parent(c.Constructor()); ok {
+					if parent != c.ParentConstructor() {
+						misplaced[c.Constructor()] = parent
+					}
+					continue
+				}
+				test, ok, err := pkg.NestedTest(c.ParentConstructor())
+				if err != nil {
+					return err
+				}
+				if ok {
+					if err := p.update(test.Path, test.New); err != nil {
+						return err
+					}
+				}
+				file, out, err := pkg.Register(c.ParentConstructor(), c.Constructor())
+				if err != nil {
+					return fmt.Errorf("registering %s: %s", c.Constructor(), err)
+				}
+				if err := p.update(file.Path, out); err != nil {
+					return err
+				}
+			}
+
+			changes := p.changes()
+			switch {
+			case flags.preview.enabled():
+				err = flags.preview.show(cmd.OutOrStdout(), path, changes)
+			case len(changes) > 0:
+				err = applyChanges(cmd.OutOrStdout(), changes)
+			}
+			if err != nil && err != errDiffer {
+				return err
+			}
+			drift := reportDrift(cmd.OutOrStdout(), pkg.Tree(), cmds, misplaced)
+			switch {
+			case err != nil:
+				return err
+			case drift && flags.preview.enabled():
+				return errDrift
+			case len(changes) == 0 && !drift:
+				fmt.Fprintln(cmd.OutOrStdout(), "The source is up to date with", flags.file)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&flags.author, "author", "a", "YOUR_NAME", "author name for copyright attribution")
+	cmd.Flags().StringVarP(&flags.license, "license", "l", "apache", "name of license for the project")
+	cmd.Flags().StringVarP(&flags.path, "path", "p", "", "path to main package")
+	cmd.Flags().StringVarP(&flags.file, "file", "f", "cli.yaml", "spec file that describes the commands")
+	cmd.Flags().BoolVar(&flags.test, "test", true, "generate a test for each created command")
+	cmd.Flags().StringToStringVar(&flags.vars, "var", nil, "custom template variables as key=value pairs")
+	flags.preview.register(cmd.Flags())
+	return cmd
+}
+
+// pending collects the generated and updated files in memory. The package is
+// updated along the way, such that subcommands can be registered with parents
+// that are generated in the same run.
+type pending struct {
+	pkg   *source.Package
+	paths []string
+	// old holds the content of the files on disk. It is nil for files that
+	// do not exist yet.
+	old map[string][]byte
+}
+
+// create renders the command and its test, unless the constructor already
+// exists. Existing files are never overwritten.
+func (p *pending) create(c gen.Command, dir string, test bool) error {
+	if _, _, ok := p.pkg.Func(c.Constructor()); ok {
+		return nil
+	}
+	change, err := c.Render(filepath.Join(dir, c.FileName()))
+	if err != nil {
+		return fmt.Errorf("command %s: %s", c.Constructor(), err)
+	}
+	if change.Old != nil {
+		return fmt.Errorf("file %s exists, but does not contain %s", change.Path, c.Constructor())
+	}
+	if err := p.update(change.Path, change.New); err != nil {
+		return err
+	}
+	if !test {
+		return nil
+	}
+	change, err = c.RenderTest(filepath.Join(dir, c.TestFileName()))
+	if err != nil || change.Old != nil {
+		return err
+	}
+	return p.update(change.Path, change.New)
+}
+
+// update updates the file in the package and records the change.
+func (p *pending) update(path string, src []byte) error {
+	if _, ok := p.old[path]; !ok {
+		p.paths = append(p.paths, path)
+		p.old[path] = nil
+		for _, f := range p.pkg.Files {
+			if f.Path == path {
+				p.old[path] = f.Src
+			}
+		}
+	}
+	return p.pkg.Update(path, src)
+}
+
+// changes returns the changes against the files on disk in the order in which
+// the files were first updated.
+func (p *pending) changes() []source.Change {
+	var changes []source.Change
+	for _, path := range p.paths {
+		for _, f := range p.pkg.Files {
+			if f.Path == path {
+				changes = append(changes, source.Change{Path: path, Old: p.old[path], New: f.Src})
+			}
+		}
+	}
+	return changes
+}
+
+// reportDrift writes the differences between the commands and the commands in
+// the tree. Misplaced commands are registered with a parent other than the one
+// in the spec. It reports whether there are any differences.
+func reportDrift(w io.Writer, tree *source.Tree, cmds []gen.Command, misplaced map[string]string) bool {
+	index := map[string]*source.Command{}
+	var walk func(c *source.Command)
+	walk = func(c *source.Command) {
+		index[c.Constructor] = c
+		for _, child := range c.Commands {
+			walk(child)
+		}
+	}
+	walk(tree.Root)
+	for _, c := range tree.Orphans {
+		walk(c)
+	}
+	found := false
+	for _, c := range cmds {
+		src, ok := index[c.Constructor()]
+		if !ok {
+			continue
+		}
+		drift := c.Drift(src)
+		if parent, ok := misplaced[c.Constructor()]; ok {
+			drift = append([]string{fmt.Sprintf("registered with %s instead of %s",
+				parent, c.ParentConstructor())}, drift...)
+		}
+		if len(drift) == 0 {
+			continue
+		}
+		found = true
+		name := strings.Join(append(append([]string(nil), c.Parents...), c.Name), " ")
+		fmt.Fprintf(w, "%s [%s] differs from the spec:\n", name, src.Position)
+		for _, d := range drift {
+			fmt.Fprintf(w, "  - %s\n", d)
+		}
+	}
+	return found
+}
@@ -0,0 +1,161 @@
+// Copyright 2020 oncilla
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oncilla/boa/pkg/boa"
+)
+
+const cliSpec = `commands:
+  - name: greet
+  - name: remote
+    short: Manage the remotes
+    commands:
+      - name: add
+        short: Add a remote
+        aliases: [new]
+        args: [name:string, url:string]
+        flags:
+          - name: fetch
+            type: bool
+            short: f
+            usage: fetch the remote after adding it
+`
+
+func TestGenerate(t *testing.T) {
+	dir, cleanup := initProject(t, "greet")
+	defer cleanup()
+	spec := filepath.Join(dir, "cli.yaml")
+	require.NoError(t, ioutil.WriteFile(spec, []byte(cliSpec), 0644))
+
+	generate := func(args ...string) (string, error) {
+		var out bytes.Buffer
+		cmd := newGenerate(boa.Pather("boa"))
+		cmd.SetOut(&out)
+		cmd.SetArgs(append([]string{"--path", dir, "-f", spec}, args...))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+	greet, err := ioutil.ReadFile(filepath.Join(dir, "greet.go"))
+	require.NoError(t, err)
+
+	out, err := generate("--dry-run")
+	assert.Equal(t, errDiffer, err)
+	assert.Contains(t, out, "==> remote_add.go <==\n")
+	_, err = ioutil.ReadFile(filepath.Join(dir, "remote.go"))
+	require.Error(t, err)
+
+	out, err = generate()
+	require.NoError(t, err)
+	for _, file := range []string{"remote.go", "remote_test.go", "remote_add.go", "remote_add_test.go"} {
+		assert.Contains(t, out, "Created "+filepath.Join(dir, file)+"\n")
+	}
+	assert.Contains(t, out, "Updated "+filepath.Join(dir, "app.go")+"\n")
+	assert.NotContains(t, out, "differs from the spec")
+
+	remote, err := ioutil.ReadFile(filepath.Join(dir, "remote.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(remote), `Use:     "remote",`)
+	assert.Contains(t, string(remote), `Short:   "Manage the remotes",`)
+	assert.Contains(t, string(remote), `cmd.AddCommand(newRemoteAdd(boa.Pather(pather.CommandPath() + " " + cmd.Name())))`)
+	add, err := ioutil.ReadFile(filepath.Join(dir, "remote_add.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(add), `Aliases: []string{"new"},`)
+	assert.Contains(t, string(add), `cmd.Flags().BoolVarP(&flags.fetch, "fetch", "f", false, "fetch the remote after adding it")`)
+
+	// The existing command is not modified.
+	after, err := ioutil.ReadFile(filepath.Join(dir, "greet.go"))
+	require.NoError(t, err)
+	assert.Equal(t, string(greet), string(after))
+
+	// Generating again is a no-op.
+	out, err = generate()
+	require.NoError(t, err)
+	assert.Equal(t, "The source is up to date with "+spec+"\n", out)
+	out, err = generate("--diff")
+	require.NoError(t, err)
+	assert.NotContains(t, out, "---")
+
+	// Drift is reported without modifying the source.
+	edited := strings.Replace(string(add), `"Add a remote"`, `"Add a new remote"`, 1)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "remote_add.go"), []byte(edited), 0644))
+	out, err = generate("--diff")
+	assert.Equal(t, errDrift, err)
+	assert.Contains(t, out, "remote add [remote_add.go:")
+	assert.Contains(t, out, `short description is "Add a remote" in the spec, but "Add a new remote" in the source`)
+	out, err = generate()
+	require.NoError(t, err)
+	assert.Contains(t, out, "differs from the spec")
+	after, err = ioutil.ReadFile(filepath.Join(dir, "remote_add.go"))
+	require.NoError(t, err)
+	assert.Equal(t, edited, string(after))
+}
+
+func TestGenerateRegister(t *testing.T) {
+	dir, cleanup := initProject(t, "greet", "remote")
+	defer cleanup()
+	spec := filepath.Join(dir, "cli.yaml")
+	require.NoError(t, ioutil.WriteFile(spec, []byte(cliSpec), 0644))
+
+	// Unregistered commands are registered, commands registered with another
+	// parent are reported.
+	app, err := ioutil.ReadFile(filepath.Join(dir, "app.go"))
+	require.NoError(t, err)
+	app = bytes.Replace(app, []byte("\t\tnewGreet(cmd),\n"), nil, 1)
+	app = bytes.Replace(app, []byte("newRemote(cmd),"), []byte("newRemote(cmd),\n\t\tnewRemoteAdd(cmd),"), 1)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "app.go"), app, 0644))
+	add := `package main
+
+import "github.com/spf13/cobra"
+
+func newRemoteAdd(pather CommandPather) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:     "add <name> <url>",
+		Aliases: []string{"new"},
+		Short:   "Add a remote",
+	}
+	return cmd
+}
+`
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "remote_add.go"), []byte(add), 0644))
+
+	var out bytes.Buffer
+	cmd := newGenerate(boa.Pather("boa"))
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--path", dir, "-f", spec})
+	require.NoError(t, cmd.Execute())
+	assert.Equal(t, "Updated "+filepath.Join(dir, "app.go")+"\n"+
+		"remote [remote.go:23] differs from the spec:\n"+
+		`  - usage line is "remote" in the spec, but "remote <arg>" in the source`+"\n"+
+		`  - short description is "Manage the remotes" in the spec, but "remote does amazing work!" in the source`+"\n"+
+		"remote add [remote_add.go:5] differs from the spec:\n"+
+		"  - registered with main instead of newRemote\n"+
+		"  - flag --fetch is missing in the source\n", out.String())
+
+	app, err = ioutil.ReadFile(filepath.Join(dir, "app.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(app), "newGreet(cmd),")
+	remote, err := ioutil.ReadFile(filepath.Join(dir, "remote.go"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(remote), "AddCommand")
+}
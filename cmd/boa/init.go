@@ -15,26 +15,93 @@ package main
 
 import (
 	"fmt"
+	"io/ioutil"
 	"os"
-	"time"
+	"path/filepath"
+	"strings"
 
+	"github.com/oncilla/boa/pkg/boa"
+	"github.com/oncilla/boa/pkg/boa/flag"
 	"github.com/oncilla/boa/pkg/gen"
+	"github.com/oncilla/boa/pkg/manifest"
+	"github.com/oncilla/boa/pkg/source"
+	"github.com/oncilla/boa/pkg/tmpl"
 	"github.com/spf13/cobra"
 )
 
 func newInit(pather CommandPather) *cobra.Command {
 	var flags struct {
-		author  string
-		license string
-		path    string
+		author      string
+		license     string
+		path        string
+		manifest    bool
+		vars        map[string]string
+		preset      *flag.Enum
+		module      string
+		goMod       bool
+		licenseFile bool
+		gitignore   bool
+		makefile    bool
+		preview     preview
+		overwrite   overwrite
 	}
 
 	var cmd = &cobra.Command{
 		Use:     "init <project name>",
 		Aliases: []string{"initialize", "create"},
 		Short:   "Initialize a venom-free cobra application",
-		Args:    cobra.ExactArgs(1),
+		Long: `Initialize a venom-free cobra application.
+
+The application is created in the project root at the provided path, or the
+current working directory if no path is provided.
+
+The preset determines the layout and the commands of the application:
+
+  cli     main package in the project root with completion and version
+  server  additional serve command with config loading and graceful shutdown
+  daemon  server with pidfile and log file handling, logs are reopened on SIGHUP
+  multi   main package in cmd/<name> with a shared internal/version package
+
+Run the multi preset again with another name to add a binary to the project.
+The module path is detected from go.mod, or set with the 'module' flag.
+
+The project root is scaffolded with a LICENSE file, a .gitignore file and a
+Makefile. If the module path is set and the project root is not part of a
+module yet, a go.mod file with pinned requirements is written. Existing files
+are kept. Each of these files can be disabled with the corresponding flag.
+
+The files are written as a whole. If any of them cannot be written, the ones
+that have already been written are rolled back. Existing files cause an error,
+unless the 'force' flag is set to overwrite them, the 'backup' flag is set to
+overwrite them and keep a copy with the .bak suffix, or the 'skip-existing' flag
+is set to keep them.
+
+To preview the project without writing it, set the 'dry-run' flag to print the
+generated files, or the 'diff' flag to print the diff against the files on
+disk. In both cases, the command fails if the files differ from the ones on
+disk, which allows checking for drift in CI.
+
+The project manifest (.boa.yaml) records the name, the path to the main
+package, the module path, the author and the license. The other commands
+discover the manifest by walking up from the working directory, such that
+these values do not need to be provided again. The manifest is written to the
+working directory if it contains the main package, otherwise it is written to
+the main package directory. To skip the manifest, set the 'manifest' flag to
+false.
+
+The files are rendered from the built-in templates. They can be overridden by
+templates in the user template directory ($XDG_CONFIG_HOME/boa/templates), which
+are in turn overridden by the project templates (.boa/templates next to the
+manifest). Templates that do not override a built-in template are rendered as
+additional files. Custom variables for the templates are set with the 'var'
+flag and recorded in the manifest. To start from the built-in templates, run
+'boa templates export'.`,
+		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			policy, err := flags.overwrite.policy()
+			if err != nil {
+				return err
+			}
 			cmd.SilenceUsage = true
 			path := flags.path
 			if path == "" {
@@ -47,23 +114,157 @@ func newInit(pather CommandPather) *cobra.Command {
 			if err != nil {
 				return err
 			}
+			preset, err := gen.FindPreset(flags.preset.Value)
+			if err != nil {
+				return err
+			}
 			p := gen.Project{
 				Name: args[0],
 				Copyright: gen.Copyright{
-					Year:   time.Now().Year(),
+					Year:   now().Year(),
 					Author: flags.author,
 				},
 				License: license,
+				Vars:    vars(nil, flags.vars),
+				Preset:  preset,
+				Module:  flags.module,
+				Boa:     boaVersion(),
+			}
+			detected, err := manifest.Module(path)
+			if err != nil {
+				return err
+			}
+			if p.Module == "" {
+				p.Module = detected
+			}
+			// The go.mod file is only written for new modules. Otherwise, the
+			// project would become a nested module.
+			goMod := flags.goMod && flags.module != "" && detected == ""
+			if goMod {
+				p.Scaffold = append(p.Scaffold, tmpl.GoModName)
+			}
+			if flags.licenseFile {
+				p.Scaffold = append(p.Scaffold, tmpl.LicenseName)
+			}
+			if flags.gitignore {
+				p.Scaffold = append(p.Scaffold, tmpl.GitignoreName)
+			}
+			if flags.makefile {
+				p.Scaffold = append(p.Scaffold, tmpl.MakefileName)
+			}
+			m, err := newManifest(p, path, flags.author, flags.license)
+			if err != nil {
+				return err
+			}
+			m.Vars = flags.vars
+			templateDir := projectTemplateDir(nil, path)
+			if flags.manifest {
+				templateDir = m.TemplateDir()
+			}
+			if p.Templates, err = loadTemplates(preset.Builtin(), templateDir); err != nil {
+				return err
+			}
+			changes, err := p.Render(path)
+			if err != nil {
+				return err
+			}
+			var manifestFile *source.Change
+			if flags.manifest {
+				change, err := manifestChange(m)
+				if err != nil {
+					return err
+				}
+				changes = append(changes, change)
+				manifestFile = &change
+			}
+			if flags.preview.enabled() {
+				return flags.preview.show(cmd.OutOrStdout(), path, changes)
+			}
+			if flags.manifest && policy == source.Fail {
+				if _, err := os.Stat(filepath.Join(m.Dir, manifest.FileName)); err == nil {
+					return fmt.Errorf("manifest already exists in %s", m.Dir)
+				}
 			}
-			if err := p.Create(path); err != nil {
+			if err := writeChanges(cmd.OutOrStdout(), policy, changes); err != nil {
 				return err
 			}
-			fmt.Println("Created project at", path)
+			fmt.Fprintln(cmd.OutOrStdout(), "Created project at", path)
+			if manifestFile != nil && manifestFile.Old == nil {
+				fmt.Fprintln(cmd.OutOrStdout(), "Created manifest at", manifestFile.Path)
+			}
+			if goMod && p.Boa == "" {
+				fmt.Fprintf(cmd.OutOrStdout(), "The boa version is unknown, "+
+					"add it to go.mod with: go get %s\n", gen.BoaModule)
+			}
 			return nil
 		},
 	}
 	cmd.Flags().StringVarP(&flags.author, "author", "a", "YOUR_NAME", "author name for copyright attribution")
 	cmd.Flags().StringVarP(&flags.license, "license", "l", "apache", "name of license for the project")
-	cmd.Flags().StringVarP(&flags.path, "path", "p", "", "path to main package")
+	flags.preset = flag.NewEnum("cli", gen.PresetNames()...)
+	cmd.Flags().StringVarP(&flags.path, "path", "p", "", "path to project root")
+	cmd.Flags().BoolVar(&flags.manifest, "manifest", true, "write the project manifest")
+	cmd.Flags().StringToStringVar(&flags.vars, "var", nil, "custom template variables as key=value pairs")
+	cmd.Flags().Var(flags.preset, "preset", "project preset ("+strings.Join(gen.PresetNames(), "|")+")")
+	cmd.Flags().StringVar(&flags.module, "module", "", "module path of the project root")
+	cmd.Flags().BoolVar(&flags.goMod, "go-mod", true, "write go.mod if the project is not part of a module")
+	cmd.Flags().BoolVar(&flags.licenseFile, "license-file", true, "write the LICENSE file")
+	cmd.Flags().BoolVar(&flags.gitignore, "gitignore", true, "write the .gitignore file")
+	cmd.Flags().BoolVar(&flags.makefile, "makefile", true, "write the Makefile")
+	flags.preview.register(cmd.Flags())
+	flags.overwrite.register(cmd.Flags())
+	boa.RegisterCompletions(cmd)
 	return cmd
 }
+
+// newManifest creates the manifest for the project with the root at the
+// path. The manifest is located in the working directory if it contains the
+// main package and has no manifest yet. Otherwise, it is located in the main
+// package directory.
+func newManifest(p gen.Project, path, author, license string) (*manifest.Manifest, error) {
+	root, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	main := filepath.Join(root, filepath.FromSlash(p.MainDir()))
+	dir := main
+	if wd, err := os.Getwd(); err == nil {
+		_, statErr := os.Stat(filepath.Join(wd, manifest.FileName))
+		rel, err := filepath.Rel(wd, main)
+		inside := rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+		if err == nil && inside && os.IsNotExist(statErr) {
+			dir = wd
+		}
+	}
+	rel, err := filepath.Rel(dir, main)
+	if err != nil {
+		return nil, err
+	}
+	var module string
+	if p.Module != "" {
+		module = strings.TrimSuffix(p.Module+"/"+p.MainDir(), "/.")
+	}
+	return &manifest.Manifest{
+		Name:    p.Name,
+		Path:    filepath.ToSlash(rel),
+		Module:  module,
+		Author:  author,
+		License: license,
+		Preset:  p.Preset.Name,
+		Dir:     dir,
+	}, nil
+}
+
+// manifestChange returns the manifest file as change against the file on disk.
+func manifestChange(m *manifest.Manifest) (source.Change, error) {
+	raw, err := m.Marshal()
+	if err != nil {
+		return source.Change{}, err
+	}
+	file := filepath.Join(m.Dir, manifest.FileName)
+	old, err := ioutil.ReadFile(file)
+	if err != nil && !os.IsNotExist(err) {
+		return source.Change{}, err
+	}
+	return source.Change{Path: file, Old: old, New: raw}, nil
+}
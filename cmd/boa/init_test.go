@@ -14,9 +14,11 @@
 package main
 
 import (
+	"bytes"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -45,6 +47,7 @@ func TestInit(t *testing.T) {
 		"--author", "my-name",
 		"--license", "apache",
 		"--path", dir,
+		"--manifest=false",
 		"server",
 	})
 	err := cmd.Execute()
@@ -62,3 +65,344 @@ func TestInit(t *testing.T) {
 		assert.Equal(t, string(golden), string(created))
 	}
 }
+
+func TestInitPresets(t *testing.T) {
+	for _, preset := range []string{"server", "daemon", "multi"} {
+		t.Run(preset, func(t *testing.T) {
+			golden := filepath.Join("testdata", "preset", preset)
+			var dir string
+			if !*update {
+				var err error
+				dir, err = ioutil.TempDir("", "preset")
+				require.NoError(t, err)
+				defer func() {
+					require.NoError(t, os.RemoveAll(dir))
+				}()
+			} else {
+				dir = golden
+				require.NoError(t, os.RemoveAll(dir))
+				require.NoError(t, os.MkdirAll(dir, 0755))
+			}
+
+			cmd := newInit(boa.Pather("parent path"))
+			cmd.SetArgs([]string{
+				"--author", "my-name",
+				"--license", "apache",
+				"--path", dir,
+				"--manifest=false",
+				"--module", "example.com/app",
+				"--go-mod=false",
+				"--preset", preset,
+				"app",
+			})
+			require.NoError(t, cmd.Execute())
+
+			var files []string
+			err := filepath.Walk(golden, func(file string, info os.FileInfo, err error) error {
+				if err == nil && !info.IsDir() {
+					files = append(files, file)
+				}
+				return err
+			})
+			require.NoError(t, err)
+			require.NotEmpty(t, files)
+
+			for _, file := range files {
+				t.Log("Checking:", file)
+				rel, err := filepath.Rel(golden, file)
+				require.NoError(t, err)
+				created, err := ioutil.ReadFile(filepath.Join(dir, rel))
+				require.NoError(t, err)
+				expected, err := ioutil.ReadFile(file)
+				require.NoError(t, err)
+				assert.Equal(t, string(expected), string(created))
+			}
+		})
+	}
+}
+
+func TestInitMultiShared(t *testing.T) {
+	dir, err := ioutil.TempDir("", "multi")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	for _, name := range []string{"client", "server"} {
+		cmd := newInit(boa.Pather("boa"))
+		cmd.SetArgs([]string{
+			"--path", dir,
+			"--manifest=false",
+			"--module", "example.com/app",
+			"--preset", "multi",
+			name,
+		})
+		require.NoError(t, cmd.Execute())
+	}
+	for _, file := range []string{
+		"cmd/client/client.go",
+		"cmd/server/server.go",
+		"internal/version/version.go",
+	} {
+		assert.FileExists(t, filepath.Join(dir, filepath.FromSlash(file)))
+	}
+}
+
+func TestInitPresetRequiresModule(t *testing.T) {
+	dir, err := ioutil.TempDir("", "multi")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	cmd := newInit(boa.Pather("boa"))
+	cmd.SetArgs([]string{"--path", dir, "--manifest=false", "--preset", "multi", "app"})
+	err = cmd.Execute()
+	assert.EqualError(t, err, "preset multi requires the module path")
+}
+
+func TestInitScaffold(t *testing.T) {
+	dir, err := ioutil.TempDir("", "scaffold")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	cmd := newInit(boa.Pather("boa"))
+	cmd.SetArgs([]string{
+		"--author", "my-name",
+		"--license", "mit",
+		"--path", dir,
+		"--manifest=false",
+		"--module", "github.com/my-name/app",
+		"--preset", "server",
+		"--makefile=false",
+		"app",
+	})
+	require.NoError(t, cmd.Execute())
+
+	goMod, err := ioutil.ReadFile(filepath.Join(dir, "go.mod"))
+	require.NoError(t, err)
+	assert.Equal(t, `module github.com/my-name/app
+
+go 1.13
+
+require (
+	github.com/mitchellh/mapstructure v1.1.2
+	github.com/oncilla/boa v0.0.0-20200101000000-000000000000
+	github.com/spf13/cobra v1.0.0
+	github.com/spf13/viper v1.4.0
+)
+`, string(goMod))
+	license, err := ioutil.ReadFile(filepath.Join(dir, "LICENSE"))
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(license),
+		"The MIT License (MIT)\n\nCopyright (c) 2020 my-name\n\nPermission"), string(license))
+	assert.FileExists(t, filepath.Join(dir, ".gitignore"))
+	_, err = os.Stat(filepath.Join(dir, "Makefile"))
+	assert.True(t, os.IsNotExist(err))
+
+	// Existing scaffolding files are kept.
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "LICENSE"), []byte("custom"), 0644))
+	cmd = newInit(boa.Pather("boa"))
+	cmd.SetArgs([]string{
+		"--path", dir,
+		"--manifest=false",
+		"--license", "mit",
+		"--preset", "multi",
+		"second",
+	})
+	require.NoError(t, cmd.Execute())
+	license, err = ioutil.ReadFile(filepath.Join(dir, "LICENSE"))
+	require.NoError(t, err)
+	assert.Equal(t, "custom", string(license))
+
+	// Projects inside a module do not get a nested go.mod.
+	nested := filepath.Join(dir, "nested")
+	cmd = newInit(boa.Pather("boa"))
+	cmd.SetArgs([]string{
+		"--path", nested,
+		"--manifest=false",
+		"--module", "github.com/my-name/app/nested",
+		"--license", "none",
+		"nested",
+	})
+	require.NoError(t, cmd.Execute())
+	_, err = os.Stat(filepath.Join(nested, "go.mod"))
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(filepath.Join(nested, "LICENSE"))
+	assert.True(t, os.IsNotExist(err))
+
+	// Binaries built from a checkout do not know the boa version.
+	defer func(f func() string) { boaVersion = f }(boaVersion)
+	boaVersion = func() string { return "" }
+	devel, err := ioutil.TempDir("", "devel")
+	require.NoError(t, err)
+	defer os.RemoveAll(devel)
+	var out bytes.Buffer
+	cmd = newInit(boa.Pather("boa"))
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{
+		"--path", devel,
+		"--manifest=false",
+		"--module", "github.com/my-name/devel",
+		"devel",
+	})
+	require.NoError(t, cmd.Execute())
+	goMod, err = ioutil.ReadFile(filepath.Join(devel, "go.mod"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(goMod), "github.com/oncilla/boa")
+	assert.Contains(t, out.String(), "go get github.com/oncilla/boa\n")
+}
+
+func TestInitPreview(t *testing.T) {
+	dir, err := ioutil.TempDir("", "preview")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	var out bytes.Buffer
+	cmd := newInit(boa.Pather("boa"))
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--path", dir, "--dry-run", "app"})
+	assert.Equal(t, errDiffer, cmd.Execute())
+	assert.Contains(t, out.String(), "==> app.go <==\n")
+	assert.Contains(t, out.String(), "==> .boa.yaml <==\n")
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, files)
+
+	cmd = newInit(boa.Pather("boa"))
+	cmd.SetArgs([]string{"--path", dir, "app"})
+	require.NoError(t, cmd.Execute())
+
+	out.Reset()
+	cmd = newInit(boa.Pather("boa"))
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--path", dir, "--diff", "app"})
+	require.NoError(t, cmd.Execute())
+	assert.Empty(t, out.String())
+
+	version := filepath.Join(dir, "version.go")
+	raw, err := ioutil.ReadFile(version)
+	require.NoError(t, err)
+	raw = bytes.Replace(raw, []byte("v0.1.0"), []byte("v1.0.0"), 1)
+	require.NoError(t, ioutil.WriteFile(version, raw, 0644))
+	cmd = newInit(boa.Pather("boa"))
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--path", dir, "--diff", "app"})
+	assert.Equal(t, errDiffer, cmd.Execute())
+	assert.Contains(t, out.String(), "--- a/version.go\n+++ b/version.go\n")
+	assert.Contains(t, out.String(), "-\t\t\tfmt.Println(\"v1.0.0\")\n+\t\t\tfmt.Println(\"v0.1.0\")\n")
+}
+
+func TestInitPolicies(t *testing.T) {
+	dir, err := ioutil.TempDir("", "policies")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	run := func(args ...string) (string, error) {
+		var out bytes.Buffer
+		cmd := newInit(boa.Pather("boa"))
+		cmd.SetOut(&out)
+		cmd.SetArgs(append([]string{"--path", dir, "--manifest=false"}, append(args, "app")...))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+	version := filepath.Join(dir, "version.go")
+	custom := []byte("package main\n")
+
+	_, err = run()
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(version, custom, 0644))
+
+	// All existing files are reported, and nothing is written.
+	_, err = run()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "files already exist: ")
+	assert.Contains(t, err.Error(), filepath.Join(dir, "app.go"))
+	raw, err := ioutil.ReadFile(version)
+	require.NoError(t, err)
+	assert.Equal(t, custom, raw)
+
+	out, err := run("--skip-existing")
+	require.NoError(t, err)
+	assert.Contains(t, out, "Skipped existing "+version+"\n")
+	raw, err = ioutil.ReadFile(version)
+	require.NoError(t, err)
+	assert.Equal(t, custom, raw)
+
+	out, err = run("--backup")
+	require.NoError(t, err)
+	assert.Contains(t, out, "Overwrote "+version+", backup at "+version+".bak\n")
+	assert.Contains(t, out, "Unchanged "+filepath.Join(dir, "app.go")+"\n")
+	raw, err = ioutil.ReadFile(version + ".bak")
+	require.NoError(t, err)
+	assert.Equal(t, custom, raw)
+	raw, err = ioutil.ReadFile(version)
+	require.NoError(t, err)
+	assert.Contains(t, string(raw), "func newVersion(")
+
+	require.NoError(t, ioutil.WriteFile(version, custom, 0644))
+	_, err = run("--backup")
+	assert.EqualError(t, err, "backup file already exists: "+version+".bak")
+	out, err = run("--force")
+	require.NoError(t, err)
+	assert.Contains(t, out, "Overwrote "+version+"\n")
+	raw, err = ioutil.ReadFile(version)
+	require.NoError(t, err)
+	assert.Contains(t, string(raw), "func newVersion(")
+
+	_, err = run("--force", "--backup")
+	assert.EqualError(t, err, "only one of force, skip-existing and backup can be set")
+}
+
+func TestInitManifest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "manifest")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	defer os.Chdir(wd)
+
+	cmd := newInit(boa.Pather("boa"))
+	cmd.SetArgs([]string{"--author", "oncilla", "--license", "mit", "--path", "cmd/app", "app"})
+	require.NoError(t, cmd.Execute())
+
+	raw, err := ioutil.ReadFile(filepath.Join(dir, ".boa.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(raw), "path: cmd/app\n")
+	assert.Contains(t, string(raw), "author: oncilla\n")
+
+	// The path, author and license are taken from the manifest, even in a
+	// nested directory.
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "docs"), 0755))
+	require.NoError(t, os.Chdir(filepath.Join(dir, "docs")))
+	cmd = newAdd(boa.Pather("boa"))
+	cmd.SetArgs([]string{"greet"})
+	require.NoError(t, cmd.Execute())
+	greet, err := ioutil.ReadFile(filepath.Join(dir, "cmd", "app", "greet.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(greet), "Copyright 2020 oncilla\n")
+	assert.Contains(t, string(greet), "Permission is hereby granted")
+
+	// Flags override the manifest.
+	cmd = newAdd(boa.Pather("boa"))
+	cmd.SetArgs([]string{"--author", "other", "hello"})
+	require.NoError(t, cmd.Execute())
+	hello, err := ioutil.ReadFile(filepath.Join(dir, "cmd", "app", "hello.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(hello), "Copyright 2020 other\n")
+}
+
+func TestReleaseVersion(t *testing.T) {
+	testCases := map[string]bool{
+		"v0.1.0":                             true,
+		"v1.2.3-rc.1":                        true,
+		"(devel)":                            false,
+		"":                                   false,
+		"v1.2":                               false,
+		"v1.2.3+dirty":                       false,
+		"v0.0.0-20261019173341-b1fac1c3cb4c": false,
+		"v0.0.0-20261019173341-b1fac1c3cb4c+dirty":  false,
+		"v1.2.4-0.20261019173341-b1fac1c3cb4c":      false,
+		"v1.2.4-rc.1.0.20261019173341-b1fac1c3cb4c": false,
+	}
+	for v, expected := range testCases {
+		assert.Equal(t, expected, releaseVersion(v), v)
+	}
+}
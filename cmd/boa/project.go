@@ -0,0 +1,116 @@
+// Copyright 2020 oncilla
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/oncilla/boa/pkg/manifest"
+	"github.com/oncilla/boa/pkg/tmpl"
+	"github.com/spf13/cobra"
+)
+
+// project resolves the settings of the project that the command operates on.
+// The manifest is searched starting from the path flag, or the working
+// directory if the flag is not set. The path to the main package is taken
+// from the manifest, unless the path flag is set. Likewise, the values of the
+// string flags are taken from the manifest, unless they are set explicitly.
+// The returned manifest is nil, if there is none.
+func project(cmd *cobra.Command, path string, values map[string]*string) (string, *manifest.Manifest, error) {
+	start := path
+	if start == "" {
+		var err error
+		if start, err = os.Getwd(); err != nil {
+			return "", nil, err
+		}
+	}
+	m, err := manifest.Find(start)
+	if err != nil || m == nil {
+		return start, nil, err
+	}
+	if path == "" {
+		path = m.MainDir()
+	}
+	fromManifest := map[string]string{
+		"author":  m.Author,
+		"license": m.License,
+	}
+	for name, value := range values {
+		if v, ok := fromManifest[name]; ok && v != "" && !cmd.Flags().Changed(name) {
+			*value = v
+		}
+	}
+	return path, m, nil
+}
+
+// appName returns the name of the application. It is taken from the manifest,
+// or the name of the main package directory if there is none.
+func appName(m *manifest.Manifest, path string) string {
+	if m != nil && m.Name != "" {
+		return m.Name
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	return filepath.Base(path)
+}
+
+// userTemplateDir returns the directory of the user templates. It is located
+// in $XDG_CONFIG_HOME/boa/templates, or the platform specific user
+// configuration directory if the variable is not set.
+func userTemplateDir() string {
+	config := os.Getenv("XDG_CONFIG_HOME")
+	if config == "" {
+		var err error
+		if config, err = os.UserConfigDir(); err != nil {
+			return ""
+		}
+	}
+	return filepath.Join(config, "boa", "templates")
+}
+
+// projectTemplateDir returns the directory of the project templates. Without a
+// manifest, it is located in the main package directory.
+func projectTemplateDir(m *manifest.Manifest, path string) string {
+	if m != nil {
+		return m.TemplateDir()
+	}
+	return filepath.Join(path, manifest.DefaultTemplates)
+}
+
+// loadTemplates loads the base templates overridden by the user templates,
+// which are in turn overridden by the templates in the project directory.
+func loadTemplates(base tmpl.Templates, projectDir string) (tmpl.Templates, error) {
+	var dirs []string
+	if dir := userTemplateDir(); dir != "" {
+		dirs = append(dirs, dir)
+	}
+	return tmpl.Overlay(base, append(dirs, projectDir)...)
+}
+
+// vars merges the variables of the manifest with the ones set by flag. The
+// variables set by flag take precedence.
+func vars(m *manifest.Manifest, flags map[string]string) map[string]string {
+	merged := map[string]string{}
+	if m != nil {
+		for k, v := range m.Vars {
+			merged[k] = v
+		}
+	}
+	for k, v := range flags {
+		merged[k] = v
+	}
+	return merged
+}
@@ -0,0 +1,79 @@
+// Copyright 2020 oncilla
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/oncilla/boa/pkg/gen"
+	"github.com/oncilla/boa/pkg/source"
+	"github.com/spf13/cobra"
+)
+
+func newRemove(pather CommandPather) *cobra.Command {
+	var flags struct {
+		path    string
+		preview preview
+	}
+
+	var cmd = &cobra.Command{
+		Use:     "remove <command path>",
+		Aliases: []string{"rm"},
+		Short:   "Remove a command from a cobra application",
+		Long: `Remove a command from the cobra application.
+
+The command is identified by its path separated by '/', e.g., 'remote/add'.
+The command and all its subcommands are removed, and the command is removed
+from the AddCommand call of its parent. Files that follow the naming
+convention, e.g., remote_add.go and remote_add_test.go, are deleted. If the
+constructor is located in a different file, only the constructor is removed.
+
+The source is modified using the go/ast package. If a removed constructor is
+still referenced, or the result does not parse, nothing is modified.
+
+To preview the removal without applying it, set the 'dry-run' flag to print the
+updated files and list the removed ones, or the 'diff' flag to print the diff
+against the files on disk. In both cases, the command fails if any file would
+be changed.`,
+		Args: cobra.ExactArgs(1),
+		Example: fmt.Sprintf(`  %[1]s remove greet
+  %[1]s remove remote/add --diff`, pather.CommandPath()),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, _, err := project(cmd, flags.path, nil)
+			if err != nil {
+				return err
+			}
+			parents, name, err := gen.ParseCommandPath(args[0])
+			if err != nil {
+				return err
+			}
+			cmd.SilenceUsage = true
+			pkg, err := source.Load(path)
+			if err != nil {
+				return err
+			}
+			changes, err := pkg.Remove(append(parents, name))
+			if err != nil {
+				return err
+			}
+			if flags.preview.enabled() {
+				return flags.preview.show(cmd.OutOrStdout(), path, changes)
+			}
+			return applyChanges(cmd.OutOrStdout(), changes)
+		},
+	}
+	cmd.Flags().StringVarP(&flags.path, "path", "p", "", "path to main package")
+	flags.preview.register(cmd.Flags())
+	return cmd
+}
@@ -0,0 +1,73 @@
+// Copyright 2020 oncilla
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oncilla/boa/pkg/boa"
+)
+
+func TestRemove(t *testing.T) {
+	dir, cleanup := initProject(t, "greet", "remote", "remote/add")
+	defer cleanup()
+
+	var out bytes.Buffer
+	cmd := newRemove(boa.Pather("boa"))
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--path", dir, "--diff", "remote/add"})
+	assert.Equal(t, errDiffer, cmd.Execute())
+	assert.Contains(t, out.String(), "--- a/remote_add.go\n+++ /dev/null\n")
+	assert.FileExists(t, filepath.Join(dir, "remote_add.go"))
+
+	out.Reset()
+	cmd = newRemove(boa.Pather("boa"))
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--path", dir, "--dry-run", "remote/add"})
+	assert.Equal(t, errDiffer, cmd.Execute())
+	assert.Contains(t, out.String(), "==> remote_add.go (removed) <==\n")
+	assert.Contains(t, out.String(), "==> remote.go <==\n")
+	assert.FileExists(t, filepath.Join(dir, "remote_add.go"))
+
+	cmd = newRemove(boa.Pather("boa"))
+	cmd.SetOut(ioutil.Discard)
+	cmd.SetArgs([]string{"--path", dir, "remote/add"})
+	require.NoError(t, cmd.Execute())
+	_, err := os.Stat(filepath.Join(dir, "remote_add.go"))
+	assert.True(t, os.IsNotExist(err))
+	remote, err := ioutil.ReadFile(filepath.Join(dir, "remote.go"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(remote), "AddCommand")
+	assert.NotContains(t, string(remote), "pkg/boa")
+
+	cmd = newRemove(boa.Pather("boa"))
+	cmd.SetOut(ioutil.Discard)
+	cmd.SetArgs([]string{"--path", dir, "greet"})
+	require.NoError(t, cmd.Execute())
+	app, err := ioutil.ReadFile(filepath.Join(dir, "app.go"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(app), "newGreet")
+	assert.Contains(t, string(app), "newRemote(cmd),")
+
+	cmd = newRemove(boa.Pather("boa"))
+	cmd.SetArgs([]string{"--path", dir, "greet"})
+	assert.Error(t, cmd.Execute())
+}
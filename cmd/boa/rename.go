@@ -0,0 +1,78 @@
+// Copyright 2020 oncilla
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/oncilla/boa/pkg/gen"
+	"github.com/oncilla/boa/pkg/source"
+	"github.com/spf13/cobra"
+)
+
+func newRename(pather CommandPather) *cobra.Command {
+	var flags struct {
+		path    string
+		preview preview
+	}
+
+	var cmd = &cobra.Command{
+		Use:     "rename <command path> <new name>",
+		Aliases: []string{"mv"},
+		Short:   "Rename a command of a cobra application",
+		Long: `Rename a command of the cobra application.
+
+The command is identified by its path separated by '/', e.g., 'remote/add'.
+The constructors of the command and all its subcommands are renamed, including
+the registration with the parent and all references in tests. Files that follow
+the naming convention, e.g., remote_add.go and remote_add_test.go, are renamed
+as well. The Use and Example of the command are updated to the new name.
+
+The source is modified using the go/ast package. If the result does not parse,
+nothing is modified.
+
+To preview the rename without applying it, set the 'dry-run' flag to print the
+updated files, or the 'diff' flag to print the diff against the files on disk.
+In both cases, the command fails if any file would be changed.`,
+		Args: cobra.ExactArgs(2),
+		Example: fmt.Sprintf(`  %[1]s rename greet hello
+  %[1]s rename remote/add append --diff`, pather.CommandPath()),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, _, err := project(cmd, flags.path, nil)
+			if err != nil {
+				return err
+			}
+			parents, name, err := gen.ParseCommandPath(args[0])
+			if err != nil {
+				return err
+			}
+			cmd.SilenceUsage = true
+			pkg, err := source.Load(path)
+			if err != nil {
+				return err
+			}
+			changes, err := pkg.Rename(append(parents, name), args[1])
+			if err != nil {
+				return err
+			}
+			if flags.preview.enabled() {
+				return flags.preview.show(cmd.OutOrStdout(), path, changes)
+			}
+			return applyChanges(cmd.OutOrStdout(), changes)
+		},
+	}
+	cmd.Flags().StringVarP(&flags.path, "path", "p", "", "path to main package")
+	flags.preview.register(cmd.Flags())
+	return cmd
+}
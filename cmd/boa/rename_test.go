@@ -0,0 +1,83 @@
+// Copyright 2020 oncilla
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oncilla/boa/pkg/boa"
+)
+
+// initProject creates an application with the given commands in a temporary
+// directory. The returned function removes the directory.
+func initProject(t *testing.T, commands ...string) (string, func()) {
+	dir, err := ioutil.TempDir("", "project")
+	require.NoError(t, err)
+
+	initCmd := newInit(boa.Pather("boa"))
+	initCmd.SetArgs([]string{"--path", dir, "app"})
+	require.NoError(t, initCmd.Execute())
+	for _, command := range commands {
+		cmd := newAdd(boa.Pather("boa"))
+		cmd.SetArgs([]string{"--path", dir, command})
+		require.NoError(t, cmd.Execute())
+	}
+	return dir, func() { os.RemoveAll(dir) }
+}
+
+func TestRename(t *testing.T) {
+	dir, cleanup := initProject(t, "remote", "remote/add")
+	defer cleanup()
+
+	var out bytes.Buffer
+	cmd := newRename(boa.Pather("boa"))
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--path", dir, "--diff", "remote", "origin"})
+	assert.Equal(t, errDiffer, cmd.Execute())
+	assert.Contains(t, out.String(), "--- a/remote.go\n+++ /dev/null\n")
+	assert.Contains(t, out.String(), "+++ b/origin_add.go\n")
+	assert.Contains(t, out.String(), "-\t\tnewRemote(cmd),\n+\t\tnewOrigin(cmd),\n")
+	assert.FileExists(t, filepath.Join(dir, "remote.go"))
+
+	out.Reset()
+	cmd = newRename(boa.Pather("boa"))
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--path", dir, "--dry-run", "remote", "origin"})
+	assert.Equal(t, errDiffer, cmd.Execute())
+	assert.Contains(t, out.String(), "==> remote.go (removed) <==\n")
+	assert.Contains(t, out.String(), "==> origin.go <==\n")
+	assert.NotContains(t, out.String(), "+++")
+	assert.FileExists(t, filepath.Join(dir, "remote.go"))
+
+	cmd = newRename(boa.Pather("boa"))
+	cmd.SetOut(ioutil.Discard)
+	cmd.SetArgs([]string{"--path", dir, "remote", "origin"})
+	require.NoError(t, cmd.Execute())
+	for _, name := range []string{"remote.go", "remote_add.go"} {
+		_, err := os.Stat(filepath.Join(dir, name))
+		assert.True(t, os.IsNotExist(err), name)
+	}
+	origin, err := ioutil.ReadFile(filepath.Join(dir, "origin.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(origin), `Use:     "origin",`)
+	assert.Contains(t, string(origin), "cmd.AddCommand(newOriginAdd(")
+	assert.FileExists(t, filepath.Join(dir, "origin_add.go"))
+}
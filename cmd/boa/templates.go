@@ -0,0 +1,40 @@
+// Copyright 2020 oncilla
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/oncilla/boa/pkg/boa"
+	"github.com/spf13/cobra"
+)
+
+func newTemplates(pather CommandPather) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "templates",
+		Short: "Manage the templates used for generating code",
+		Long: `Manage the templates used for generating code.
+
+The built-in templates can be overridden by templates in the user template
+directory ($XDG_CONFIG_HOME/boa/templates), which are in turn overridden by the
+project templates (.boa/templates next to the manifest). Templates that do not
+override a built-in template are rendered as additional files by 'boa init'.
+
+The template data model is documented in the tmpl package:
+https://pkg.go.dev/github.com/oncilla/boa/pkg/tmpl`,
+		Example: fmt.Sprintf("  %[1]s templates export", pather.CommandPath()),
+	}
+	cmd.AddCommand(newTemplatesExport(boa.Pather(pather.CommandPath() + " " + cmd.Name())))
+	return cmd
+}
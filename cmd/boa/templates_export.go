@@ -0,0 +1,88 @@
+// Copyright 2020 oncilla
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/oncilla/boa/pkg/tmpl"
+	"github.com/spf13/cobra"
+)
+
+func newTemplatesExport(pather CommandPather) *cobra.Command {
+	var flags struct {
+		path string
+		dir  string
+		user bool
+	}
+
+	var cmd = &cobra.Command{
+		Use:   "export",
+		Short: "Export the built-in templates",
+		Long: `Export the built-in templates as a starting point for custom templates.
+
+By default, the templates are written to the project template directory. Use
+the 'user' flag to write them to the user template directory instead, or the
+'dir' flag to write them to an arbitrary directory. Existing templates are not
+overwritten.`,
+		Args: cobra.NoArgs,
+		Example: fmt.Sprintf(`  %[1]s export
+  %[1]s export --user`, pather.CommandPath()),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, m, err := project(cmd, flags.path, nil)
+			if err != nil {
+				return err
+			}
+			dir := projectTemplateDir(m, path)
+			switch {
+			case flags.dir != "":
+				dir = flags.dir
+			case flags.user:
+				if dir = userTemplateDir(); dir == "" {
+					return fmt.Errorf("user template directory cannot be determined")
+				}
+			}
+			cmd.SilenceUsage = true
+
+			builtin := tmpl.Builtin()
+			var names []string
+			for name := range builtin {
+				if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+					return fmt.Errorf("template already exists: %s", filepath.Join(dir, name))
+				}
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return err
+			}
+			for _, name := range names {
+				file := filepath.Join(dir, name)
+				if err := ioutil.WriteFile(file, []byte(builtin[name]), 0644); err != nil {
+					return err
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), "Exported", file)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&flags.path, "path", "p", "", "path to main package")
+	cmd.Flags().StringVar(&flags.dir, "dir", "", "directory to export the templates to")
+	cmd.Flags().BoolVar(&flags.user, "user", false, "export to the user template directory")
+	return cmd
+}
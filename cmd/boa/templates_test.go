@@ -0,0 +1,121 @@
+// Copyright 2020 oncilla
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oncilla/boa/pkg/boa"
+)
+
+func TestTemplates(t *testing.T) {
+	dir, err := ioutil.TempDir("", "templates")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	defer os.Chdir(wd)
+
+	export := func() error {
+		cmd := newTemplatesExport(boa.Pather("boa templates"))
+		cmd.SetOut(ioutil.Discard)
+		cmd.SetArgs(nil)
+		return cmd.Execute()
+	}
+	require.NoError(t, export())
+	assert.Error(t, export())
+
+	templates := filepath.Join(dir, ".boa", "templates")
+	command := filepath.Join(templates, "command.go.tmpl")
+	raw, err := ioutil.ReadFile(command)
+	require.NoError(t, err)
+	custom := strings.Replace(string(raw), "package main",
+		"// Owned by {{ .Vars.team }}.\npackage main", 1)
+	require.NoError(t, ioutil.WriteFile(command, []byte(custom), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(templates, "README.md.tmpl"),
+		[]byte("# {{ .Name }}\n\nMaintained by {{ .Vars.team }}.\n"), 0644))
+
+	cmd := newInit(boa.Pather("boa"))
+	cmd.SetArgs([]string{"--var", "team=platform", "app"})
+	require.NoError(t, cmd.Execute())
+	readme, err := ioutil.ReadFile(filepath.Join(dir, "README.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "# app\n\nMaintained by platform.\n", string(readme))
+	manifest, err := ioutil.ReadFile(filepath.Join(dir, ".boa.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(manifest), "vars:\n  team: platform\n")
+
+	cmd = newAdd(boa.Pather("boa"))
+	cmd.SetArgs([]string{"greet"})
+	require.NoError(t, cmd.Execute())
+	greet, err := ioutil.ReadFile(filepath.Join(dir, "greet.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(greet), "// Owned by platform.\npackage main")
+
+	cmd = newAdd(boa.Pather("boa"))
+	cmd.SetArgs([]string{"--var", "team=core", "hello"})
+	require.NoError(t, cmd.Execute())
+	hello, err := ioutil.ReadFile(filepath.Join(dir, "hello.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(hello), "// Owned by core.\npackage main")
+}
+
+func TestTemplatesFormat(t *testing.T) {
+	dir, err := ioutil.TempDir("", "templates")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	templates := filepath.Join(dir, ".boa", "templates")
+	require.NoError(t, os.MkdirAll(templates, 0755))
+
+	// Unused imports of customized templates are removed.
+	version := `package main
+import (
+	"github.com/spf13/cobra"
+	"os"
+	"fmt"
+)
+func newVersion(pather CommandPather) *cobra.Command {
+	return &cobra.Command{Use: "version", Run: func(*cobra.Command, []string) { fmt.Println("v1") }}
+}
+`
+	require.NoError(t, ioutil.WriteFile(filepath.Join(templates, "version.go.tmpl"), []byte(version), 0644))
+	cmd := newInit(boa.Pather("boa"))
+	cmd.SetArgs([]string{"--path", dir, "--manifest=false", "app"})
+	require.NoError(t, cmd.Execute())
+	raw, err := ioutil.ReadFile(filepath.Join(dir, "version.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(raw), "import (\n\t\"fmt\"\n\n\t\"github.com/spf13/cobra\"\n)\n")
+
+	// Templates that do not render valid Go leave no files behind.
+	broken := filepath.Join(dir, "broken")
+	require.NoError(t, os.MkdirAll(filepath.Join(broken, ".boa", "templates"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(broken, ".boa", "templates", "version.go.tmpl"),
+		[]byte("package main\n\nfunc newVersion(\n"), 0644))
+	cmd = newInit(boa.Pather("boa"))
+	cmd.SetArgs([]string{"--path", broken, "--manifest=false", "app"})
+	err = cmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "formatting "+filepath.Join(broken, "version.go"))
+	files, err := filepath.Glob(filepath.Join(broken, "[^.]*"))
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
@@ -0,0 +1,75 @@
+// Copyright 2020 my-name
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func newCopy(pather CommandPather) *cobra.Command {
+	var flags struct {
+		sample bool
+	}
+
+	var cmd = &cobra.Command{
+		Use:     "copy <src> [count] [extra...]",
+		Short:   "copy does amazing work!",
+		Example: fmt.Sprintf("  %[1]s copy . --sample", pather.CommandPath()),
+		Args:    cobra.MinimumNArgs(1),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) == 0 {
+				return nil, cobra.ShellCompDirectiveDefault
+			}
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var positional struct {
+				src   string
+				count int
+				extra []time.Duration
+			}
+			var err error
+			positional.src = args[0]
+			if len(args) > 1 {
+				if positional.count, err = strconv.Atoi(args[1]); err != nil {
+					return fmt.Errorf("invalid argument <count>: %s", err)
+				}
+			}
+			if len(args) > 2 {
+				for _, arg := range args[2:] {
+					v, err := time.ParseDuration(arg)
+					if err != nil {
+						return fmt.Errorf("invalid argument <extra>: %s", err)
+					}
+					positional.extra = append(positional.extra, v)
+				}
+			}
+
+			// Add basic sanity checks, where the usage help message should be
+			// printed on error, before this line. After this line, the usage
+			// message is no longer printed on error.
+			cmd.SilenceUsage = true
+
+			// TODO: Amazing work goes here!
+			return nil
+		},
+	}
+	cmd.Flags().BoolVarP(&flags.sample, "sample", "s", false, "sample flag")
+	return cmd
+}
@@ -0,0 +1,92 @@
+// Copyright 2020 my-name
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/oncilla/boa/pkg/boa"
+	"github.com/oncilla/boa/pkg/boa/flag"
+	"github.com/oncilla/boa/pkg/gen/testdata/options"
+	"github.com/spf13/cobra"
+)
+
+func newGreet(pather CommandPather) *cobra.Command {
+	var flags struct {
+		addr            *flag.TCPAddr
+		ip              net.IP
+		shutdownTimeout time.Duration
+		level           string
+		mode            *flag.Enum
+		tags            []string
+		output          *flag.Enum
+	}
+
+	var cmd = &cobra.Command{
+		Use:     "greet <count> [names...]",
+		Short:   "greet does amazing work!",
+		Example: fmt.Sprintf("  %[1]s greet 1 --ip=127.0.0.1", pather.CommandPath()),
+		Args:    cobra.MinimumNArgs(1),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var positional struct {
+				count int
+				names []string
+			}
+			var err error
+			if positional.count, err = strconv.Atoi(args[0]); err != nil {
+				return fmt.Errorf("invalid argument <count>: %s", err)
+			}
+			positional.names = args[1:]
+
+			// Add basic sanity checks, where the usage help message should be
+			// printed on error, before this line. After this line, the usage
+			// message is no longer printed on error.
+			cmd.SilenceUsage = true
+
+			opts := options.Options{
+				Addr:            *flags.addr,
+				IP:              flags.ip,
+				ShutdownTimeout: flags.shutdownTimeout,
+				Level:           options.Level(flags.level),
+				Mode:            flags.mode,
+				Tags:            flags.tags,
+			}
+			result, err := options.Greet(cmd.Context(), positional.count, &opts, positional.names...)
+			if err != nil {
+				return err
+			}
+			return boa.Print(cmd.OutOrStdout(), flags.output.String(), result)
+		},
+	}
+
+	flags.addr = new(flag.TCPAddr)
+	cmd.Flags().Var(flags.addr, "addr", "address to listen on")
+	cmd.Flags().IPVar(&flags.ip, "ip", nil, "address of the upstream server")
+	cmd.Flags().DurationVar(&flags.shutdownTimeout, "shutdown-timeout", 0, "limits the time to wait for open connections")
+	cmd.Flags().StringVar(&flags.level, "level", "", "log level")
+	flags.mode = new(flag.Enum)
+	cmd.Flags().Var(flags.mode, "mode", "mode of operation")
+	cmd.Flags().StringSliceVar(&flags.tags, "tags", nil, "tags attached to all requests")
+	flags.output = flag.NewEnum("text", "text", "json")
+	cmd.Flags().VarP(flags.output, "output", "o", "output format")
+	boa.RegisterCompletions(cmd)
+	return cmd
+}
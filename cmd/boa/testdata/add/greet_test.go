@@ -0,0 +1,54 @@
+// Copyright 2020 my-name
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestGreet(t *testing.T) {
+	testCases := map[string]struct {
+		Args  []string
+		Error bool
+	}{
+		"missing arguments": {
+			Args:  []string{},
+			Error: true,
+		},
+		"unknown flag": {
+			Args:  []string{"1", "--unknown"},
+			Error: true,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			var out bytes.Buffer
+			cmd := newGreet(&cobra.Command{Use: "app"})
+			cmd.SetOut(&out)
+			cmd.SetErr(&out)
+			cmd.SetArgs(tc.Args)
+			err := cmd.Execute()
+			if tc.Error && err == nil {
+				t.Fatalf("expected error, output:\n%s", out.String())
+			}
+			if !tc.Error && err != nil {
+				t.Fatalf("unexpected error: %s\noutput:\n%s", err, out.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright 2020 my-name
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/oncilla/boa/pkg/boa"
+	"github.com/oncilla/boa/pkg/boa/flag"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// listenConfig is the configuration of the listen command. The values
+// are set by flags, environment variables prefixed with ADD_, or the
+// config file, in this order of precedence.
+type listenConfig struct {
+	Addr            net.IP        `mapstructure:"addr"`
+	Port            uint16        `mapstructure:"port"`
+	Level           string        `mapstructure:"level" validate:"oneof=info debug"`
+	ShutdownTimeout time.Duration `mapstructure:"shutdown-timeout"`
+}
+
+func defaultListenConfig() listenConfig {
+	return listenConfig{
+		Addr:            net.ParseIP("127.0.0.1"),
+		Port:            8080,
+		Level:           "info",
+		ShutdownTimeout: 10 * time.Second,
+	}
+}
+
+func newListen(pather CommandPather) *cobra.Command {
+	var flags struct {
+		config string
+	}
+	cfg := defaultListenConfig()
+	v := viper.New()
+	v.SetEnvPrefix("ADD")
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_", "-", "_"))
+
+	var cmd = &cobra.Command{
+		Use:     "listen <arg>",
+		Short:   "listen does amazing work!",
+		Example: fmt.Sprintf("  %[1]s listen arg --config listen.yaml", pather.CommandPath()),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if flags.config != "" {
+				v.SetConfigFile(flags.config)
+				if err := v.ReadInConfig(); err != nil {
+					return err
+				}
+			}
+			hooks := viper.DecodeHook(
+				mapstructure.ComposeDecodeHookFunc(boa.DefaultDecodeHooks()...),
+			)
+			if err := v.Unmarshal(&cfg, hooks); err != nil {
+				return err
+			}
+			// Values that differ from the defaults are checked against the
+			// constraints. The defaults have been checked on generation.
+			defaults := defaultListenConfig()
+			if cfg.Port != defaults.Port && cfg.Port < 1 {
+				return fmt.Errorf("port must be in range [1..65535], got %v", cfg.Port)
+			}
+
+			if err := flag.NewEnum("info", "info", "debug").Set(cfg.Level); err != nil {
+				return fmt.Errorf("level: %s", err)
+			}
+
+			// Add basic sanity checks, where the usage help message should be
+			// printed on error, before this line. After this line, the usage
+			// message is no longer printed on error.
+			cmd.SilenceUsage = true
+
+			// TODO: Amazing work goes here!
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&flags.config, "config", "", "path to the config file")
+	// The config struct is static. Errors are programming errors that are
+	// detected by boa.Check in the tests. The schema is compiled once and
+	// shared by the flags, defaults and environment variables.
+	schema, err := boa.Compile(&cfg)
+	if err != nil {
+		panic(err)
+	}
+	if err := schema.AddFlags(cmd.Flags()); err != nil {
+		panic(err)
+	}
+	schema.SetDefaults(v)
+	if err := schema.BindEnv(v); err != nil {
+		panic(err)
+	}
+	if err := v.BindPFlags(cmd.Flags()); err != nil {
+		panic(err)
+	}
+	cmd.Flags().Lookup("addr").Usage = "addr description"
+	cmd.Flags().Lookup("port").Usage = "port description"
+	cmd.Flags().Lookup("level").Usage = "level description"
+	cmd.Flags().Lookup("shutdown-timeout").Usage = "time to wait for connections to close"
+	boa.RegisterCompletions(cmd)
+	return cmd
+}
@@ -17,21 +17,41 @@ package main
 import (
 	"fmt"
 	"net"
+	"os"
 
+	"github.com/oncilla/boa/pkg/boa"
+	"github.com/oncilla/boa/pkg/boa/flag"
 	"github.com/spf13/cobra"
 )
 
 func newServe(pather CommandPather) *cobra.Command {
 	var flags struct {
-		addr net.IP
-		port uint16
+		addr   net.IP
+		port   uint16
+		mode   *flag.Enum
+		config string
+		name   string
 	}
 
 	var cmd = &cobra.Command{
 		Use:     "serve <arg>",
 		Short:   "serve does amazing work!",
-		Example: fmt.Sprintf("  %[1]s serve --sample", pather.CommandPath()),
+		Example: fmt.Sprintf("  %[1]s serve arg --name=value", pather.CommandPath()),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if cmd.Flags().Changed("port") && flags.port < 1 {
+				return fmt.Errorf("--port must be in range [1..65535], got %v", flags.port)
+			}
+
+			if cmd.Flags().Changed("config") {
+				info, err := os.Stat(flags.config)
+				if err != nil {
+					return fmt.Errorf("--config: %s", err)
+				}
+				if info.IsDir() {
+					return fmt.Errorf("--config: %s is a directory", flags.config)
+				}
+			}
+
 			// Add basic sanity checks, where the usage help message should be
 			// printed on error, before this line. After this line, the usage
 			// message is no longer printed on error.
@@ -43,6 +63,14 @@ func newServe(pather CommandPather) *cobra.Command {
 	}
 
 	cmd.Flags().IPVar(&flags.addr, "addr", nil, "addr description")
-	cmd.Flags().Uint16Var(&flags.port, "port", 0, "port description")
+	cmd.Flags().Uint16VarP(&flags.port, "port", "p", 8080, "port description")
+	flags.mode = flag.NewEnum("fast", "fast", "safe")
+	cmd.Flags().Var(flags.mode, "mode", "mode description")
+	cmd.Flags().StringVar(&flags.config, "config", "", "config description")
+	cmd.Flags().StringVarP(&flags.name, "name", "n", "alice", "who to greet")
+	if err := cmd.MarkFlagRequired("name"); err != nil {
+		panic(err)
+	}
+	boa.RegisterCompletions(cmd)
 	return cmd
 }
@@ -0,0 +1,73 @@
+// Copyright 2020 my-name
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestServe(t *testing.T) {
+	testCases := map[string]struct {
+		Args  []string
+		Error bool
+	}{
+		"required flags": {
+			Args: []string{"arg", "--name=value"},
+		},
+		"addr": {
+			Args: []string{"arg", "--name=value", "--addr=127.0.0.1"},
+		},
+		"port": {
+			Args: []string{"arg", "--name=value", "--port=1"},
+		},
+		"mode": {
+			Args: []string{"arg", "--name=value", "--mode=safe"},
+		},
+		"config": {
+			Args: []string{"arg", "--name=value", "--config=" + os.Args[0]},
+		},
+		"name": {
+			Args: []string{"arg", "--name=value"},
+		},
+		"missing name": {
+			Args:  []string{"arg"},
+			Error: true,
+		},
+		"unknown flag": {
+			Args:  []string{"arg", "--name=value", "--unknown"},
+			Error: true,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			var out bytes.Buffer
+			cmd := newServe(&cobra.Command{Use: "app"})
+			cmd.SetOut(&out)
+			cmd.SetErr(&out)
+			cmd.SetArgs(tc.Args)
+			err := cmd.Execute()
+			if tc.Error && err == nil {
+				t.Fatalf("expected error, output:\n%s", out.String())
+			}
+			if !tc.Error && err != nil {
+				t.Fatalf("unexpected error: %s\noutput:\n%s", err, out.String())
+			}
+		})
+	}
+}
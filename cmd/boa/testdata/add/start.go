@@ -0,0 +1,68 @@
+// Copyright 2020 my-name
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/oncilla/boa/pkg/boa/flag"
+	"github.com/oncilla/boa/pkg/gen/testdata/options"
+	"github.com/spf13/cobra"
+)
+
+func newStart(pather CommandPather) *cobra.Command {
+	var flags struct {
+		addr            *flag.TCPAddr
+		ip              net.IP
+		shutdownTimeout time.Duration
+		level           string
+		mode            *flag.Enum
+		tags            []string
+	}
+
+	var cmd = &cobra.Command{
+		Use:     "start <arg>",
+		Short:   "start does amazing work!",
+		Example: fmt.Sprintf("  %[1]s start arg --ip=127.0.0.1", pather.CommandPath()),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Add basic sanity checks, where the usage help message should be
+			// printed on error, before this line. After this line, the usage
+			// message is no longer printed on error.
+			cmd.SilenceUsage = true
+
+			opts := options.Options{
+				Addr:            *flags.addr,
+				IP:              flags.ip,
+				ShutdownTimeout: flags.shutdownTimeout,
+				Level:           options.Level(flags.level),
+				Mode:            flags.mode,
+				Tags:            flags.tags,
+			}
+			return options.Run(cmd.Context(), opts)
+		},
+	}
+
+	flags.addr = new(flag.TCPAddr)
+	cmd.Flags().Var(flags.addr, "addr", "address to listen on")
+	cmd.Flags().IPVar(&flags.ip, "ip", nil, "address of the upstream server")
+	cmd.Flags().DurationVar(&flags.shutdownTimeout, "shutdown-timeout", 0, "limits the time to wait for open connections")
+	cmd.Flags().StringVar(&flags.level, "level", "", "log level")
+	flags.mode = new(flag.Enum)
+	cmd.Flags().Var(flags.mode, "mode", "mode of operation")
+	cmd.Flags().StringSliceVar(&flags.tags, "tags", nil, "tags attached to all requests")
+	return cmd
+}
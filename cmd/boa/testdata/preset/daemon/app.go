@@ -0,0 +1,56 @@
+// Copyright 2020 my-name
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// CommandPather returns the path to a command.
+type CommandPather interface {
+	CommandPath() string
+}
+
+func main() {
+	cmd := &cobra.Command{
+		Use:           "app",
+		Short:         "app does amazing work!",
+		SilenceErrors: true,
+	}
+	cmd.AddCommand(
+		newCompletion(cmd),
+		newServe(cmd),
+		newVersion(cmd),
+	)
+	if err := cmd.Execute(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(exitCode(err))
+	}
+}
+
+// exitCode returns the exit code for the error. Errors that implement
+// 'ExitCode() int' determine the exit code themselves, e.g., *exec.ExitError.
+// All other errors result in the exit code 1.
+func exitCode(err error) int {
+	var coder interface{ ExitCode() int }
+	if errors.As(err, &coder) {
+		return coder.ExitCode()
+	}
+	return 1
+}
@@ -0,0 +1,97 @@
+// Copyright 2020 my-name
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+)
+
+// writePidfile writes the process ID to the file. It fails if the file
+// already exists, which indicates that another instance is running. The
+// returned function removes the file.
+func writePidfile(file string) (func(), error) {
+	f, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		if os.IsExist(err) {
+			raw, _ := ioutil.ReadFile(file)
+			return nil, fmt.Errorf("pidfile %s exists, is process %s still running?", file, raw)
+		}
+		return nil, err
+	}
+	if _, err := f.WriteString(strconv.Itoa(os.Getpid()) + "\n"); err != nil {
+		f.Close()
+		os.Remove(file)
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(file)
+		return nil, err
+	}
+	return func() { os.Remove(file) }, nil
+}
+
+// logFile is the destination of the standard logger. It can be reopened to
+// support log rotation.
+type logFile struct {
+	mtx  sync.Mutex
+	path string
+	file *os.File
+}
+
+// openLog sets the output of the standard logger to the file. If the path is
+// empty, the logs are written to stderr.
+func openLog(path string) (*logFile, error) {
+	l := &logFile{path: path}
+	if err := l.Reopen(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Reopen closes and reopens the log file.
+func (l *logFile) Reopen() error {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	if l.path == "" {
+		log.SetOutput(os.Stderr)
+		return nil
+	}
+	f, err := os.OpenFile(l.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	log.SetOutput(f)
+	if l.file != nil {
+		l.file.Close()
+	}
+	l.file = f
+	return nil
+}
+
+// Close restores the output of the standard logger and closes the log file.
+func (l *logFile) Close() error {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	log.SetOutput(os.Stderr)
+	if l.file == nil {
+		return nil
+	}
+	return l.file.Close()
+}
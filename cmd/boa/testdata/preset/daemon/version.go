@@ -0,0 +1,33 @@
+// Copyright 2020 my-name
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func newVersion(pather CommandPather) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:     "version",
+		Short:   "Show the version information",
+		Example: fmt.Sprintf("  %[1]s version", pather.CommandPath()),
+		Run: func(cmd *cobra.Command, args []string) {
+			fmt.Println("v0.1.0")
+		},
+	}
+	return cmd
+}
@@ -0,0 +1,22 @@
+// Copyright 2020 my-name
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package version contains the version information that is shared by all
+// binaries in this project.
+package version
+
+// Version is the version of the binaries. It can be set at build time:
+//
+//	go build -ldflags "-X example.com/app/internal/version.Version=v1.0.0" ./cmd/...
+var Version = "v0.1.0"
@@ -0,0 +1,125 @@
+// Copyright 2020 my-name
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/oncilla/boa/pkg/boa"
+	"github.com/oncilla/boa/pkg/boa/flag"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// serveConfig is the configuration of the serve command. The values are set
+// by flags, environment variables prefixed with APP_, or the
+// config file, in this order of precedence.
+type serveConfig struct {
+	Addr            flag.TCPAddr  `mapstructure:"addr"`
+	ShutdownTimeout time.Duration `mapstructure:"shutdown-timeout"`
+}
+
+func defaultServeConfig() serveConfig {
+	return serveConfig{
+		Addr:            flag.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 8080},
+		ShutdownTimeout: 10 * time.Second,
+	}
+}
+
+func newServe(pather CommandPather) *cobra.Command {
+	var flags struct {
+		config string
+	}
+	cfg := defaultServeConfig()
+	v := viper.New()
+	v.SetEnvPrefix("APP")
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_", "-", "_"))
+
+	var cmd = &cobra.Command{
+		Use:     "serve",
+		Short:   "Serve requests until interrupted",
+		Example: fmt.Sprintf("  %[1]s serve --addr 127.0.0.1:8080", pather.CommandPath()),
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if flags.config != "" {
+				v.SetConfigFile(flags.config)
+				if err := v.ReadInConfig(); err != nil {
+					return err
+				}
+			}
+			err := v.Unmarshal(&cfg, viper.DecodeHook(
+				mapstructure.ComposeDecodeHookFunc(boa.DefaultDecodeHooks()...),
+			))
+			if err != nil {
+				return err
+			}
+			cmd.SilenceUsage = true
+
+			server := &http.Server{
+				Addr:    cfg.Addr.String(),
+				Handler: http.NotFoundHandler(),
+			}
+			errs := make(chan error, 1)
+			go func() {
+				errs <- server.ListenAndServe()
+			}()
+			fmt.Fprintln(cmd.OutOrStdout(), "Listening on", cfg.Addr.String())
+
+			signals := make(chan os.Signal, 1)
+			signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+			defer signal.Stop(signals)
+			select {
+			case err := <-errs:
+				return err
+			case s := <-signals:
+				fmt.Fprintf(cmd.OutOrStdout(), "Received %s, shutting down\n", s)
+			}
+
+			// Stop accepting new connections and wait for the active ones
+			// to finish.
+			ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+			defer cancel()
+			return server.Shutdown(ctx)
+		},
+	}
+	cmd.Flags().StringVar(&flags.config, "config", "", "path to the config file")
+	// The config struct is static. Errors are programming errors that are
+	// detected by boa.Check in the tests. The schema is compiled once and
+	// shared by the flags, defaults and environment variables.
+	schema, err := boa.Compile(&cfg)
+	if err != nil {
+		panic(err)
+	}
+	if err := schema.AddFlags(cmd.Flags()); err != nil {
+		panic(err)
+	}
+	schema.SetDefaults(v)
+	if err := schema.BindEnv(v); err != nil {
+		panic(err)
+	}
+	if err := v.BindPFlags(cmd.Flags()); err != nil {
+		panic(err)
+	}
+	return cmd
+}
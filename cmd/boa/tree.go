@@ -0,0 +1,195 @@
+// Copyright 2020 oncilla
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/oncilla/boa/pkg/boa"
+	"github.com/oncilla/boa/pkg/boa/flag"
+	"github.com/oncilla/boa/pkg/source"
+	"github.com/spf13/cobra"
+)
+
+func newTree(pather CommandPather) *cobra.Command {
+	var flags struct {
+		path   string
+		format *flag.Enum
+	}
+
+	var cmd = &cobra.Command{
+		Use:     "tree",
+		Aliases: []string{"inspect"},
+		Short:   "Print the command tree of a cobra application",
+		Long: `Print the command tree of the cobra application.
+
+The main package is parsed statically, the application is not built or run.
+The tree starts at the root command created in the main function and follows
+the constructors that are registered in AddCommand calls. For each command,
+the usage line, the short description, the aliases, the flags and the position
+of the constructor are shown.
+
+Constructors, i.e., functions that take a CommandPather and return a
+*cobra.Command, that are not registered with any command in the tree are listed
+as orphans.
+
+The output format is one of text, json or markdown.`,
+		Args: cobra.NoArgs,
+		Example: fmt.Sprintf(`  %[1]s tree
+  %[1]s tree --format markdown > COMMANDS.md`, pather.CommandPath()),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, _, err := project(cmd, flags.path, nil)
+			if err != nil {
+				return err
+			}
+			cmd.SilenceUsage = true
+			pkg, err := source.Load(path)
+			if err != nil {
+				return err
+			}
+			tree := pkg.Tree()
+			if tree.Root == nil {
+				return fmt.Errorf("no main function found in %s", path)
+			}
+			switch flags.format.Value {
+			case "json":
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				enc.SetEscapeHTML(false)
+				return enc.Encode(tree)
+			case "markdown":
+				writeMarkdown(cmd.OutOrStdout(), tree)
+			default:
+				writeText(cmd.OutOrStdout(), tree)
+			}
+			return nil
+		},
+	}
+	flags.format = flag.NewEnum("text", "text", "json", "markdown")
+	cmd.Flags().StringVarP(&flags.path, "path", "p", "", "path to main package")
+	cmd.Flags().Var(flags.format, "format", "output format (text|json|markdown)")
+	boa.RegisterCompletions(cmd)
+	return cmd
+}
+
+// writeText writes the tree in a human readable form.
+func writeText(w io.Writer, tree *source.Tree) {
+	writeTextCommand(w, tree.Root, "", "")
+	if len(tree.Orphans) == 0 {
+		return
+	}
+	fmt.Fprintln(w, "\nOrphans:")
+	for _, c := range tree.Orphans {
+		writeTextCommand(w, c, "", "")
+	}
+}
+
+// writeTextCommand writes the command and its subcommands. The first prefix is
+// used for the line of the command, the second one for all lines below it.
+func writeTextCommand(w io.Writer, c *source.Command, first, rest string) {
+	line := c.Name()
+	if len(c.Aliases) > 0 {
+		line += fmt.Sprintf(" (aliases: %s)", strings.Join(c.Aliases, ", "))
+	}
+	if c.Short != "" {
+		line += " - " + c.Short
+	}
+	fmt.Fprintf(w, "%s%s [%s]\n", first, line, c.Position)
+
+	indent := rest + "  "
+	if len(c.Commands) > 0 {
+		indent = rest + "│ "
+	}
+	for _, f := range c.Flags {
+		fmt.Fprintf(w, "%s%s\n", indent, flagText(f))
+	}
+	for i, child := range c.Commands {
+		if i == len(c.Commands)-1 {
+			writeTextCommand(w, child, rest+"└── ", rest+"    ")
+			continue
+		}
+		writeTextCommand(w, child, rest+"├── ", rest+"│   ")
+	}
+}
+
+// flagText returns the flag in the form '-s, --sample bool (default false)'.
+func flagText(f source.Flag) string {
+	name := "    --" + f.Name
+	if f.Shorthand != "" {
+		name = "-" + f.Shorthand + ", --" + f.Name
+	}
+	text := name + " " + f.Type
+	if f.Default != "" {
+		text += " (default " + f.Default + ")"
+	}
+	if f.Persistent {
+		text += " (persistent)"
+	}
+	return text
+}
+
+// writeMarkdown writes the tree as Markdown document with one section per
+// command.
+func writeMarkdown(w io.Writer, tree *source.Tree) {
+	fmt.Fprintf(w, "# Commands of %s\n", tree.Root.Name())
+	writeMarkdownCommand(w, tree.Root, "##", "")
+	if len(tree.Orphans) == 0 {
+		return
+	}
+	fmt.Fprintln(w, "\n## Orphans")
+	fmt.Fprintln(w, "\nThe following commands are not registered with any command in the tree.")
+	for _, c := range tree.Orphans {
+		writeMarkdownCommand(w, c, "###", "")
+	}
+}
+
+func writeMarkdownCommand(w io.Writer, c *source.Command, heading, parent string) {
+	path := strings.TrimSpace(parent + " " + c.Name())
+	fmt.Fprintf(w, "\n%s `%s`\n\n", heading, path)
+	if c.Short != "" {
+		fmt.Fprintf(w, "%s\n\n", c.Short)
+	}
+	fmt.Fprintf(w, "- Usage: `%s`\n", c.Use)
+	if len(c.Aliases) > 0 {
+		fmt.Fprintf(w, "- Aliases: `%s`\n", strings.Join(c.Aliases, "`, `"))
+	}
+	fmt.Fprintf(w, "- Defined by `%s` in `%s`\n", c.Constructor, c.Position)
+	if len(c.Flags) > 0 {
+		fmt.Fprint(w, "\n| Flag | Type | Default | Description |\n")
+		fmt.Fprint(w, "| --- | --- | --- | --- |\n")
+		for _, f := range c.Flags {
+			name := "`--" + f.Name + "`"
+			if f.Shorthand != "" {
+				name = "`-" + f.Shorthand + ", --" + f.Name + "`"
+			}
+			def := ""
+			if f.Default != "" {
+				def = "`" + f.Default + "`"
+			}
+			fmt.Fprintf(w, "| %s | %s | %s | %s |\n",
+				name, f.Type, markdownCell(def), markdownCell(f.Usage))
+		}
+	}
+	for _, child := range c.Commands {
+		writeMarkdownCommand(w, child, heading, path)
+	}
+}
+
+// markdownCell escapes the pipe characters in a table cell.
+func markdownCell(s string) string {
+	return strings.Replace(s, "|", `\|`, -1)
+}
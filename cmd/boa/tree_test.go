@@ -0,0 +1,69 @@
+// Copyright 2020 oncilla
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oncilla/boa/pkg/boa"
+	"github.com/oncilla/boa/pkg/source"
+)
+
+func TestTree(t *testing.T) {
+	dir, cleanup := initProject(t, "remote", "remote/add")
+	defer cleanup()
+
+	add := newAdd(boa.Pather("boa"))
+	add.SetArgs([]string{"--path", dir, "--register=false", "greet"})
+	require.NoError(t, add.Execute())
+
+	tree := func(format string) string {
+		var out bytes.Buffer
+		cmd := newTree(boa.Pather("boa"))
+		cmd.SetOut(&out)
+		cmd.SetArgs([]string{"--path", dir, "--format", format})
+		require.NoError(t, cmd.Execute())
+		return out.String()
+	}
+
+	assert.Equal(t, `app - app does amazing work! [app.go:30]
+├── completion - Generates shell completion scripts [completion.go:24]
+│         --shell string (default "bash")
+├── remote - remote does amazing work! [remote.go:24]
+│   │ -s, --sample bool (default false)
+│   └── add - add does amazing work! [remote_add.go:23]
+│         -s, --sample bool (default false)
+└── version - Show the version information [version.go:23]
+
+Orphans:
+greet - greet does amazing work! [greet.go:23]
+  -s, --sample bool (default false)
+`, tree("text"))
+
+	var decoded source.Tree
+	require.NoError(t, json.Unmarshal([]byte(tree("json")), &decoded))
+	require.Len(t, decoded.Root.Commands, 3)
+	assert.Equal(t, "newRemoteAdd", decoded.Root.Commands[1].Commands[0].Constructor)
+	require.Len(t, decoded.Orphans, 1)
+
+	markdown := tree("markdown")
+	assert.Contains(t, markdown, "## `app remote add`\n")
+	assert.Contains(t, markdown, "| `--shell` | string | `\"bash\"` | Shell type (bash\\|zsh\\|fish) |\n")
+	assert.Contains(t, markdown, "## Orphans\n")
+}
@@ -0,0 +1,101 @@
+// Copyright 2020 oncilla
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package boa
+
+import (
+	"fmt"
+	"net"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// Issue describes a single problem in a config struct.
+type Issue struct {
+	// Field is the path of the Go field, e.g., DB.User.
+	Field string
+	// Key is the path of the configuration key, e.g., db.user.
+	Key string
+	// Problem describes what is wrong with the field.
+	Problem string
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%s (key: %q): %s", i.Field, i.Key, i.Problem)
+}
+
+// Issues is a list of problems found in a config struct.
+type Issues []Issue
+
+func (i Issues) Error() string {
+	lines := make([]string, 0, len(i))
+	for _, issue := range i {
+		lines = append(lines, issue.String())
+	}
+	return fmt.Sprintf("%d config issue(s):\n  %s", len(i), strings.Join(lines, "\n  "))
+}
+
+// Check statically analyses the config struct and reports all problems that
+// would cause SetDefaults, BindEnv or AddFlags to misbehave. If problems are
+// found, the returned error is of type Issues.
+//
+// Environment variable names are derived from the key path as described in
+// Field.Env, e.g., both shutdown-timeout and shutdown_timeout map to
+// SHUTDOWN_TIMEOUT and collide.
+//
+// Check is intended to be called in a unit test:
+//
+//	func TestConfig(t *testing.T) {
+//	    if err := boa.Check(defaultConfig()); err != nil {
+//	        t.Fatal(err)
+//	    }
+//	}
+func Check(config interface{}) error {
+	s, err := Compile(config)
+	if err != nil {
+		return Issues{{Problem: err.Error()}}
+	}
+	return s.Check()
+}
+
+var pflagValueType = reflect.TypeOf((*pflag.Value)(nil)).Elem()
+
+// supportedFlag indicates whether AddFlags can register a flag for the type.
+func supportedFlag(t reflect.Type) bool {
+	if t.Implements(pflagValueType) || reflect.PtrTo(t).Implements(pflagValueType) {
+		return true
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch {
+	case t == reflect.TypeOf(time.Duration(1)), t == reflect.TypeOf(net.IP{}):
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Bool, reflect.Float32, reflect.Float64, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	case reflect.Slice:
+		switch t.Elem().Kind() {
+		case reflect.Bool, reflect.Int32, reflect.Int64, reflect.Int, reflect.Uint,
+			reflect.String:
+			return true
+		}
+	}
+	return false
+}
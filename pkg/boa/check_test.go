@@ -0,0 +1,119 @@
+// Copyright 2020 oncilla
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package boa_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oncilla/boa/pkg/boa"
+)
+
+func TestCheck(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		assert.NoError(t, boa.Check(&Config{}))
+	})
+
+	t.Run("not a struct", func(t *testing.T) {
+		assert.Error(t, boa.Check(42))
+	})
+
+	t.Run("issues", func(t *testing.T) {
+		type Embedded struct {
+			Token string `mapstructure:"token"`
+		}
+		type Broken struct {
+			DB struct {
+				User string `mapstructure:"user"`
+			} `mapstructure:"db"`
+			DBUser   string `mapstructure:"db_user"`
+			Token    string `mapstructure:"token"`
+			Embedded `mapstructure:",squash"`
+			Untagged string
+			Values   []float64              `mapstructure:"values"`
+			Any      interface{}            `mapstructure:"any"`
+			Map      map[string]interface{} `mapstructure:"map"`
+			Ignored  func()                 `mapstructure:"-"`
+			port     int
+		}
+
+		err := boa.Check(&Broken{})
+		require.Error(t, err)
+		issues, ok := err.(boa.Issues)
+		require.True(t, ok)
+
+		type issue struct{ Field, Key string }
+		var got []issue
+		for _, i := range issues {
+			got = append(got, issue{Field: i.Field, Key: i.Key})
+		}
+		assert.Equal(t, []issue{
+			{Field: "DBUser", Key: "db_user"},
+			{Field: "Embedded.Token", Key: "token"},
+			{Field: "Untagged", Key: "Untagged"},
+			{Field: "Values", Key: "values"},
+			{Field: "Any", Key: "any"},
+			{Field: "Map", Key: "map"},
+			{Field: "port", Key: ""},
+		}, got)
+		assert.Contains(t, issues[0].Problem, "DB_USER")
+		assert.Contains(t, issues[1].Problem, "duplicate key")
+		assert.Contains(t, issues[2].Problem, "missing mapstructure tag")
+		assert.Contains(t, issues[3].Problem, "unsupported type")
+		assert.Contains(t, issues[4].Problem, "nil interface")
+		assert.Contains(t, issues[6].Problem, "unexported")
+	})
+
+	t.Run("duplicate keys", func(t *testing.T) {
+		type Duplicates struct {
+			DB struct {
+				User string `mapstructure:"user"`
+			} `mapstructure:"db"`
+			DBValue string `mapstructure:"DB"`
+			Name    string `mapstructure:"name"`
+			Group   struct {
+				First string `mapstructure:"first"`
+			} `mapstructure:"Name"`
+		}
+
+		err := boa.Check(&Duplicates{})
+		require.Error(t, err)
+		issues, ok := err.(boa.Issues)
+		require.True(t, ok)
+		require.Len(t, issues, 2)
+		assert.Equal(t, "DBValue", issues[0].Field)
+		assert.Contains(t, issues[0].Problem, "duplicate key, already defined by DB")
+		assert.Equal(t, "Group", issues[1].Field)
+		assert.Contains(t, issues[1].Problem, "duplicate key, already defined by Name")
+	})
+
+	t.Run("dashed env collision", func(t *testing.T) {
+		type Dashed struct {
+			Dashed     string `mapstructure:"shutdown-timeout"`
+			Underscore string `mapstructure:"shutdown_timeout"`
+		}
+
+		err := boa.Check(&Dashed{})
+		require.Error(t, err)
+		issues, ok := err.(boa.Issues)
+		require.True(t, ok)
+		require.Len(t, issues, 1)
+		assert.Equal(t, "Underscore", issues[0].Field)
+		assert.Equal(t, "shutdown_timeout", issues[0].Key)
+		assert.Contains(t, issues[0].Problem,
+			"environment variable SHUTDOWN_TIMEOUT collides with Dashed")
+	})
+}
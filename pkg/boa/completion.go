@@ -0,0 +1,114 @@
+// Copyright 2020 oncilla
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package boa
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/oncilla/boa/pkg/boa/flag"
+)
+
+// AnnotationValues is the flag annotation that holds the allowed values of a
+// flag. AddFlags sets it for fields with a 'oneof' validation tag, e.g.,
+// `validate:"oneof=fast safe"`.
+const AnnotationValues = "boa_values"
+
+// RegisterCompletions registers shell completion functions for all flags of
+// the command, based on the flag value type:
+//
+//   - Values that list their allowed values with a 'Values() []string' method,
+//     e.g., flag.Enum and flag.LogLevel, and flags annotated with
+//     AnnotationValues complete the allowed values.
+//   - flag.ExistingFile completes file names, flag.ExistingDir directory names.
+//   - Address values, e.g., flag.TCPAddr or flag.HostPort, complete nothing.
+//
+// Flags that already have a completion function keep it. RegisterCompletions
+// should be called after all flags have been added to the command.
+func RegisterCompletions(cmd *cobra.Command) {
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		registerCompletion(cmd, f)
+	})
+}
+
+func registerCompletion(cmd *cobra.Command, f *pflag.Flag) {
+	if values := f.Annotations[AnnotationValues]; len(values) > 0 {
+		completeValues(cmd, f.Name, values)
+		return
+	}
+	switch v := f.Value.(type) {
+	case interface{ Values() []string }:
+		completeValues(cmd, f.Name, v.Values())
+	case *flag.ExistingFile:
+		// Errors are ignored, the flag is known to exist.
+		_ = cmd.MarkFlagFilename(f.Name)
+	case *flag.ExistingDir:
+		_ = cmd.MarkFlagDirname(f.Name)
+	case *flag.TCPAddr, *flag.UDPAddr, *flag.HostPort, *flag.Addr:
+		_ = cmd.RegisterFlagCompletionFunc(f.Name, noCompletion)
+	}
+}
+
+func completeValues(cmd *cobra.Command, name string, values []string) {
+	// The error is ignored, it only indicates that a completion function is
+	// already registered, which takes precedence.
+	_ = cmd.RegisterFlagCompletionFunc(name,
+		func(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			var matches []string
+			for _, v := range values {
+				if strings.HasPrefix(v, toComplete) {
+					matches = append(matches, v)
+				}
+			}
+			return matches, cobra.ShellCompDirectiveNoFileComp
+		},
+	)
+}
+
+func noCompletion(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
+
+// oneOf returns the allowed values listed in the 'oneof' rule of the validate
+// tag. Values that contain spaces can be quoted with single quotes.
+func oneOf(tag string) []string {
+	for _, rule := range strings.Split(tag, ",") {
+		if !strings.HasPrefix(rule, "oneof=") {
+			continue
+		}
+		var values []string
+		var current strings.Builder
+		quoted := false
+		for _, r := range strings.TrimPrefix(rule, "oneof=") {
+			switch {
+			case r == '\'':
+				quoted = !quoted
+			case r == ' ' && !quoted:
+				if current.Len() > 0 {
+					values = append(values, current.String())
+					current.Reset()
+				}
+			default:
+				current.WriteRune(r)
+			}
+		}
+		if current.Len() > 0 {
+			values = append(values, current.String())
+		}
+		return values
+	}
+	return nil
+}
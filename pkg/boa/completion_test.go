@@ -0,0 +1,59 @@
+// Copyright 2020 oncilla
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package boa_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oncilla/boa/pkg/boa"
+	"github.com/oncilla/boa/pkg/boa/flag"
+)
+
+func TestRegisterCompletions(t *testing.T) {
+	config := struct {
+		Mode  *flag.Enum    `mapstructure:"mode"`
+		Level flag.LogLevel `mapstructure:"level"`
+		Color string        `mapstructure:"color" validate:"required,oneof=red green 'light blue'"`
+		DB    flag.HostPort `mapstructure:"db"`
+		Name  string        `mapstructure:"name"`
+	}{
+		Mode: flag.NewEnum("fast", "fast", "safe"),
+	}
+
+	complete := func(t *testing.T, args ...string) []string {
+		root := &cobra.Command{Use: "root"}
+		cmd := &cobra.Command{Use: "run", Run: func(*cobra.Command, []string) {}}
+		root.AddCommand(cmd)
+		require.NoError(t, boa.AddFlags(cmd.Flags(), &config))
+		boa.RegisterCompletions(cmd)
+
+		var out bytes.Buffer
+		root.SetOut(&out)
+		root.SetArgs(append([]string{cobra.ShellCompRequestCmd, "run"}, args...))
+		require.NoError(t, root.Execute())
+		return strings.Split(strings.TrimSpace(out.String()), "\n")
+	}
+
+	assert.Equal(t, []string{"fast", "safe", ":4"}, complete(t, "--mode", ""))
+	assert.Equal(t, []string{"warn", ":4"}, complete(t, "--level", "w"))
+	assert.Equal(t, []string{"red", "green", "light blue", ":4"}, complete(t, "--color", ""))
+	assert.Equal(t, []string{":4"}, complete(t, "--db", ""))
+	assert.Equal(t, []string{":0"}, complete(t, "--name", ""))
+}
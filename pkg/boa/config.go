@@ -17,10 +17,8 @@ import (
 	"fmt"
 	"net"
 	"reflect"
-	"strings"
 	"time"
 
-	"github.com/mitchellh/mapstructure"
 	"github.com/spf13/pflag"
 )
 
@@ -33,144 +31,159 @@ type ConfigRegistry interface {
 // SetDefaults sets the default values based on the values contained in the
 // provided config struct.
 func SetDefaults(r ConfigRegistry, config interface{}) error {
-	m := map[string]interface{}{}
-	if err := mapstructure.Decode(config, &m); err != nil {
+	s, err := Compile(config)
+	if err != nil {
 		return err
 	}
-	setDefaults(r, nil, m)
+	s.SetDefaults(r)
 	return nil
 }
 
-func setDefaults(r ConfigRegistry, p path, config map[string]interface{}) {
-	for key, value := range config {
-		keyPath := p.Extend(key)
-		if m, ok := value.(map[string]interface{}); ok {
-			setDefaults(r, keyPath, m)
-			continue
-		}
-		r.SetDefault(keyPath.String(), value)
-	}
-}
-
 // BindEnv binds the environemt variables based on the config struct.
 func BindEnv(r ConfigRegistry, config interface{}) error {
-	m := map[string]interface{}{}
-	if err := mapstructure.Decode(config, &m); err != nil {
+	s, err := Compile(config)
+	if err != nil {
 		return err
 	}
-	return bindEnv(r, nil, m)
+	return s.BindEnv(r)
 }
 
-func bindEnv(r ConfigRegistry, p path, config map[string]interface{}) error {
-	for key, value := range config {
-		keyPath := p.Extend(key)
-		if m, ok := value.(map[string]interface{}); ok {
-			if err := bindEnv(r, keyPath, m); err != nil {
-				return err
-			}
-			continue
-		}
-		if err := r.BindEnv(keyPath.String()); err != nil {
-			return err
-		}
+// Validate checks the decoded config against the values allowed by the
+// defaults. See Schema.Validate for details.
+func Validate(defaults, config interface{}) error {
+	s, err := Compile(defaults)
+	if err != nil {
+		return err
 	}
-	return nil
+	return s.Validate(config)
 }
 
 // AddFlags adds flags to the provided flag set based on the config struct.
 // Default are set according to the values present in the config struct.
 func AddFlags(r *pflag.FlagSet, config interface{}) error {
-	m := map[string]interface{}{}
-	if err := mapstructure.Decode(config, &m); err != nil {
+	s, err := Compile(config)
+	if err != nil {
 		return err
 	}
-	return addFlags(r, nil, m)
+	return s.AddFlags(r)
 }
 
-// nolint: gocyclo
-func addFlags(r *pflag.FlagSet, p path, config map[string]interface{}) error {
-	for key, value := range config {
-		keyPath := p.Extend(key)
-		if m, ok := value.(map[string]interface{}); ok {
-			if err := addFlags(r, keyPath, m); err != nil {
+// AddFlags adds flags to the provided flag set for all fields. Default are set
+// according to the values present in the compiled config struct.
+//
+// Fields with a 'oneof' validation tag are annotated with AnnotationValues,
+// which is used by RegisterCompletions.
+func (s *Schema) AddFlags(r *pflag.FlagSet) error {
+	for _, f := range s.Fields {
+		if err := addFlag(r, f); err != nil {
+			return err
+		}
+		if values := oneOf(f.Tag.Get("validate")); len(values) > 0 {
+			if err := r.SetAnnotation(f.Key, AnnotationValues, values); err != nil {
 				return err
 			}
-			continue
 		}
+	}
+	return nil
+}
 
-		if v, ok := value.(pflag.Value); ok {
-			r.Var(v, keyPath.String(), "")
-			continue
+// nolint: gocyclo
+func addFlag(r *pflag.FlagSet, f *Field) error {
+	key := f.Key
+	v := reflect.ValueOf(f.Default)
+	if !v.IsValid() {
+		return fmt.Errorf("unsupported value: %s (%s): nil interface", key, f.Name)
+	}
+	if value, ok := newValue(v); ok {
+		flag := r.VarPF(value, key, "", "")
+		if b, ok := value.(interface{ IsBoolFlag() bool }); ok && b.IsBoolFlag() {
+			flag.NoOptDefVal = "true"
 		}
+		return nil
+	}
 
-		t, v := reflect.TypeOf(value), reflect.ValueOf(value)
-		if t.Kind() == reflect.Ptr || t.Kind() == reflect.Interface {
-			t, v = t.Elem(), reflect.Indirect(v)
-			if v.Kind() == reflect.Invalid {
-				v = reflect.Zero(t)
-			}
+	t := v.Type()
+	if t.Kind() == reflect.Ptr {
+		t, v = t.Elem(), reflect.Indirect(v)
+		if v.Kind() == reflect.Invalid {
+			v = reflect.Zero(t)
 		}
-		switch {
-		case t == reflect.TypeOf(time.Duration(1)):
-			r.Duration(keyPath.String(), time.Duration(v.Int()), "")
-		case t == reflect.TypeOf(net.IP{}):
-			r.IP(keyPath.String(), v.Interface().(net.IP), "")
-		case t.Kind() == reflect.Bool:
-			r.Bool(keyPath.String(), v.Bool(), "")
-		case t.Kind() == reflect.Float32:
-			r.Float32(keyPath.String(), float32(v.Float()), "")
-		case t.Kind() == reflect.Float64:
-			r.Float64(keyPath.String(), v.Float(), "")
-		case t.Kind() == reflect.Int8:
-			r.Int8(keyPath.String(), int8(v.Int()), "")
-		case t.Kind() == reflect.Int16:
-			r.Int16(keyPath.String(), int16(v.Int()), "")
-		case t.Kind() == reflect.Int32:
-			r.Int32(keyPath.String(), int32(v.Int()), "")
-		case t.Kind() == reflect.Int64:
-			r.Int64(keyPath.String(), v.Int(), "")
-		case t.Kind() == reflect.Int:
-			r.Int(keyPath.String(), int(v.Int()), "")
-		case t.Kind() == reflect.Uint8:
-			r.Uint8(keyPath.String(), uint8(v.Uint()), "")
-		case t.Kind() == reflect.Uint16:
-			r.Uint16(keyPath.String(), uint16(v.Uint()), "")
-		case t.Kind() == reflect.Uint32:
-			r.Uint32(keyPath.String(), uint32(v.Uint()), "")
-		case t.Kind() == reflect.Uint64:
-			r.Uint64(keyPath.String(), v.Uint(), "")
-		case t.Kind() == reflect.Uint:
-			r.Uint(keyPath.String(), uint(v.Uint()), "")
-		case t.Kind() == reflect.String:
-			r.String(keyPath.String(), v.String(), "")
-		case t.Kind() == reflect.Slice:
-			switch t.Elem().Kind() {
-			case reflect.Bool:
-				r.BoolSlice(keyPath.String(), v.Interface().([]bool), "")
-			case reflect.Int32:
-				r.Int32Slice(keyPath.String(), v.Interface().([]int32), "")
-			case reflect.Int64:
-				r.Int64Slice(keyPath.String(), v.Interface().([]int64), "")
-			case reflect.Int:
-				r.IntSlice(keyPath.String(), v.Interface().([]int), "")
-			case reflect.Uint:
-				r.UintSlice(keyPath.String(), v.Interface().([]uint), "")
-			case reflect.String:
-				r.StringSlice(keyPath.String(), v.Interface().([]string), "")
-			}
-		default:
-			return fmt.Errorf("unsupported value: %s (%T)", keyPath, value)
+	}
+	switch {
+	case t == reflect.TypeOf(time.Duration(1)):
+		r.Duration(key, time.Duration(v.Int()), "")
+	case t == reflect.TypeOf(net.IP{}):
+		r.IP(key, v.Convert(t).Interface().(net.IP), "")
+	case t.Kind() == reflect.Bool:
+		r.Bool(key, v.Bool(), "")
+	case t.Kind() == reflect.Float32:
+		r.Float32(key, float32(v.Float()), "")
+	case t.Kind() == reflect.Float64:
+		r.Float64(key, v.Float(), "")
+	case t.Kind() == reflect.Int8:
+		r.Int8(key, int8(v.Int()), "")
+	case t.Kind() == reflect.Int16:
+		r.Int16(key, int16(v.Int()), "")
+	case t.Kind() == reflect.Int32:
+		r.Int32(key, int32(v.Int()), "")
+	case t.Kind() == reflect.Int64:
+		r.Int64(key, v.Int(), "")
+	case t.Kind() == reflect.Int:
+		r.Int(key, int(v.Int()), "")
+	case t.Kind() == reflect.Uint8:
+		r.Uint8(key, uint8(v.Uint()), "")
+	case t.Kind() == reflect.Uint16:
+		r.Uint16(key, uint16(v.Uint()), "")
+	case t.Kind() == reflect.Uint32:
+		r.Uint32(key, uint32(v.Uint()), "")
+	case t.Kind() == reflect.Uint64:
+		r.Uint64(key, v.Uint(), "")
+	case t.Kind() == reflect.Uint:
+		r.Uint(key, uint(v.Uint()), "")
+	case t.Kind() == reflect.String:
+		r.String(key, v.String(), "")
+	case t.Kind() == reflect.Slice && supportedFlag(t):
+		s := reflect.MakeSlice(reflect.SliceOf(t.Elem()), v.Len(), v.Len())
+		reflect.Copy(s, v)
+		switch s := s.Interface().(type) {
+		case []bool:
+			r.BoolSlice(key, s, "")
+		case []int32:
+			r.Int32Slice(key, s, "")
+		case []int64:
+			r.Int64Slice(key, s, "")
+		case []int:
+			r.IntSlice(key, s, "")
+		case []uint:
+			r.UintSlice(key, s, "")
+		case []string:
+			r.StringSlice(key, s, "")
 		}
+	default:
+		return fmt.Errorf("unsupported value: %s (%s): %s", key, f.Name, v.Type())
 	}
 	return nil
 }
 
-type path []string
-
-func (p path) Extend(key string) path {
-	return append([]string(p), key)
-}
-
-func (p path) String() string {
-	return strings.Join([]string(p), ".")
+// newValue returns a copy of the value as pflag.Value, if the value or a
+// pointer to it implements pflag.Value. A nil pointer is replaced by a pointer
+// to the zero value. Copying ensures that parsing flags does not modify the
+// defaults.
+func newValue(v reflect.Value) (pflag.Value, bool) {
+	t := v.Type()
+	switch {
+	case t.Kind() == reflect.Ptr && t.Implements(pflagValueType):
+		p := reflect.New(t.Elem())
+		if !v.IsNil() {
+			p.Elem().Set(v.Elem())
+		}
+		return p.Interface().(pflag.Value), true
+	case t.Implements(pflagValueType):
+		return v.Interface().(pflag.Value), true
+	case reflect.PtrTo(t).Implements(pflagValueType):
+		p := reflect.New(t)
+		p.Elem().Set(v)
+		return p.Interface().(pflag.Value), true
+	}
+	return nil, false
 }
@@ -14,10 +14,12 @@
 package boa_test
 
 import (
+	"encoding/json"
 	"net"
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/mitchellh/mapstructure"
@@ -130,3 +132,161 @@ func TestAddFlags(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "token", token)
 }
+
+type OptionalConfig struct {
+	Port    flag.OptionalInt      `mapstructure:"port"`
+	Name    flag.OptionalString   `mapstructure:"name"`
+	Verbose flag.OptionalBool     `mapstructure:"verbose"`
+	Timeout flag.OptionalDuration `mapstructure:"timeout"`
+}
+
+func TestViperOptional(t *testing.T) {
+	defaults := OptionalConfig{
+		Port: flag.OptionalInt{Value: 8080},
+	}
+
+	v := viper.New()
+	v.SetEnvPrefix("boa_optional")
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	s := pflag.NewFlagSet("", pflag.ContinueOnError)
+	require.NoError(t, boa.AddFlags(s, &defaults))
+	require.NoError(t, boa.SetDefaults(v, &defaults))
+	require.NoError(t, boa.BindEnv(v, &defaults))
+	require.NoError(t, v.BindPFlags(s))
+
+	os.Setenv("BOA_OPTIONAL_NAME", "")
+	defer os.Unsetenv("BOA_OPTIONAL_NAME")
+	require.NoError(t, s.Parse([]string{"--port", "0", "--verbose"}))
+	v.SetConfigType("yaml")
+	require.NoError(t, v.ReadConfig(strings.NewReader("timeout: 1s\n")))
+
+	var config OptionalConfig
+	err := v.Unmarshal(&config, viper.DecodeHook(
+		mapstructure.ComposeDecodeHookFunc(
+			boa.DefaultDecodeHooks()...,
+		),
+	))
+	require.NoError(t, err)
+
+	assert.True(t, config.Port.IsSet())
+	assert.Equal(t, 0, config.Port.Value)
+	assert.True(t, config.Verbose.IsSet())
+	assert.True(t, config.Verbose.Value)
+	assert.True(t, config.Timeout.IsSet())
+	assert.Equal(t, time.Second, config.Timeout.Value)
+	// Viper ignores empty environment variables.
+	assert.False(t, config.Name.IsSet())
+
+	raw, err := json.Marshal(config)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"Port":0,"Name":null,"Verbose":true,"Timeout":"1s"}`, string(raw))
+
+	// Without any explicit value, the defaults are used and reported unset.
+	v = viper.New()
+	require.NoError(t, boa.SetDefaults(v, &defaults))
+	config = OptionalConfig{}
+	require.NoError(t, v.Unmarshal(&config))
+	assert.False(t, config.Port.IsSet())
+	assert.Equal(t, 8080, config.Port.Value)
+}
+
+func TestViperValueTypeDefaults(t *testing.T) {
+	type DefaultConfig struct {
+		Mode  flag.FileMode     `mapstructure:"mode"`
+		File  flag.ExistingFile `mapstructure:"file"`
+		Level flag.LogLevel     `mapstructure:"level"`
+		Size  flag.ByteSize     `mapstructure:"size"`
+	}
+	defaults := DefaultConfig{Mode: 0644, Size: 10 * flag.MiB}
+	require.NoError(t, boa.Check(&defaults))
+
+	v := viper.New()
+	require.NoError(t, boa.SetDefaults(v, &defaults))
+	var config DefaultConfig
+	err := v.Unmarshal(&config, viper.DecodeHook(
+		mapstructure.ComposeDecodeHookFunc(
+			boa.DefaultDecodeHooks()...,
+		),
+	))
+	require.NoError(t, err)
+	assert.Equal(t, defaults, config)
+}
+
+func TestViperValueTypes(t *testing.T) {
+	type ValueConfig struct {
+		URL   *flag.URL     `mapstructure:"url"`
+		Size  flag.ByteSize `mapstructure:"size"`
+		Level flag.LogLevel `mapstructure:"level"`
+		Mode  flag.FileMode `mapstructure:"mode"`
+		Net   flag.CIDR     `mapstructure:"net"`
+		Zone  flag.Location `mapstructure:"zone"`
+		Enum  flag.Enum     `mapstructure:"enum"`
+		Expr  flag.Regexp   `mapstructure:"expr"`
+		Start flag.Time     `mapstructure:"start"`
+		DB    flag.HostPort `mapstructure:"db"`
+		Sock  *flag.Addr    `mapstructure:"sock"`
+	}
+	defaults := ValueConfig{
+		Size:  10 * flag.MiB,
+		Level: flag.LogLevelInfo,
+		Mode:  0644,
+		Enum:  *flag.NewEnum("fast", "fast", "safe"),
+	}
+	require.NoError(t, boa.Check(&defaults))
+
+	s := pflag.NewFlagSet("", pflag.ContinueOnError)
+	require.NoError(t, boa.AddFlags(s, &defaults))
+	assert.Equal(t, "10MiB", s.Lookup("size").DefValue)
+	assert.Equal(t, "byte-size", s.Lookup("size").Value.Type())
+	assert.Error(t, s.Parse([]string{"--enum", "slow"}))
+
+	v := viper.New()
+	require.NoError(t, boa.SetDefaults(v, &defaults))
+	v.SetConfigType("yaml")
+	require.NoError(t, v.ReadConfig(strings.NewReader(`
+url: https://example.com
+size: 1024
+level: DEBUG
+mode: "0600"
+net: 192.0.2.0/24
+zone: UTC
+enum: safe
+expr: ^a+$
+start: "2020-06-01T10:00:00Z"
+db: db.internal:5432
+sock: unix:///run/app.sock
+`)))
+
+	var config ValueConfig
+	err := v.Unmarshal(&config, viper.DecodeHook(
+		mapstructure.ComposeDecodeHookFunc(
+			boa.DefaultDecodeHooks()...,
+		),
+	))
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com", config.URL.String())
+	assert.Equal(t, flag.KiB, config.Size)
+	assert.Equal(t, flag.LogLevelDebug, config.Level)
+	assert.Equal(t, flag.FileMode(0600), config.Mode)
+	assert.Equal(t, "192.0.2.0/24", config.Net.String())
+	assert.Equal(t, "UTC", config.Zone.String())
+	assert.Equal(t, "safe", config.Enum.Value)
+	assert.True(t, config.Expr.MatchString("aaa"))
+	assert.Equal(t, "2020-06-01T10:00:00Z", config.Start.String())
+	assert.Equal(t, flag.HostPort{Host: "db.internal", Port: 5432}, config.DB)
+	assert.Equal(t, &flag.Addr{Network: "unix", Address: "/run/app.sock"}, config.Sock)
+	require.NoError(t, boa.Validate(&defaults, &config))
+
+	// The decoded enum does not know the allowed values of the default.
+	v.Set("enum", "slow")
+	require.NoError(t, v.Unmarshal(&config, viper.DecodeHook(
+		mapstructure.ComposeDecodeHookFunc(
+			boa.DefaultDecodeHooks()...,
+		),
+	)))
+	assert.Equal(t, "slow", config.Enum.Value)
+	err = boa.Validate(&defaults, &config)
+	require.Error(t, err)
+	require.IsType(t, boa.Issues{}, err)
+	assert.Equal(t, "enum", err.(boa.Issues)[0].Key)
+}
@@ -0,0 +1,197 @@
+// Copyright 2020 oncilla
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flag
+
+import (
+	"context"
+	"encoding"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+var _ pflag.Value = (*HostPort)(nil)
+var _ encoding.TextUnmarshaler = (*HostPort)(nil)
+var _ encoding.TextMarshaler = (*HostPort)(nil)
+
+// HostPort implements pflags.Value. In contrast to TCPAddr and UDPAddr, the
+// host is not resolved when the value is set. Only the syntax is validated,
+// and the host name is kept, such that it can be resolved lazily. An empty
+// host is allowed, e.g., ':8080'.
+type HostPort struct {
+	Host string
+	Port uint16
+}
+
+func (hp *HostPort) Set(input string) error {
+	host, port, err := splitHostPort(input)
+	if err != nil {
+		return err
+	}
+	hp.Host, hp.Port = host, port
+	return nil
+}
+
+// ResolveTCP resolves the host and returns the TCP address.
+func (hp *HostPort) ResolveTCP() (*net.TCPAddr, error) {
+	return net.ResolveTCPAddr("tcp", hp.String())
+}
+
+// ResolveUDP resolves the host and returns the UDP address.
+func (hp *HostPort) ResolveUDP() (*net.UDPAddr, error) {
+	return net.ResolveUDPAddr("udp", hp.String())
+}
+
+func (hp *HostPort) UnmarshalText(b []byte) error {
+	return hp.Set(string(b))
+}
+
+func (hp *HostPort) Type() string {
+	return "host-port"
+}
+
+func (hp *HostPort) MarshalText() ([]byte, error) {
+	return []byte(hp.String()), nil
+}
+
+func (hp *HostPort) String() string {
+	return net.JoinHostPort(hp.Host, strconv.Itoa(int(hp.Port)))
+}
+
+var _ pflag.Value = (*Addr)(nil)
+var _ encoding.TextUnmarshaler = (*Addr)(nil)
+var _ encoding.TextMarshaler = (*Addr)(nil)
+
+// Addr implements pflags.Value. It holds a network address in the form
+// '<network>://<address>', e.g., tcp://localhost:8080, udp://[::1]:53 or
+// unix:///run/app.sock. If the network is omitted, tcp is assumed. Supported
+// networks are tcp, tcp4, tcp6, udp, udp4, udp6 and unix. Like HostPort, host
+// names are not resolved when the value is set.
+type Addr struct {
+	Network string
+	Address string
+}
+
+func (a *Addr) Set(input string) error {
+	network, address := "tcp", input
+	if i := strings.Index(input, "://"); i != -1 {
+		network, address = input[:i], input[i+3:]
+	}
+	switch network {
+	case "tcp", "tcp4", "tcp6", "udp", "udp4", "udp6":
+		if _, _, err := splitHostPort(address); err != nil {
+			return err
+		}
+	case "unix":
+		if address == "" {
+			return fmt.Errorf("missing socket path: %s", input)
+		}
+	default:
+		return fmt.Errorf("unsupported network %q: %s", network, input)
+	}
+	a.Network, a.Address = network, address
+	return nil
+}
+
+// Listen announces on the address. It is only supported for the stream
+// oriented networks tcp and unix.
+func (a *Addr) Listen() (net.Listener, error) {
+	switch a.Network {
+	case "udp", "udp4", "udp6":
+		return nil, fmt.Errorf("cannot listen on packet network %s, use ListenPacket", a.Network)
+	}
+	return net.Listen(a.Network, a.Address)
+}
+
+// ListenPacket announces on the address. It is only supported for the packet
+// oriented network udp.
+func (a *Addr) ListenPacket() (net.PacketConn, error) {
+	switch a.Network {
+	case "udp", "udp4", "udp6":
+		return net.ListenPacket(a.Network, a.Address)
+	}
+	return nil, fmt.Errorf("cannot listen for packets on network %s, use Listen", a.Network)
+}
+
+// DialContext connects to the address using the provided dialer. If the
+// dialer is nil, the zero dialer is used.
+func (a *Addr) DialContext(ctx context.Context, d *net.Dialer) (net.Conn, error) {
+	if d == nil {
+		d = &net.Dialer{}
+	}
+	return d.DialContext(ctx, a.Network, a.Address)
+}
+
+func (a *Addr) UnmarshalText(b []byte) error {
+	return a.Set(string(b))
+}
+
+func (a *Addr) Type() string {
+	return "addr"
+}
+
+func (a *Addr) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+func (a *Addr) String() string {
+	if a.Network == "" {
+		return ""
+	}
+	return a.Network + "://" + a.Address
+}
+
+// splitHostPort splits the input into host and port and validates the syntax
+// without resolving the host.
+func splitHostPort(input string) (string, uint16, error) {
+	host, portStr, err := net.SplitHostPort(input)
+	if err != nil {
+		return "", 0, err
+	}
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid port %q: %s", portStr, input)
+	}
+	if host != "" && net.ParseIP(host) == nil && !validHostname(host) {
+		return "", 0, fmt.Errorf("invalid host %q: %s", host, input)
+	}
+	return host, uint16(port), nil
+}
+
+// validHostname checks the host name syntax according to RFC 1123.
+func validHostname(host string) bool {
+	host = strings.TrimSuffix(host, ".")
+	if len(host) == 0 || len(host) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(host, ".") {
+		if len(label) == 0 || len(label) > 63 {
+			return false
+		}
+		if label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, r := range label {
+			switch {
+			case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-':
+			default:
+				return false
+			}
+		}
+	}
+	return true
+}
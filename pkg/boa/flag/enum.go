@@ -0,0 +1,167 @@
+// Copyright 2020 oncilla
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flag
+
+import (
+	"encoding"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+var _ pflag.Value = (*Enum)(nil)
+var _ encoding.TextUnmarshaler = (*Enum)(nil)
+var _ encoding.TextMarshaler = (*Enum)(nil)
+
+// Enum implements pflags.Value. It only accepts the values listed in Allowed.
+// If Allowed is empty, any value is accepted. This is the case when the enum
+// is decoded by the decoding hooks, which have no access to the allowed
+// values. Use boa.Validate to check the decoded value against the allowed
+// values of the default.
+type Enum struct {
+	Value   string
+	Allowed []string
+}
+
+// NewEnum returns an enum with the default value and the allowed values.
+func NewEnum(value string, allowed ...string) *Enum {
+	return &Enum{Value: value, Allowed: allowed}
+}
+
+// Values returns the allowed values.
+func (e *Enum) Values() []string {
+	return e.Allowed
+}
+
+func (e *Enum) Set(input string) error {
+	if len(e.Allowed) == 0 {
+		e.Value = input
+		return nil
+	}
+	for _, v := range e.Allowed {
+		if v == input {
+			e.Value = input
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid value %q, allowed: %s", input, strings.Join(e.Allowed, ", "))
+}
+
+func (e *Enum) UnmarshalText(b []byte) error {
+	return e.Set(string(b))
+}
+
+func (e *Enum) Type() string {
+	return "enum"
+}
+
+func (e *Enum) MarshalText() ([]byte, error) {
+	return []byte(e.String()), nil
+}
+
+func (e *Enum) String() string {
+	return e.Value
+}
+
+// Log levels supported by LogLevel.
+const (
+	LogLevelDebug LogLevel = "debug"
+	LogLevelInfo  LogLevel = "info"
+	LogLevelWarn  LogLevel = "warn"
+	LogLevelError LogLevel = "error"
+)
+
+var _ pflag.Value = (*LogLevel)(nil)
+var _ encoding.TextUnmarshaler = (*LogLevel)(nil)
+var _ encoding.TextMarshaler = (*LogLevel)(nil)
+
+// LogLevel implements pflags.Value. The level is case insensitive and is
+// normalized to lower case.
+type LogLevel string
+
+// Values returns the allowed log levels.
+func (l *LogLevel) Values() []string {
+	return []string{
+		string(LogLevelDebug),
+		string(LogLevelInfo),
+		string(LogLevelWarn),
+		string(LogLevelError),
+	}
+}
+
+func (l *LogLevel) Set(input string) error {
+	for _, v := range l.Values() {
+		if strings.EqualFold(v, input) {
+			*l = LogLevel(v)
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid log level %q, allowed: %s", input,
+		strings.Join(l.Values(), ", "))
+}
+
+func (l *LogLevel) UnmarshalText(b []byte) error {
+	return l.Set(string(b))
+}
+
+func (l *LogLevel) Type() string {
+	return "log-level"
+}
+
+func (l *LogLevel) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+func (l *LogLevel) String() string {
+	return string(*l)
+}
+
+var _ pflag.Value = (*Regexp)(nil)
+var _ encoding.TextUnmarshaler = (*Regexp)(nil)
+var _ encoding.TextMarshaler = (*Regexp)(nil)
+
+// Regexp implements pflags.Value. The zero value holds no expression.
+type Regexp struct {
+	*regexp.Regexp
+}
+
+func (r *Regexp) Set(input string) error {
+	p, err := regexp.Compile(input)
+	if err != nil {
+		return err
+	}
+	r.Regexp = p
+	return nil
+}
+
+func (r *Regexp) UnmarshalText(b []byte) error {
+	return r.Set(string(b))
+}
+
+func (r *Regexp) Type() string {
+	return "regexp"
+}
+
+func (r *Regexp) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+func (r *Regexp) String() string {
+	if r.Regexp == nil {
+		return ""
+	}
+	return r.Regexp.String()
+}
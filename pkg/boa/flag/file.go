@@ -0,0 +1,128 @@
+// Copyright 2020 oncilla
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flag
+
+import (
+	"encoding"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/spf13/pflag"
+)
+
+var _ pflag.Value = (*FileMode)(nil)
+var _ encoding.TextUnmarshaler = (*FileMode)(nil)
+var _ encoding.TextMarshaler = (*FileMode)(nil)
+
+// FileMode implements pflags.Value. It is parsed and formatted as octal
+// number, e.g., 0644.
+type FileMode os.FileMode
+
+func (m *FileMode) Set(input string) error {
+	p, err := strconv.ParseUint(input, 8, 32)
+	if err != nil {
+		return fmt.Errorf("invalid file mode %q: must be an octal number", input)
+	}
+	*m = FileMode(p)
+	return nil
+}
+
+func (m *FileMode) UnmarshalText(b []byte) error {
+	return m.Set(string(b))
+}
+
+func (m *FileMode) Type() string {
+	return "file-mode"
+}
+
+func (m *FileMode) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+func (m *FileMode) String() string {
+	return fmt.Sprintf("%#o", uint32(*m))
+}
+
+var _ pflag.Value = (*ExistingFile)(nil)
+var _ encoding.TextUnmarshaler = (*ExistingFile)(nil)
+var _ encoding.TextMarshaler = (*ExistingFile)(nil)
+
+// ExistingFile implements pflags.Value. It only accepts paths to existing
+// files that are not directories. Defaults are not checked.
+type ExistingFile string
+
+func (f *ExistingFile) Set(input string) error {
+	info, err := os.Stat(input)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return fmt.Errorf("%s is a directory", input)
+	}
+	*f = ExistingFile(input)
+	return nil
+}
+
+func (f *ExistingFile) UnmarshalText(b []byte) error {
+	return f.Set(string(b))
+}
+
+func (f *ExistingFile) Type() string {
+	return "file"
+}
+
+func (f *ExistingFile) MarshalText() ([]byte, error) {
+	return []byte(f.String()), nil
+}
+
+func (f *ExistingFile) String() string {
+	return string(*f)
+}
+
+var _ pflag.Value = (*ExistingDir)(nil)
+var _ encoding.TextUnmarshaler = (*ExistingDir)(nil)
+var _ encoding.TextMarshaler = (*ExistingDir)(nil)
+
+// ExistingDir implements pflags.Value. It only accepts paths to existing
+// directories. Defaults are not checked.
+type ExistingDir string
+
+func (d *ExistingDir) Set(input string) error {
+	info, err := os.Stat(input)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", input)
+	}
+	*d = ExistingDir(input)
+	return nil
+}
+
+func (d *ExistingDir) UnmarshalText(b []byte) error {
+	return d.Set(string(b))
+}
+
+func (d *ExistingDir) Type() string {
+	return "dir"
+}
+
+func (d *ExistingDir) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func (d *ExistingDir) String() string {
+	return string(*d)
+}
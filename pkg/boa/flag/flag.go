@@ -23,7 +23,8 @@ import (
 var _ pflag.Value = (*TCPAddr)(nil)
 var _ encoding.TextMarshaler = (*TCPAddr)(nil)
 
-// TCPAddr implements pflags.Value
+// TCPAddr implements pflags.Value. The address is resolved when the value is
+// set. Use HostPort or Addr to keep the host name and resolve it lazily.
 type TCPAddr net.TCPAddr
 
 func (addr *TCPAddr) Set(input string) error {
@@ -51,11 +52,15 @@ func (addr *TCPAddr) String() string {
 	return (*net.TCPAddr)(addr).String()
 }
 
-// UDPAddr implements pflags.Value
+var _ pflag.Value = (*UDPAddr)(nil)
+var _ encoding.TextMarshaler = (*UDPAddr)(nil)
+
+// UDPAddr implements pflags.Value. The address is resolved when the value is
+// set. Use HostPort or Addr to keep the host name and resolve it lazily.
 type UDPAddr net.UDPAddr
 
 func (addr *UDPAddr) Set(input string) error {
-	p, err := net.ResolveUDPAddr("tcp", input)
+	p, err := net.ResolveUDPAddr("udp", input)
 	if err != nil {
 		return err
 	}
@@ -68,7 +73,7 @@ func (addr *UDPAddr) UnmarshalText(b []byte) error {
 }
 
 func (addr *UDPAddr) Type() string {
-	return "tcp-addr"
+	return "udp-addr"
 }
 
 func (addr *UDPAddr) MarshalText() ([]byte, error) {
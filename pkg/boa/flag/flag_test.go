@@ -0,0 +1,234 @@
+// Copyright 2020 oncilla
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flag_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oncilla/boa/pkg/boa/flag"
+)
+
+func TestValues(t *testing.T) {
+	dir, err := ioutil.TempDir("", "flag")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "file")
+	require.NoError(t, ioutil.WriteFile(file, nil, 0644))
+
+	testCases := map[string]struct {
+		Value   pflag.Value
+		Input   string
+		Output  string
+		Invalid []string
+	}{
+		"url": {
+			Value:  &flag.URL{},
+			Input:  "https://example.com/path?q=1",
+			Output: "https://example.com/path?q=1",
+		},
+		"cidr": {
+			Value:   &flag.CIDR{},
+			Input:   "192.0.2.1/24",
+			Output:  "192.0.2.0/24",
+			Invalid: []string{"192.0.2.1", "invalid"},
+		},
+		"byte size": {
+			Value:   new(flag.ByteSize),
+			Input:   "10MiB",
+			Output:  "10MiB",
+			Invalid: []string{"10XB", "MiB", "-1"},
+		},
+		"byte size si": {
+			Value:  new(flag.ByteSize),
+			Input:  "1.5 kb",
+			Output: "1500B",
+		},
+		"byte size plain": {
+			Value:  new(flag.ByteSize),
+			Input:  "2048",
+			Output: "2KiB",
+		},
+		"byte size max": {
+			Value:   new(flag.ByteSize),
+			Input:   "9223372036854775807",
+			Output:  "9223372036854775807B",
+			Invalid: []string{"100000PiB", "8192PiB", "8192.0PiB", "9223372036854775808"},
+		},
+		"enum": {
+			Value:   flag.NewEnum("fast", "fast", "safe"),
+			Input:   "safe",
+			Output:  "safe",
+			Invalid: []string{"slow", "Safe"},
+		},
+		"log level": {
+			Value:   new(flag.LogLevel),
+			Input:   "WARN",
+			Output:  "warn",
+			Invalid: []string{"trace"},
+		},
+		"regexp": {
+			Value:   &flag.Regexp{},
+			Input:   "^https://",
+			Output:  "^https://",
+			Invalid: []string{"("},
+		},
+		"time": {
+			Value:   &flag.Time{},
+			Input:   "2020-06-01T10:00:00+02:00",
+			Output:  "2020-06-01T10:00:00+02:00",
+			Invalid: []string{"2020-06-01"},
+		},
+		"location": {
+			Value:   &flag.Location{},
+			Input:   "UTC",
+			Output:  "UTC",
+			Invalid: []string{"Nowhere/Special"},
+		},
+		"file mode": {
+			Value:   new(flag.FileMode),
+			Input:   "644",
+			Output:  "0644",
+			Invalid: []string{"0999", "rw"},
+		},
+		"existing file": {
+			Value:   new(flag.ExistingFile),
+			Input:   file,
+			Output:  file,
+			Invalid: []string{dir, filepath.Join(dir, "missing")},
+		},
+		"udp addr": {
+			Value:  &flag.UDPAddr{},
+			Input:  "127.0.0.1:53",
+			Output: "127.0.0.1:53",
+		},
+		"host port": {
+			Value:   &flag.HostPort{},
+			Input:   "db.internal:5432",
+			Output:  "db.internal:5432",
+			Invalid: []string{"db.internal", "db.internal:99999", "db_internal:80", "-db:80"},
+		},
+		"host port ipv6": {
+			Value:  &flag.HostPort{},
+			Input:  "[::1]:80",
+			Output: "[::1]:80",
+		},
+		"addr": {
+			Value:   &flag.Addr{},
+			Input:   "db.internal:5432",
+			Output:  "tcp://db.internal:5432",
+			Invalid: []string{"tcp://db.internal", "sctp://db:80", "unix://"},
+		},
+		"addr unix": {
+			Value:  &flag.Addr{},
+			Input:  "unix:///run/app.sock",
+			Output: "unix:///run/app.sock",
+		},
+		"existing dir": {
+			Value:   new(flag.ExistingDir),
+			Input:   dir,
+			Output:  dir,
+			Invalid: []string{file, filepath.Join(dir, "missing")},
+		},
+	}
+	for name, tc := range testCases {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, tc.Value.Set(tc.Input))
+			assert.Equal(t, tc.Output, tc.Value.String())
+			for _, input := range tc.Invalid {
+				assert.Error(t, tc.Value.Set(input), input)
+			}
+		})
+	}
+}
+
+func TestAddrListen(t *testing.T) {
+	dir, err := ioutil.TempDir("", "addr")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	for _, input := range []string{"tcp://127.0.0.1:0", "unix://" + filepath.Join(dir, "sock")} {
+		var addr flag.Addr
+		require.NoError(t, addr.Set(input))
+		l, err := addr.Listen()
+		require.NoError(t, err, input)
+		dialed := flag.Addr{Network: addr.Network, Address: l.Addr().String()}
+		conn, err := dialed.DialContext(context.Background(), nil)
+		require.NoError(t, err, input)
+		assert.NoError(t, conn.Close())
+		assert.NoError(t, l.Close())
+	}
+
+	var addr flag.Addr
+	require.NoError(t, addr.Set("udp://127.0.0.1:0"))
+	_, err = addr.Listen()
+	assert.Error(t, err)
+	conn, err := addr.ListenPacket()
+	require.NoError(t, err)
+	assert.NoError(t, conn.Close())
+}
+
+func TestOptionalJSON(t *testing.T) {
+	type config struct {
+		Verbose flag.OptionalBool
+		Port    flag.OptionalInt
+		Workers flag.OptionalUint
+		Ratio   flag.OptionalFloat64
+		Name    flag.OptionalString
+		Timeout flag.OptionalDuration
+	}
+	var dumped config
+	require.NoError(t, dumped.Verbose.Set("true"))
+	require.NoError(t, dumped.Port.Set("0"))
+	require.NoError(t, dumped.Workers.Set("4"))
+	require.NoError(t, dumped.Ratio.Set("0.5"))
+	require.NoError(t, dumped.Timeout.Set("1m30s"))
+	raw, err := json.Marshal(dumped)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"Verbose":true,"Port":0,"Workers":4,"Ratio":0.5,"Name":null,"Timeout":"1m30s"}`,
+		string(raw))
+
+	// Set values are restored, null keeps the default and reports it unset.
+	loaded := config{Name: flag.OptionalString{Value: "default"}}
+	require.NoError(t, json.Unmarshal(raw, &loaded))
+	assert.True(t, loaded.Verbose.IsSet())
+	assert.True(t, loaded.Verbose.Value)
+	assert.True(t, loaded.Port.IsSet())
+	assert.Equal(t, 0, loaded.Port.Value)
+	assert.True(t, loaded.Workers.IsSet())
+	assert.Equal(t, uint(4), loaded.Workers.Value)
+	assert.True(t, loaded.Ratio.IsSet())
+	assert.Equal(t, 0.5, loaded.Ratio.Value)
+	assert.False(t, loaded.Name.IsSet())
+	assert.Equal(t, "default", loaded.Name.Value)
+	assert.True(t, loaded.Timeout.IsSet())
+	assert.Equal(t, 90*time.Second, loaded.Timeout.Value)
+
+	again, err := json.Marshal(loaded)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(raw), string(again))
+
+	assert.Error(t, json.Unmarshal([]byte(`{"Port":"eight"}`), &loaded))
+	assert.Error(t, json.Unmarshal([]byte(`{"Timeout":"soon"}`), &loaded))
+}
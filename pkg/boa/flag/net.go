@@ -0,0 +1,90 @@
+// Copyright 2020 oncilla
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flag
+
+import (
+	"encoding"
+	"net"
+	"net/url"
+
+	"github.com/spf13/pflag"
+)
+
+var _ pflag.Value = (*URL)(nil)
+var _ encoding.TextUnmarshaler = (*URL)(nil)
+var _ encoding.TextMarshaler = (*URL)(nil)
+
+// URL implements pflags.Value
+type URL url.URL
+
+func (u *URL) Set(input string) error {
+	p, err := url.Parse(input)
+	if err != nil {
+		return err
+	}
+	*u = URL(*p)
+	return nil
+}
+
+func (u *URL) UnmarshalText(b []byte) error {
+	return u.Set(string(b))
+}
+
+func (u *URL) Type() string {
+	return "url"
+}
+
+func (u *URL) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+func (u *URL) String() string {
+	return (*url.URL)(u).String()
+}
+
+var _ pflag.Value = (*CIDR)(nil)
+var _ encoding.TextUnmarshaler = (*CIDR)(nil)
+var _ encoding.TextMarshaler = (*CIDR)(nil)
+
+// CIDR implements pflags.Value. It holds the network of the parsed CIDR
+// notation, e.g., 192.0.2.0/24 for the input 192.0.2.1/24.
+type CIDR net.IPNet
+
+func (c *CIDR) Set(input string) error {
+	_, n, err := net.ParseCIDR(input)
+	if err != nil {
+		return err
+	}
+	*c = CIDR(*n)
+	return nil
+}
+
+func (c *CIDR) UnmarshalText(b []byte) error {
+	return c.Set(string(b))
+}
+
+func (c *CIDR) Type() string {
+	return "cidr"
+}
+
+func (c *CIDR) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+func (c *CIDR) String() string {
+	if c.IP == nil {
+		return ""
+	}
+	return (*net.IPNet)(c).String()
+}
@@ -0,0 +1,403 @@
+// Copyright 2020 oncilla
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flag
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// Optional is implemented by all optional values. Optional values distinguish
+// between a value that has been set explicitly, e.g., through a command line
+// flag, environment variable or configuration file, and a value that is
+// merely the default.
+//
+// The default is the Value field of the optional. It is reported as unset.
+// All optional values implement pflag.Value and encoding.TextUnmarshaler,
+// such that they are supported by boa.AddFlags and the decoding hooks.
+// When marshaled to JSON or YAML, an unset optional is encoded as null. When
+// unmarshaled from JSON, null leaves the optional unset with its default.
+type Optional interface {
+	IsSet() bool
+}
+
+var _ pflag.Value = (*OptionalBool)(nil)
+var _ encoding.TextUnmarshaler = (*OptionalBool)(nil)
+var _ encoding.TextMarshaler = (*OptionalBool)(nil)
+
+// OptionalBool is a bool value that records whether it has been set.
+type OptionalBool struct {
+	Value bool
+	set   bool
+}
+
+// IsSet indicates whether the value has been set explicitly.
+func (o *OptionalBool) IsSet() bool {
+	return o.set
+}
+
+func (o *OptionalBool) Set(input string) error {
+	v, err := strconv.ParseBool(input)
+	if err != nil {
+		return err
+	}
+	o.Value, o.set = v, true
+	return nil
+}
+
+// IsBoolFlag allows the flag to be passed without value.
+func (o *OptionalBool) IsBoolFlag() bool {
+	return true
+}
+
+func (o *OptionalBool) Type() string {
+	return "bool"
+}
+
+func (o *OptionalBool) String() string {
+	return strconv.FormatBool(o.Value)
+}
+
+func (o *OptionalBool) UnmarshalText(b []byte) error {
+	return o.Set(string(b))
+}
+
+func (o *OptionalBool) MarshalText() ([]byte, error) {
+	return []byte(o.String()), nil
+}
+
+func (o OptionalBool) MarshalJSON() ([]byte, error) {
+	return marshalJSON(o.set, o.Value)
+}
+
+func (o *OptionalBool) UnmarshalJSON(b []byte) error {
+	var err error
+	o.set, err = unmarshalJSON(b, &o.Value)
+	return err
+}
+
+func (o OptionalBool) MarshalYAML() (interface{}, error) {
+	return marshalYAML(o.set, o.Value)
+}
+
+var _ pflag.Value = (*OptionalInt)(nil)
+var _ encoding.TextUnmarshaler = (*OptionalInt)(nil)
+var _ encoding.TextMarshaler = (*OptionalInt)(nil)
+
+// OptionalInt is an int value that records whether it has been set.
+type OptionalInt struct {
+	Value int
+	set   bool
+}
+
+// IsSet indicates whether the value has been set explicitly.
+func (o *OptionalInt) IsSet() bool {
+	return o.set
+}
+
+func (o *OptionalInt) Set(input string) error {
+	v, err := parseInt(input)
+	if err != nil {
+		return err
+	}
+	o.Value, o.set = v, true
+	return nil
+}
+
+func (o *OptionalInt) Type() string {
+	return "int"
+}
+
+func (o *OptionalInt) String() string {
+	return strconv.Itoa(o.Value)
+}
+
+func (o *OptionalInt) UnmarshalText(b []byte) error {
+	return o.Set(string(b))
+}
+
+func (o *OptionalInt) MarshalText() ([]byte, error) {
+	return []byte(o.String()), nil
+}
+
+func (o OptionalInt) MarshalJSON() ([]byte, error) {
+	return marshalJSON(o.set, o.Value)
+}
+
+func (o *OptionalInt) UnmarshalJSON(b []byte) error {
+	var err error
+	o.set, err = unmarshalJSON(b, &o.Value)
+	return err
+}
+
+func (o OptionalInt) MarshalYAML() (interface{}, error) {
+	return marshalYAML(o.set, o.Value)
+}
+
+var _ pflag.Value = (*OptionalUint)(nil)
+var _ encoding.TextUnmarshaler = (*OptionalUint)(nil)
+var _ encoding.TextMarshaler = (*OptionalUint)(nil)
+
+// OptionalUint is a uint value that records whether it has been set.
+type OptionalUint struct {
+	Value uint
+	set   bool
+}
+
+// IsSet indicates whether the value has been set explicitly.
+func (o *OptionalUint) IsSet() bool {
+	return o.set
+}
+
+func (o *OptionalUint) Set(input string) error {
+	v, err := parseUint(input)
+	if err != nil {
+		return err
+	}
+	o.Value, o.set = v, true
+	return nil
+}
+
+func (o *OptionalUint) Type() string {
+	return "uint"
+}
+
+func (o *OptionalUint) String() string {
+	return strconv.FormatUint(uint64(o.Value), 10)
+}
+
+func (o *OptionalUint) UnmarshalText(b []byte) error {
+	return o.Set(string(b))
+}
+
+func (o *OptionalUint) MarshalText() ([]byte, error) {
+	return []byte(o.String()), nil
+}
+
+func (o OptionalUint) MarshalJSON() ([]byte, error) {
+	return marshalJSON(o.set, o.Value)
+}
+
+func (o *OptionalUint) UnmarshalJSON(b []byte) error {
+	var err error
+	o.set, err = unmarshalJSON(b, &o.Value)
+	return err
+}
+
+func (o OptionalUint) MarshalYAML() (interface{}, error) {
+	return marshalYAML(o.set, o.Value)
+}
+
+var _ pflag.Value = (*OptionalFloat64)(nil)
+var _ encoding.TextUnmarshaler = (*OptionalFloat64)(nil)
+var _ encoding.TextMarshaler = (*OptionalFloat64)(nil)
+
+// OptionalFloat64 is a float64 value that records whether it has been set.
+type OptionalFloat64 struct {
+	Value float64
+	set   bool
+}
+
+// IsSet indicates whether the value has been set explicitly.
+func (o *OptionalFloat64) IsSet() bool {
+	return o.set
+}
+
+func (o *OptionalFloat64) Set(input string) error {
+	v, err := strconv.ParseFloat(input, 64)
+	if err != nil {
+		return err
+	}
+	o.Value, o.set = v, true
+	return nil
+}
+
+func (o *OptionalFloat64) Type() string {
+	return "float64"
+}
+
+func (o *OptionalFloat64) String() string {
+	return strconv.FormatFloat(o.Value, 'g', -1, 64)
+}
+
+func (o *OptionalFloat64) UnmarshalText(b []byte) error {
+	return o.Set(string(b))
+}
+
+func (o *OptionalFloat64) MarshalText() ([]byte, error) {
+	return []byte(o.String()), nil
+}
+
+func (o OptionalFloat64) MarshalJSON() ([]byte, error) {
+	return marshalJSON(o.set, o.Value)
+}
+
+func (o *OptionalFloat64) UnmarshalJSON(b []byte) error {
+	var err error
+	o.set, err = unmarshalJSON(b, &o.Value)
+	return err
+}
+
+func (o OptionalFloat64) MarshalYAML() (interface{}, error) {
+	return marshalYAML(o.set, o.Value)
+}
+
+var _ pflag.Value = (*OptionalString)(nil)
+var _ encoding.TextUnmarshaler = (*OptionalString)(nil)
+var _ encoding.TextMarshaler = (*OptionalString)(nil)
+
+// OptionalString is a string value that records whether it has been set.
+type OptionalString struct {
+	Value string
+	set   bool
+}
+
+// IsSet indicates whether the value has been set explicitly.
+func (o *OptionalString) IsSet() bool {
+	return o.set
+}
+
+func (o *OptionalString) Set(input string) error {
+	o.Value, o.set = input, true
+	return nil
+}
+
+func (o *OptionalString) Type() string {
+	return "string"
+}
+
+func (o *OptionalString) String() string {
+	return o.Value
+}
+
+func (o *OptionalString) UnmarshalText(b []byte) error {
+	return o.Set(string(b))
+}
+
+func (o *OptionalString) MarshalText() ([]byte, error) {
+	return []byte(o.String()), nil
+}
+
+func (o OptionalString) MarshalJSON() ([]byte, error) {
+	return marshalJSON(o.set, o.Value)
+}
+
+func (o *OptionalString) UnmarshalJSON(b []byte) error {
+	var err error
+	o.set, err = unmarshalJSON(b, &o.Value)
+	return err
+}
+
+func (o OptionalString) MarshalYAML() (interface{}, error) {
+	return marshalYAML(o.set, o.Value)
+}
+
+var _ pflag.Value = (*OptionalDuration)(nil)
+var _ encoding.TextUnmarshaler = (*OptionalDuration)(nil)
+var _ encoding.TextMarshaler = (*OptionalDuration)(nil)
+
+// OptionalDuration is a time.Duration value that records whether it has been set.
+type OptionalDuration struct {
+	Value time.Duration
+	set   bool
+}
+
+// IsSet indicates whether the value has been set explicitly.
+func (o *OptionalDuration) IsSet() bool {
+	return o.set
+}
+
+func (o *OptionalDuration) Set(input string) error {
+	v, err := time.ParseDuration(input)
+	if err != nil {
+		return err
+	}
+	o.Value, o.set = v, true
+	return nil
+}
+
+func (o *OptionalDuration) Type() string {
+	return "duration"
+}
+
+func (o *OptionalDuration) String() string {
+	return o.Value.String()
+}
+
+func (o *OptionalDuration) UnmarshalText(b []byte) error {
+	return o.Set(string(b))
+}
+
+func (o *OptionalDuration) MarshalText() ([]byte, error) {
+	return []byte(o.String()), nil
+}
+
+func (o OptionalDuration) MarshalJSON() ([]byte, error) {
+	return marshalJSON(o.set, o.Value.String())
+}
+
+func (o *OptionalDuration) UnmarshalJSON(b []byte) error {
+	var v string
+	set, err := unmarshalJSON(b, &v)
+	if err != nil || !set {
+		o.set = false
+		return err
+	}
+	return o.Set(v)
+}
+
+func (o OptionalDuration) MarshalYAML() (interface{}, error) {
+	return marshalYAML(o.set, o.Value.String())
+}
+
+func parseInt(input string) (int, error) {
+	v, err := strconv.ParseInt(input, 0, strconv.IntSize)
+	return int(v), err
+}
+
+func parseUint(input string) (uint, error) {
+	v, err := strconv.ParseUint(input, 0, strconv.IntSize)
+	return uint(v), err
+}
+
+func marshalJSON(set bool, v interface{}) ([]byte, error) {
+	if !set {
+		return []byte("null"), nil
+	}
+	return json.Marshal(v)
+}
+
+// unmarshalJSON decodes the JSON value into v and reports whether it is set.
+// A null value leaves v, i.e., the default, unchanged.
+func unmarshalJSON(b []byte, v interface{}) (bool, error) {
+	if string(bytes.TrimSpace(b)) == "null" {
+		return false, nil
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func marshalYAML(set bool, v interface{}) (interface{}, error) {
+	if !set {
+		return nil, nil
+	}
+	return v, nil
+}
@@ -0,0 +1,126 @@
+// Copyright 2020 oncilla
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flag
+
+import (
+	"encoding"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// Byte sizes in SI and IEC units.
+const (
+	Byte ByteSize = 1
+
+	KB = 1000 * Byte
+	MB = 1000 * KB
+	GB = 1000 * MB
+	TB = 1000 * GB
+	PB = 1000 * TB
+
+	KiB = 1024 * Byte
+	MiB = 1024 * KiB
+	GiB = 1024 * MiB
+	TiB = 1024 * GiB
+	PiB = 1024 * TiB
+)
+
+// byteUnits lists the units in the order they are preferred when formatting.
+var byteUnits = []struct {
+	Name string
+	Size ByteSize
+}{
+	{"PiB", PiB}, {"TiB", TiB}, {"GiB", GiB}, {"MiB", MiB}, {"KiB", KiB},
+	{"PB", PB}, {"TB", TB}, {"GB", GB}, {"MB", MB}, {"kB", KB},
+	{"B", Byte},
+}
+
+var _ pflag.Value = (*ByteSize)(nil)
+var _ encoding.TextUnmarshaler = (*ByteSize)(nil)
+var _ encoding.TextMarshaler = (*ByteSize)(nil)
+
+// ByteSize implements pflags.Value. It parses sizes with an optional SI or
+// IEC unit suffix, e.g., 512, 1.5kB or 10MiB. Units are case insensitive.
+// Sizes that do not fit into an int64 are rejected.
+type ByteSize uint64
+
+func (s *ByteSize) Set(input string) error {
+	input = strings.TrimSpace(input)
+	i := strings.IndexFunc(input, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if i == -1 {
+		i = len(input)
+	}
+	num, unit := input[:i], strings.TrimSpace(input[i:])
+	unitSize := Byte
+	if unit != "" {
+		found := false
+		for _, u := range byteUnits {
+			if strings.EqualFold(u.Name, unit) {
+				unitSize, found = u.Size, true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("unknown unit %q in byte size %q", unit, input)
+		}
+	}
+	if v, err := strconv.ParseUint(num, 10, 64); err == nil {
+		if v > math.MaxInt64/uint64(unitSize) {
+			return fmt.Errorf("byte size %q out of range", input)
+		}
+		*s = ByteSize(v) * unitSize
+		return nil
+	}
+	v, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return fmt.Errorf("invalid byte size %q", input)
+	}
+	// float64(math.MaxInt64) rounds up to 2^63, which is out of range.
+	if v*float64(unitSize) >= math.MaxInt64 {
+		return fmt.Errorf("byte size %q out of range", input)
+	}
+	*s = ByteSize(v * float64(unitSize))
+	return nil
+}
+
+func (s *ByteSize) UnmarshalText(b []byte) error {
+	return s.Set(string(b))
+}
+
+func (s *ByteSize) Type() string {
+	return "byte-size"
+}
+
+func (s *ByteSize) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// String formats the size with the largest unit that represents it exactly.
+func (s *ByteSize) String() string {
+	if *s == 0 {
+		return "0B"
+	}
+	for _, u := range byteUnits {
+		if *s%u.Size == 0 {
+			return fmt.Sprintf("%d%s", *s/u.Size, u.Name)
+		}
+	}
+	return fmt.Sprintf("%dB", uint64(*s))
+}
@@ -0,0 +1,95 @@
+// Copyright 2020 oncilla
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flag
+
+import (
+	"encoding"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+var _ pflag.Value = (*Time)(nil)
+var _ encoding.TextUnmarshaler = (*Time)(nil)
+var _ encoding.TextMarshaler = (*Time)(nil)
+
+// Time implements pflags.Value. It is formatted according to RFC 3339.
+type Time time.Time
+
+func (t *Time) Set(input string) error {
+	p, err := time.Parse(time.RFC3339, input)
+	if err != nil {
+		return err
+	}
+	*t = Time(p)
+	return nil
+}
+
+func (t *Time) UnmarshalText(b []byte) error {
+	return t.Set(string(b))
+}
+
+func (t *Time) Type() string {
+	return "time"
+}
+
+func (t *Time) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+func (t *Time) String() string {
+	if time.Time(*t).IsZero() {
+		return ""
+	}
+	return time.Time(*t).Format(time.RFC3339)
+}
+
+var _ pflag.Value = (*Location)(nil)
+var _ encoding.TextUnmarshaler = (*Location)(nil)
+var _ encoding.TextMarshaler = (*Location)(nil)
+
+// Location implements pflags.Value. It accepts the names of the IANA time
+// zone database, e.g., Europe/Zurich, as well as UTC and Local. The zero value
+// holds no location.
+type Location struct {
+	*time.Location
+}
+
+func (l *Location) Set(input string) error {
+	p, err := time.LoadLocation(input)
+	if err != nil {
+		return err
+	}
+	l.Location = p
+	return nil
+}
+
+func (l *Location) UnmarshalText(b []byte) error {
+	return l.Set(string(b))
+}
+
+func (l *Location) Type() string {
+	return "location"
+}
+
+func (l *Location) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+func (l *Location) String() string {
+	if l.Location == nil {
+		return ""
+	}
+	return l.Location.String()
+}
@@ -14,6 +14,8 @@
 package boa
 
 import (
+	"encoding"
+	"fmt"
 	"net"
 	"reflect"
 
@@ -29,6 +31,42 @@ func DefaultDecodeHooks() []mapstructure.DecodeHookFunc {
 		mapstructure.StringToSliceHookFunc(","),
 		StringToTCPAddrHookFunc(),
 		StringToUDPAddrHookFunc(),
+		TextUnmarshalerHookFunc(),
+	}
+}
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// TextUnmarshalerHookFunc returns a DecodeHookFunc that converts strings,
+// booleans and numbers to types that implement encoding.TextUnmarshaler, such
+// as the optional values in package flag. Booleans and numbers are formatted
+// as text first, which allows configuration files to contain unquoted values.
+// Values that already have the target type, e.g., the defaults set with
+// SetDefaults, are returned unchanged.
+func TextUnmarshalerHookFunc() mapstructure.DecodeHookFunc {
+	return func(
+		f reflect.Type,
+		t reflect.Type,
+		data interface{}) (interface{}, error) {
+		if f == t {
+			return data, nil
+		}
+		switch f.Kind() {
+		case reflect.String, reflect.Bool, reflect.Float32, reflect.Float64,
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		default:
+			return data, nil
+		}
+		if !reflect.PtrTo(t).Implements(textUnmarshalerType) {
+			return data, nil
+		}
+		v := reflect.New(t)
+		if err := v.Interface().(encoding.TextUnmarshaler).UnmarshalText(
+			[]byte(fmt.Sprint(data))); err != nil {
+			return nil, err
+		}
+		return v.Elem().Interface(), nil
 	}
 }
 
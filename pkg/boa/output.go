@@ -0,0 +1,50 @@
+// Copyright 2020 oncilla
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package boa
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Output formats supported by Print.
+const (
+	// OutputText formats the value with the %+v verb of package fmt.
+	OutputText = "text"
+	// OutputJSON formats the value as indented JSON.
+	OutputJSON = "json"
+)
+
+// OutputFormats are the output formats supported by Print. The first one is
+// the default.
+var OutputFormats = []string{OutputText, OutputJSON}
+
+// Print writes the value to the writer in the output format, followed by a
+// newline. It is used by commands to print the result of the business logic
+// in the format selected by the user, e.g., with an --output flag.
+func Print(w io.Writer, format string, v interface{}) error {
+	switch format {
+	case OutputText:
+		_, err := fmt.Fprintf(w, "%+v\n", v)
+		return err
+	case OutputJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+	return fmt.Errorf("unsupported output format %q, supported: %s",
+		format, strings.Join(OutputFormats, ", "))
+}
@@ -0,0 +1,45 @@
+// Copyright 2020 oncilla
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package boa_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oncilla/boa/pkg/boa"
+)
+
+func TestPrint(t *testing.T) {
+	v := struct {
+		Name  string `json:"name"`
+		Count int    `json:"count"`
+	}{Name: "boa", Count: 2}
+
+	testCases := map[string]string{
+		boa.OutputText: "{Name:boa Count:2}\n",
+		boa.OutputJSON: "{\n  \"name\": \"boa\",\n  \"count\": 2\n}\n",
+	}
+	for format, expected := range testCases {
+		t.Run(format, func(t *testing.T) {
+			var out bytes.Buffer
+			require.NoError(t, boa.Print(&out, format, v))
+			assert.Equal(t, expected, out.String())
+		})
+	}
+	err := boa.Print(&bytes.Buffer{}, "xml", v)
+	assert.EqualError(t, err, `unsupported output format "xml", supported: text, json`)
+}
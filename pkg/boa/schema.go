@@ -0,0 +1,419 @@
+// Copyright 2020 oncilla
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package boa
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Field describes a single configuration value of a config struct.
+type Field struct {
+	// Name is the path of the Go field, e.g., DB.User.
+	Name string
+	// Key is the path of the configuration key, e.g., db.user.
+	Key string
+	// Type is the declared type of the Go field.
+	Type reflect.Type
+	// Tag is the struct tag of the Go field.
+	Tag reflect.StructTag
+	// Default is the value of the field in the compiled config struct.
+	Default interface{}
+}
+
+// Env returns the environment variable name for the field without prefix.
+// It is derived from the key path by replacing '.' and '-' with '_' and
+// converting it to upper case, the same way as the generated viper setup with
+// strings.NewReplacer(".", "_", "-", "_").
+func (f *Field) Env() string {
+	return envName(f.Key)
+}
+
+func envName(key string) string {
+	var b strings.Builder
+	writeEnv(&b, key)
+	return b.String()
+}
+
+func writeEnv(b *strings.Builder, key string) {
+	b.Grow(len(key))
+	for i := 0; i < len(key); i++ {
+		switch c := key[i]; {
+		case c == '.' || c == '-':
+			b.WriteByte('_')
+		case 'a' <= c && c <= 'z':
+			b.WriteByte(c - 'a' + 'A')
+		default:
+			b.WriteByte(c)
+		}
+	}
+}
+
+// Schema is the precompiled description of a config struct. It is built once
+// with reflection and keeps the field order, tags, types and default values.
+// A schema can be reused to set defaults, bind environment variables and add
+// flags without walking the config struct again.
+type Schema struct {
+	// Fields contains all configuration values in declaration order. Nested
+	// structs are flattened, such that only leaf values are contained.
+	Fields []*Field
+
+	issues Issues
+}
+
+// Compile builds the schema for the provided config struct. The config
+// follows the mapstructure conventions: The key is taken from the mapstructure
+// tag, or the field name if the tag is missing. Fields tagged with '-' and
+// unexported fields are ignored. Embedded structs tagged with ',squash' are
+// flattened into the parent. Nested structs that do not implement
+// pflag.Value are treated as a group of values.
+func Compile(config interface{}) (*Schema, error) {
+	v := reflect.ValueOf(config)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil, fmt.Errorf("config must not be nil")
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return nil, fmt.Errorf("config must not be nil")
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("config must be a struct, got %s", v.Type())
+	}
+	c := newCompiler(v.Type())
+	c.compileStruct(v, "", "")
+	return c.schema, nil
+}
+
+// Field returns the field with the given key path. If no field exists, nil is
+// returned.
+func (s *Schema) Field(key string) *Field {
+	for _, f := range s.Fields {
+		if f.Key == key {
+			return f
+		}
+	}
+	return nil
+}
+
+// Check reports all problems found while compiling the schema. If problems
+// are found, the returned error is of type Issues.
+func (s *Schema) Check() error {
+	if len(s.issues) == 0 {
+		return nil
+	}
+	return s.issues
+}
+
+// Validate checks the decoded config against the compiled defaults. Values
+// that list their allowed values with a 'Values() []string' method, e.g.,
+// flag.Enum, must hold one of the values allowed by the default. The decoding
+// hooks only know the type of the target, thus a decoded flag.Enum does not
+// carry the allowed values and accepts anything. If problems are found, the
+// returned error is of type Issues.
+func (s *Schema) Validate(config interface{}) error {
+	decoded, err := Compile(config)
+	if err != nil {
+		return err
+	}
+	if len(decoded.Fields) != len(s.Fields) {
+		return fmt.Errorf("config does not match the schema")
+	}
+	var issues Issues
+	for i, f := range s.Fields {
+		d := decoded.Fields[i]
+		if d.Name != f.Name || d.Type != f.Type {
+			return fmt.Errorf("config does not match the schema at %s", d.Name)
+		}
+		allowed := allowedValues(f.Default)
+		if len(allowed) == 0 {
+			continue
+		}
+		value := fmt.Sprint(addressable(d.Default))
+		if !contains(allowed, value) {
+			issues = append(issues, Issue{
+				Field: f.Name,
+				Key:   f.Key,
+				Problem: fmt.Sprintf("invalid value %q, allowed: %s", value,
+					strings.Join(allowed, ", ")),
+			})
+		}
+	}
+	if len(issues) == 0 {
+		return nil
+	}
+	return issues
+}
+
+// allowedValues returns the allowed values of v, if it implements
+// 'Values() []string' on the value or pointer receiver.
+func allowedValues(v interface{}) []string {
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && rv.IsNil() {
+		return nil
+	}
+	if l, ok := addressable(v).(interface{ Values() []string }); ok {
+		return l.Values()
+	}
+	return nil
+}
+
+// addressable returns a pointer to a copy of v, such that methods with
+// pointer receivers are in the method set. Pointers are returned as is.
+func addressable(v interface{}) interface{} {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() || rv.Kind() == reflect.Ptr {
+		return v
+	}
+	p := reflect.New(rv.Type())
+	p.Elem().Set(rv)
+	return p.Interface()
+}
+
+func contains(values []string, v string) bool {
+	for _, a := range values {
+		if a == v {
+			return true
+		}
+	}
+	return false
+}
+
+// SetDefaults sets the default values based on the compiled config struct.
+func (s *Schema) SetDefaults(r ConfigRegistry) {
+	for _, f := range s.Fields {
+		r.SetDefault(f.Key, f.Default)
+	}
+}
+
+// BindEnv binds the environment variables for all fields.
+func (s *Schema) BindEnv(r ConfigRegistry) error {
+	for _, f := range s.Fields {
+		if err := r.BindEnv(f.Key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type compiler struct {
+	schema *Schema
+	// fields is preallocated storage for the schema fields.
+	fields []Field
+	// groups maps the lower case key path of nested structs to the Go field
+	// path.
+	groups map[string]string
+	// envs maps the environment variable name to the field. Keys that only
+	// differ in case share the environment variable, thus it is also used to
+	// detect duplicate keys.
+	envs map[string]*Field
+}
+
+func newCompiler(t reflect.Type) *compiler {
+	n := countFields(t, map[reflect.Type]int{})
+	return &compiler{
+		schema: &Schema{Fields: make([]*Field, 0, n)},
+		fields: make([]Field, 0, n),
+		groups: map[string]string{},
+		envs:   make(map[string]*Field, n),
+	}
+}
+
+// countFields returns an upper bound for the number of values in the struct
+// type. It is used to size the compiler storage upfront. The counts of nested
+// struct types are memoized in seen, since configs often reuse them.
+func countFields(t reflect.Type, seen map[reflect.Type]int) int {
+	if n, ok := seen[t]; ok {
+		return n
+	}
+	n := 0
+	for i := 0; i < t.NumField(); i++ {
+		if ft := t.Field(i).Type; isGroup(ft) {
+			n += countFields(ft, seen)
+			continue
+		}
+		n++
+	}
+	seen[t] = n
+	return n
+}
+
+func (c *compiler) report(field, key string, format string, a ...interface{}) {
+	c.schema.issues = append(c.schema.issues, Issue{
+		Field:   field,
+		Key:     key,
+		Problem: fmt.Sprintf(format, a...),
+	})
+}
+
+func (c *compiler) compileStruct(v reflect.Value, field, key string) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			c.report(join(field, f.Name), key, "unexported field is ignored")
+			continue
+		}
+
+		tag, ok := f.Tag.Lookup("mapstructure")
+		name, squash := parseTag(tag)
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		var fieldPath, keyPath, env string
+		if squash {
+			fieldPath, keyPath = join(field, f.Name), key
+		} else {
+			fieldPath, keyPath, env = joinPaths(field, f.Name, key, name)
+		}
+		if !ok {
+			c.report(fieldPath, keyPath, "missing mapstructure tag, key defaults to %q", name)
+		}
+
+		fv := v.Field(i)
+		if squash {
+			if fv.Kind() != reflect.Struct {
+				c.report(fieldPath, keyPath, "cannot squash non-struct type %s", fv.Type())
+				continue
+			}
+			c.compileStruct(fv, fieldPath, keyPath)
+			continue
+		}
+		if isGroup(fv.Type()) {
+			if c.registerGroup(fieldPath, keyPath, env) {
+				c.compileStruct(fv, fieldPath, keyPath)
+			}
+			continue
+		}
+		c.compileLeaf(fv, f, fieldPath, keyPath, env)
+	}
+}
+
+// parseTag splits the mapstructure tag into the name and the squash option.
+func parseTag(tag string) (string, bool) {
+	i := strings.IndexByte(tag, ',')
+	if i < 0 {
+		return tag, false
+	}
+	for _, opt := range strings.Split(tag[i+1:], ",") {
+		if opt == "squash" {
+			return tag[:i], true
+		}
+	}
+	return tag[:i], false
+}
+
+// join appends the name to the dot separated path.
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// joinPaths appends the names to the Go field and key path, and derives the
+// environment variable name from the key path. The results share a single
+// allocation, since building the names dominates the cost of compiling large
+// config structs.
+func joinPaths(field, fieldName, key, keyName string) (string, string, string) {
+	var b strings.Builder
+	b.Grow(len(field) + len(fieldName) + 2*(len(key)+len(keyName)+1) + 1)
+	writePath(&b, field, fieldName)
+	n := b.Len()
+	writePath(&b, key, keyName)
+	m := b.Len()
+	writeEnv(&b, b.String()[n:])
+	s := b.String()
+	return s[:n], s[n:m], s[m:]
+}
+
+func writePath(b *strings.Builder, path, name string) {
+	if path != "" {
+		b.WriteString(path)
+		b.WriteByte('.')
+	}
+	b.WriteString(name)
+}
+
+// registerGroup registers the key of a nested struct and reports collisions.
+// It returns false, if the key collides with an already registered key.
+func (c *compiler) registerGroup(field, key, env string) bool {
+	if c.duplicateGroup(field, key) {
+		return false
+	}
+	if other, ok := c.envs[env]; ok && strings.EqualFold(other.Key, key) {
+		c.report(field, key, "duplicate key, already defined by %s", other.Name)
+		return false
+	}
+	c.groups[strings.ToLower(key)] = field
+	return true
+}
+
+// duplicateGroup reports whether the key is already registered by a nested
+// struct, ignoring case.
+func (c *compiler) duplicateGroup(field, key string) bool {
+	other, ok := c.groups[strings.ToLower(key)]
+	if ok {
+		c.report(field, key, "duplicate key, already defined by %s", other)
+	}
+	return ok
+}
+
+func (c *compiler) compileLeaf(v reflect.Value, sf reflect.StructField, field, key, env string) {
+	other, collides := c.envs[env]
+	if collides && strings.EqualFold(other.Key, key) {
+		c.report(field, key, "duplicate key, already defined by %s", other.Name)
+		return
+	}
+	if c.duplicateGroup(field, key) {
+		return
+	}
+	c.fields = append(c.fields, Field{
+		Name:    field,
+		Key:     key,
+		Type:    sf.Type,
+		Tag:     sf.Tag,
+		Default: v.Interface(),
+	})
+	f := &c.fields[len(c.fields)-1]
+	c.schema.Fields = append(c.schema.Fields, f)
+	if !collides {
+		c.envs[env] = f
+	}
+
+	if v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			c.report(field, key, "nil interface value, type cannot be determined")
+			return
+		}
+		v = v.Elem()
+	}
+	if !supportedFlag(v.Type()) {
+		c.report(field, key, "unsupported type %s", v.Type())
+		return
+	}
+	if collides {
+		c.report(field, key, "environment variable %s collides with %s", env, other.Name)
+	}
+}
+
+// isGroup indicates whether the type is a nested struct whose fields are
+// configuration values on their own.
+func isGroup(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && !reflect.PtrTo(t).Implements(pflagValueType)
+}
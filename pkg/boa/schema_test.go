@@ -0,0 +1,202 @@
+// Copyright 2020 oncilla
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package boa_test
+
+import (
+	"fmt"
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oncilla/boa/pkg/boa"
+	"github.com/oncilla/boa/pkg/boa/flag"
+)
+
+func TestCompile(t *testing.T) {
+	var config Config
+	config.DB.User = "oncilla"
+
+	s, err := boa.Compile(&config)
+	require.NoError(t, err)
+
+	var keys []string
+	for _, f := range s.Fields {
+		keys = append(keys, f.Key)
+	}
+	assert.Equal(t, []string{"db.user", "db.password", "addr", "token"}, keys)
+
+	user := s.Field("db.user")
+	require.NotNil(t, user)
+	assert.Equal(t, "DB.User", user.Name)
+	assert.Equal(t, "DB_USER", user.Env())
+	assert.Equal(t, reflect.TypeOf(""), user.Type)
+	assert.Equal(t, `mapstructure:"user"`, string(user.Tag))
+	assert.Equal(t, "oncilla", user.Default)
+
+	token := s.Field("token")
+	require.NotNil(t, token)
+	assert.Equal(t, "Token.Token", token.Name)
+
+	assert.Nil(t, s.Field("db"))
+	assert.NoError(t, s.Check())
+	_, err = boa.Compile(nil)
+	assert.Error(t, err)
+}
+
+func TestSchemaAddFlags(t *testing.T) {
+	var config struct {
+		Nil   *flag.TCPAddr `mapstructure:"nil"`
+		Value flag.TCPAddr  `mapstructure:"value"`
+		Ptr   *flag.TCPAddr `mapstructure:"ptr"`
+	}
+	config.Value = flag.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 80}
+	config.Ptr = &flag.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 443}
+
+	s, err := boa.Compile(&config)
+	require.NoError(t, err)
+
+	// The schema can be used for multiple flag sets.
+	for i := 0; i < 2; i++ {
+		fs := pflag.NewFlagSet("", pflag.ContinueOnError)
+		require.NoError(t, s.AddFlags(fs))
+		assert.Equal(t, ":0", fs.Lookup("nil").Value.String())
+		assert.Equal(t, "127.0.0.1:80", fs.Lookup("value").Value.String())
+		assert.Equal(t, "127.0.0.1:443", fs.Lookup("ptr").Value.String())
+		require.NoError(t, fs.Parse([]string{"--ptr", "127.0.0.1:8443", "--nil", "127.0.0.1:1"}))
+		assert.Equal(t, "127.0.0.1:8443", fs.Lookup("ptr").Value.String())
+	}
+	// Parsing flags does not modify the defaults.
+	assert.Equal(t, 443, config.Ptr.Port)
+	assert.Nil(t, config.Nil)
+}
+
+func TestSchemaAddFlagsUnsupported(t *testing.T) {
+	var config struct {
+		Values []float64 `mapstructure:"values"`
+	}
+	fs := pflag.NewFlagSet("", pflag.ContinueOnError)
+	err := boa.AddFlags(fs, &config)
+	assert.Error(t, err)
+}
+
+type registry map[string]interface{}
+
+func (r registry) BindEnv(input ...string) error        { return nil }
+func (r registry) SetDefault(key string, v interface{}) { r[key] = v }
+
+// largeConfig creates a config struct with the given number of groups that
+// each contain the given number of fields.
+func largeConfig(groups, fields int) interface{} {
+	var leafs []reflect.StructField
+	for i := 0; i < fields; i++ {
+		typ := reflect.TypeOf("")
+		if i%2 == 0 {
+			typ = reflect.TypeOf(0)
+		}
+		leafs = append(leafs, reflect.StructField{
+			Name: fmt.Sprintf("Field%d", i),
+			Type: typ,
+			Tag:  reflect.StructTag(fmt.Sprintf(`mapstructure:"field%d"`, i)),
+		})
+	}
+	group := reflect.StructOf(leafs)
+	var nested []reflect.StructField
+	for i := 0; i < groups; i++ {
+		nested = append(nested, reflect.StructField{
+			Name: fmt.Sprintf("Group%d", i),
+			Type: group,
+			Tag:  reflect.StructTag(fmt.Sprintf(`mapstructure:"group%d"`, i)),
+		})
+	}
+	return reflect.New(reflect.StructOf(nested)).Interface()
+}
+
+// setDefaultsDecode sets the defaults by decoding the config into a map and
+// walking it. This is the approach used before the schema was introduced.
+func setDefaultsDecode(r boa.ConfigRegistry, config interface{}) error {
+	m := map[string]interface{}{}
+	if err := mapstructure.Decode(config, &m); err != nil {
+		return err
+	}
+	var walk func(prefix string, m map[string]interface{})
+	walk = func(prefix string, m map[string]interface{}) {
+		for key, value := range m {
+			if nested, ok := value.(map[string]interface{}); ok {
+				walk(prefix+key+".", nested)
+				continue
+			}
+			r.SetDefault(prefix+key, value)
+		}
+	}
+	walk("", m)
+	return nil
+}
+
+func BenchmarkSetDefaults(b *testing.B) {
+	config := largeConfig(50, 20)
+	b.Run("decode", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if err := setDefaultsDecode(registry{}, config); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("compile", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if err := boa.SetDefaults(registry{}, config); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("precompiled", func(b *testing.B) {
+		s, err := boa.Compile(config)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			s.SetDefaults(registry{})
+		}
+	})
+}
+
+func BenchmarkAddFlags(b *testing.B) {
+	config := largeConfig(50, 20)
+	b.Run("compile", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			fs := pflag.NewFlagSet("", pflag.ContinueOnError)
+			if err := boa.AddFlags(fs, config); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("precompiled", func(b *testing.B) {
+		s, err := boa.Compile(config)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			fs := pflag.NewFlagSet("", pflag.ContinueOnError)
+			if err := s.AddFlags(fs); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
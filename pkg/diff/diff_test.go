@@ -0,0 +1,115 @@
+// Copyright 2020 oncilla
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diff_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/oncilla/boa/pkg/diff"
+)
+
+func lines(n int, replace map[int]string) string {
+	var b strings.Builder
+	for i := 1; i <= n; i++ {
+		if l, ok := replace[i]; ok {
+			if l != "" {
+				b.WriteString(l + "\n")
+			}
+			continue
+		}
+		b.WriteString(string(rune('a'+i-1)) + "\n")
+	}
+	return b.String()
+}
+
+func TestUnified(t *testing.T) {
+	testCases := map[string]struct {
+		Old      string
+		New      string
+		Expected string
+	}{
+		"equal": {
+			Old:      lines(5, nil),
+			New:      lines(5, nil),
+			Expected: "",
+		},
+		"change": {
+			Old: lines(10, nil),
+			New: lines(10, map[int]string{5: "E"}),
+			Expected: `--- a
++++ b
+@@ -2,7 +2,7 @@
+ b
+ c
+ d
+-e
++E
+ f
+ g
+ h
+`,
+		},
+		"separate hunks": {
+			Old: lines(20, nil),
+			New: lines(20, map[int]string{2: "", 18: "R\nS"}),
+			Expected: `--- a
++++ b
+@@ -1,5 +1,4 @@
+ a
+-b
+ c
+ d
+ e
+@@ -15,6 +14,7 @@
+ o
+ p
+ q
+-r
++R
++S
+ s
+ t
+`,
+		},
+		"created": {
+			Old: "",
+			New: "a\nb\n",
+			Expected: `--- a
++++ b
+@@ -0,0 +1,2 @@
++a
++b
+`,
+		},
+		"removed": {
+			Old: "a\n",
+			New: "",
+			Expected: `--- a
++++ b
+@@ -1 +0,0 @@
+-a
+`,
+		},
+	}
+	for name, tc := range testCases {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			out := diff.Unified("a", "b", []byte(tc.Old), []byte(tc.New))
+			assert.Equal(t, tc.Expected, out)
+		})
+	}
+}
@@ -0,0 +1,198 @@
+// Copyright 2020 oncilla
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gen
+
+import (
+	"fmt"
+	"go/token"
+	"sort"
+	"strings"
+)
+
+// Arg holds the description of a positional argument.
+type Arg struct {
+	Name string
+	// Type is the Go type of a single value.
+	Type string
+	// Index is the position of the argument.
+	Index int
+	// Optional indicates that the argument can be omitted.
+	Optional bool
+	// Variadic indicates that the argument takes all remaining values.
+	Variadic bool
+	// Path indicates that the argument is a path. Paths are completed with
+	// file names.
+	Path bool
+	// Parse is the format of the Go expression that parses the raw value,
+	// e.g., 'strconv.Atoi(%s)'. It is empty for strings.
+	Parse  string
+	Import string
+}
+
+// Placeholder returns the placeholder of the argument in the usage line,
+// e.g., <src>, [dst] or [extra...].
+func (a Arg) Placeholder() string {
+	switch {
+	case a.Variadic:
+		return "[" + a.Name + "...]"
+	case a.Optional:
+		return "[" + a.Name + "]"
+	}
+	return "<" + a.Name + ">"
+}
+
+// FieldType returns the type of the field the argument is parsed into.
+func (a Arg) FieldType() string {
+	if a.Variadic {
+		return "[]" + a.Type
+	}
+	return a.Type
+}
+
+// ParseExpr returns the Go expression that parses the raw value. It returns
+// the value and an error.
+func (a Arg) ParseExpr(raw string) string {
+	return fmt.Sprintf(a.Parse, raw)
+}
+
+// Sample returns a valid command line value for the argument. It is used by
+// the generated tests.
+func (a Arg) Sample() string {
+	if a.Path {
+		return "."
+	}
+	return sample(a.Type)
+}
+
+var supportedArg = map[string]struct {
+	Type   string
+	Parse  string
+	Import string
+}{
+	"string":   {Type: "string"},
+	"path":     {Type: "string"},
+	"bool":     {Type: "bool", Parse: "strconv.ParseBool(%s)", Import: "strconv"},
+	"int":      {Type: "int", Parse: "strconv.Atoi(%s)", Import: "strconv"},
+	"int64":    {Type: "int64", Parse: "strconv.ParseInt(%s, 0, 64)", Import: "strconv"},
+	"uint64":   {Type: "uint64", Parse: "strconv.ParseUint(%s, 0, 64)", Import: "strconv"},
+	"float64":  {Type: "float64", Parse: "strconv.ParseFloat(%s, 64)", Import: "strconv"},
+	"duration": {Type: "time.Duration", Parse: "time.ParseDuration(%s)", Import: "time"},
+}
+
+// ParseArgs parses the positional arguments. Each input is a comma separated
+// list of arguments in the notation:
+//
+//	name:[...]type[?]
+//
+// For example, 'src:path,dst:path?,extra:...string' describes the required
+// argument src, the optional argument dst, and the variadic argument extra
+// that takes all remaining values. Optional arguments follow the required
+// ones, the variadic argument is last. The supported types are string, path,
+// bool, int, int64, uint64, float64 and duration. The sorted imports required
+// for parsing are returned alongside.
+func ParseArgs(inputs []string) ([]Arg, []string, error) {
+	var args []Arg
+	imports := map[string]struct{}{}
+	names := map[string]bool{}
+	for _, input := range inputs {
+		s := &specScanner{input: input}
+		for {
+			start := s.pos
+			a, err := s.arg()
+			if err != nil {
+				return nil, nil, err
+			}
+			if names[a.Name] {
+				return nil, nil, s.errorf(start, "duplicate argument %s", a.Name)
+			}
+			names[a.Name] = true
+			if len(args) > 0 {
+				last := args[len(args)-1]
+				switch {
+				case last.Variadic:
+					return nil, nil, s.errorf(start, "variadic argument %s must be last", last.Name)
+				case last.Optional && !a.Optional && !a.Variadic:
+					return nil, nil, s.errorf(start,
+						"required argument %s must precede optional argument %s", a.Name, last.Name)
+				}
+			}
+			a.Index = len(args)
+			args = append(args, a)
+			if a.Import != "" {
+				imports[a.Import] = struct{}{}
+			}
+			if s.eof() {
+				break
+			}
+			// The scanner stops at the comma that separates the arguments.
+			s.pos++
+		}
+	}
+	var unique []string
+	for imp := range imports {
+		unique = append(unique, imp)
+	}
+	sort.Strings(unique)
+	return args, unique, nil
+}
+
+// arg scans a positional argument. It stops at the end of the input, or at
+// the comma that separates it from the next argument.
+func (s *specScanner) arg() (Arg, error) {
+	start := s.pos
+	name := s.ident()
+	switch {
+	case name == "":
+		return Arg{}, s.errorf(start, "expected argument name")
+	case !token.IsIdentifier(name):
+		return Arg{}, s.errorf(start, "argument name %q is not a valid identifier", name)
+	case s.peek() != ':':
+		return Arg{}, s.errorf(s.pos, "expected ':' after argument name")
+	}
+	s.pos++
+	a := Arg{Name: name}
+	if strings.HasPrefix(s.input[s.pos:], "...") {
+		a.Variadic = true
+		s.pos += len("...")
+	}
+	typeStart := s.pos
+	typ := s.ident()
+	v, ok := supportedArg[strings.ToLower(typ)]
+	if !ok {
+		return Arg{}, s.errorf(typeStart, "unsupported argument type: %s, expected one of %s",
+			typ, strings.Join(argTypes(), ", "))
+	}
+	a.Type, a.Parse, a.Import = v.Type, v.Parse, v.Import
+	a.Path = strings.ToLower(typ) == "path"
+	if s.peek() == '?' {
+		if a.Variadic {
+			return Arg{}, s.errorf(s.pos, "variadic arguments are optional already")
+		}
+		a.Optional = true
+		s.pos++
+	}
+	if !s.eof() && s.peek() != ',' {
+		return Arg{}, s.errorf(s.pos, "unexpected %q", s.peek())
+	}
+	return a, nil
+}
+
+func argTypes() []string {
+	var types []string
+	for typ := range supportedArg {
+		types = append(types, typ)
+	}
+	sort.Strings(types)
+	return types
+}
@@ -0,0 +1,145 @@
+// Copyright 2020 oncilla
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gen_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oncilla/boa/pkg/gen"
+)
+
+func TestParseArgs(t *testing.T) {
+	testCases := map[string]struct {
+		Input         string
+		Use           string
+		Example       string
+		Validator     string
+		CompletePaths string
+		Imports       []string
+	}{
+		"exact": {
+			Input:         "src:path,dst:path",
+			Use:           "copy <src> <dst>",
+			Example:       "copy . . --sample",
+			Validator:     "cobra.ExactArgs(2)",
+			CompletePaths: "len(args) == 0 || len(args) == 1",
+		},
+		"optional": {
+			Input:     "count:int,wait:duration?",
+			Use:       "copy <count> [wait]",
+			Example:   "copy 1 --sample",
+			Validator: "cobra.RangeArgs(1, 2)",
+			Imports:   []string{"strconv", "time"},
+		},
+		"only optional": {
+			Input:     "name:string?",
+			Use:       "copy [name]",
+			Example:   "copy --sample",
+			Validator: "cobra.RangeArgs(0, 1)",
+		},
+		"variadic": {
+			Input:         "src:path,dst:path,extra:...string",
+			Use:           "copy <src> <dst> [extra...]",
+			Example:       "copy . . --sample",
+			Validator:     "cobra.MinimumNArgs(2)",
+			CompletePaths: "len(args) == 0 || len(args) == 1",
+		},
+		"only variadic": {
+			Input:         "files:...path",
+			Use:           "copy [files...]",
+			Example:       "copy --sample",
+			Validator:     "cobra.ArbitraryArgs",
+			CompletePaths: "true",
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			args, imports, err := gen.ParseArgs([]string{tc.Input})
+			require.NoError(t, err)
+			c := gen.Command{Name: "copy", Args: args}
+			assert.Equal(t, tc.Use, c.Use())
+			assert.Equal(t, tc.Example, c.Example())
+			assert.Equal(t, tc.Validator, c.ArgsValidator())
+			assert.Equal(t, tc.CompletePaths, c.CompletePaths())
+			assert.Equal(t, tc.Imports, imports)
+		})
+	}
+}
+
+func TestParseArgsErrors(t *testing.T) {
+	testCases := map[string]struct {
+		Input  string
+		Offset int
+		Msg    string
+	}{
+		"missing type":     {Input: "src", Offset: 3, Msg: "expected ':' after argument name"},
+		"unsupported type": {Input: "src:file", Offset: 4, Msg: "unsupported argument type: file"},
+		"duplicate":        {Input: "src:path,src:path", Offset: 9, Msg: "duplicate argument src"},
+		"variadic last":    {Input: "a:...string,b:string", Offset: 12, Msg: "variadic argument a must be last"},
+		"required order":   {Input: "a:string?,b:string", Offset: 10, Msg: "must precede optional argument a"},
+		"optional variadic": {
+			Input: "a:...string?", Offset: 11, Msg: "variadic arguments are optional already",
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, _, err := gen.ParseArgs([]string{tc.Input})
+			require.Error(t, err)
+			specErr, ok := err.(*gen.SpecError)
+			require.True(t, ok, "unexpected error type %T", err)
+			assert.Equal(t, tc.Offset, specErr.Offset)
+			assert.Contains(t, specErr.Msg, tc.Msg)
+		})
+	}
+}
+
+func TestCommandExample(t *testing.T) {
+	testCases := map[string]struct {
+		Args    string
+		Flags   string
+		Example string
+	}{
+		"no args": {
+			Flags:   "count:int=1",
+			Example: "greet arg --count=1",
+		},
+		"required flags": {
+			Args:    "name:string",
+			Flags:   "verbose:bool,port:uint16[1..]=8080!,mode:enum(fast|safe)!",
+			Example: "greet value --port=1 --mode=safe",
+		},
+		"quoted": {
+			Args:    "names:...string",
+			Flags:   "v:string(regexp=`^v 1$`)!",
+			Example: "greet --v='v 1'",
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			c := gen.Command{Name: "greet"}
+			if tc.Args != "" {
+				args, _, err := gen.ParseArgs([]string{tc.Args})
+				require.NoError(t, err)
+				c.Args = args
+			}
+			flags, _, err := gen.ParseFlags([]string{tc.Flags}, nil)
+			require.NoError(t, err)
+			c.Flags = flags
+			assert.Equal(t, tc.Example, c.Example())
+		})
+	}
+}
@@ -0,0 +1,249 @@
+// Copyright 2020 oncilla
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"io/ioutil"
+	"strings"
+
+	"github.com/oncilla/boa/pkg/source"
+	"gopkg.in/yaml.v2"
+)
+
+// CLI is the declarative description of the commands of an application, as
+// read from the spec file of 'boa generate'.
+type CLI struct {
+	// Commands are the subcommands of the root command.
+	Commands []CommandSpec `yaml:"commands"`
+}
+
+// CommandSpec describes a command and its subcommands.
+type CommandSpec struct {
+	Name    string     `yaml:"name"`
+	Short   string     `yaml:"short,omitempty"`
+	Long    string     `yaml:"long,omitempty"`
+	Aliases []string   `yaml:"aliases,omitempty"`
+	Flags   []FlagSpec `yaml:"flags,omitempty"`
+	// Args are the positional arguments in the notation of ParseArgs, e.g.,
+	// 'src:path' or 'extra:...string'.
+	Args     []string      `yaml:"args,omitempty"`
+	Commands []CommandSpec `yaml:"commands,omitempty"`
+}
+
+// FlagSpec describes a flag. The attributes correspond to the ones of the long
+// notation, see ParseFlagSpec.
+type FlagSpec struct {
+	Name string `yaml:"name"`
+	// Type is the flag type including its constraint, e.g., 'uint16[1..]',
+	// 'path(dir)' or 'enum(fast|safe)'.
+	Type string `yaml:"type"`
+	// Default is the unquoted default value. If empty, the zero value, or the
+	// first value of enums, is the default.
+	Default  string `yaml:"default,omitempty"`
+	Short    string `yaml:"short,omitempty"`
+	Required bool   `yaml:"required,omitempty"`
+	Usage    string `yaml:"usage,omitempty"`
+}
+
+// LoadCLI loads the spec file. Unknown keys are rejected.
+func LoadCLI(file string) (*CLI, error) {
+	raw, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var cli CLI
+	if err := yaml.UnmarshalStrict(raw, &cli); err != nil {
+		return nil, fmt.Errorf("parsing spec %s: %s", file, err)
+	}
+	return &cli, nil
+}
+
+// Flatten returns the commands described by the spec, with every command
+// preceding its subcommands. The name, the parents, the descriptions, the
+// aliases, the flags, the arguments and the imports are set. All other fields
+// are left to the caller.
+func (c *CLI) Flatten() ([]Command, error) {
+	return specCommands(nil, c.Commands)
+}
+
+func specCommands(parents []string, specs []CommandSpec) ([]Command, error) {
+	var cmds []Command
+	names := map[string]bool{}
+	for _, spec := range specs {
+		path := append(append([]string(nil), parents...), spec.Name)
+		if names[spec.Name] {
+			return nil, fmt.Errorf("duplicate command %s", strings.Join(path, " "))
+		}
+		names[spec.Name] = true
+		cmd, err := spec.command(parents)
+		if err != nil {
+			return nil, fmt.Errorf("command %s: %s", strings.Join(path, " "), err)
+		}
+		subcommands, err := specCommands(path, spec.Commands)
+		if err != nil {
+			return nil, err
+		}
+		cmds = append(append(cmds, cmd), subcommands...)
+	}
+	return cmds, nil
+}
+
+// command converts the spec to the command with the given parents.
+func (s CommandSpec) command(parents []string) (Command, error) {
+	if err := source.CheckName(s.Name); err != nil {
+		return Command{}, err
+	}
+	c := Command{
+		Name:    s.Name,
+		Parents: parents,
+		Short:   s.Short,
+		Long:    strings.TrimRight(s.Long, "\n"),
+		Aliases: s.Aliases,
+
+		HasSubcommands: len(s.Commands) > 0,
+	}
+	for _, spec := range s.Flags {
+		f, err := spec.flag()
+		if err != nil {
+			return Command{}, err
+		}
+		c.Flags = append(c.Flags, f)
+	}
+	flagImports, err := checkFlags(c.Flags)
+	if err != nil {
+		return Command{}, err
+	}
+	var argImports []string
+	if c.Args, argImports, err = ParseArgs(s.Args); err != nil {
+		return Command{}, err
+	}
+	c.Imports = append(flagImports, argImports...)
+	return c, nil
+}
+
+// flag converts the spec to the flag.
+func (s FlagSpec) flag() (Flag, error) {
+	f, err := newFlag(s.Name, s.Type, s.Default)
+	if err != nil {
+		return Flag{}, err
+	}
+	switch {
+	case s.Short == "":
+	case len(s.Short) != 1 || !isAlnum(s.Short[0]):
+		return Flag{}, fmt.Errorf("flag %s: shorthand must be a single letter or digit, got %q",
+			s.Name, s.Short)
+	case s.Short == "h":
+		return Flag{}, fmt.Errorf("flag %s: shorthand h is reserved for the help flag", s.Name)
+	}
+	f.Short = s.Short
+	f.Required = s.Required
+	if s.Usage != "" {
+		f.Usage = s.Usage
+	}
+	if s.Default == "" {
+		if err := f.checkZero(); err != nil {
+			return Flag{}, err
+		}
+	}
+	return f, nil
+}
+
+// Drift returns the differences between the command and the command found in
+// the source. The usage line, the aliases and the flags are compared. The
+// short description is only compared if it is set. Required flags and the
+// long description cannot be determined from the source, and are not
+// compared. Commands without flags are generated with the placeholder flag
+// 'sample', which is not reported.
+func (c Command) Drift(src *source.Command) []string {
+	var drift []string
+	if c.Use() != src.Use {
+		drift = append(drift, fmt.Sprintf("usage line is %q in the spec, but %q in the source",
+			c.Use(), src.Use))
+	}
+	if c.Short != "" && c.Short != src.Short {
+		drift = append(drift, fmt.Sprintf("short description is %q in the spec, but %q in the source",
+			c.Short, src.Short))
+	}
+	if fmt.Sprintf("%q", c.Aliases) != fmt.Sprintf("%q", src.Aliases) {
+		drift = append(drift, fmt.Sprintf("aliases are %q in the spec, but %q in the source",
+			c.Aliases, src.Aliases))
+	}
+	srcFlags := map[string]source.Flag{}
+	for _, f := range src.Flags {
+		srcFlags[f.Name] = f
+	}
+	for _, f := range c.Flags {
+		s, ok := srcFlags[f.Name]
+		if !ok {
+			drift = append(drift, fmt.Sprintf("flag --%s is missing in the source", f.Name))
+			continue
+		}
+		delete(srcFlags, f.Name)
+		drift = append(drift, f.drift(s)...)
+	}
+	if len(c.Flags) == 0 {
+		delete(srcFlags, "sample")
+	}
+	for _, f := range src.Flags {
+		if _, ok := srcFlags[f.Name]; ok {
+			drift = append(drift, fmt.Sprintf("flag --%s is not in the spec", f.Name))
+		}
+	}
+	return drift
+}
+
+// drift returns the differences between the flag and the flag found in the
+// source. The default is only compared for flags that are not registered with
+// a pflag.Value.
+func (f Flag) drift(src source.Flag) []string {
+	type attr struct {
+		name      string
+		spec, src string
+	}
+	attrs := []attr{
+		{"type", source.FlagType(f.Register), src.Type},
+		{"shorthand", f.Short, src.Shorthand},
+		{"usage", f.Usage, src.Usage},
+	}
+	if !f.IsVar() {
+		attrs = append(attrs, attr{"default", formatExpr(f.Default), src.Default})
+	}
+	var drift []string
+	for _, a := range attrs {
+		if a.spec != a.src {
+			drift = append(drift, fmt.Sprintf("flag --%s: %s is %q in the spec, but %q in the source",
+				f.Name, a.name, a.spec, a.src))
+		}
+	}
+	return drift
+}
+
+// formatExpr returns the Go expression in the canonical format. If it does
+// not parse, it is returned as is.
+func formatExpr(expr string) string {
+	e, err := parser.ParseExpr(expr)
+	if err != nil {
+		return expr
+	}
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, token.NewFileSet(), e); err != nil {
+		return expr
+	}
+	return buf.String()
+}
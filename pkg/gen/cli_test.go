@@ -0,0 +1,220 @@
+// Copyright 2020 oncilla
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gen_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oncilla/boa/pkg/gen"
+	"github.com/oncilla/boa/pkg/source"
+)
+
+// loadCLI writes the spec to a temporary file and loads it.
+func loadCLI(t *testing.T, spec string) (*gen.CLI, error) {
+	dir, err := ioutil.TempDir("", "cli")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "cli.yaml")
+	require.NoError(t, ioutil.WriteFile(file, []byte(spec), 0644))
+	return gen.LoadCLI(file)
+}
+
+func TestCLIFlatten(t *testing.T) {
+	cli, err := loadCLI(t, `
+commands:
+  - name: remote
+    short: Manage the remotes
+    commands:
+      - name: add
+        aliases: [new]
+        long: |
+          Add a remote.
+        args: [name:string, 'timeout:duration?']
+        flags:
+          - name: port
+            type: uint16[1..]
+            default: 22
+            short: p
+            required: true
+            usage: port of the remote
+          - name: mode
+            type: enum(fast|safe)
+  - name: ping
+`)
+	require.NoError(t, err)
+	cmds, err := cli.Flatten()
+	require.NoError(t, err)
+	require.Len(t, cmds, 3)
+
+	assert.Equal(t, "remote", cmds[0].Name)
+	assert.Empty(t, cmds[0].Parents)
+	assert.Equal(t, "Manage the remotes", cmds[0].Short)
+	assert.True(t, cmds[0].HasSubcommands)
+	assert.Equal(t, "remote", cmds[0].Use())
+	assert.Empty(t, cmds[0].RequiredArgs(""))
+
+	add := cmds[1]
+	assert.Equal(t, "newRemoteAdd", add.Constructor())
+	assert.Equal(t, []string{"new"}, add.Aliases)
+	assert.Equal(t, "Add a remote.", add.Long)
+	assert.Equal(t, "add <name> [timeout]", add.Use())
+	assert.Equal(t, []string{"github.com/oncilla/boa/pkg/boa/flag", "time"}, add.Imports)
+	require.Len(t, add.Flags, 2)
+	assert.Equal(t, "22", add.Flags[0].Default)
+	assert.Equal(t, "1..", add.Flags[0].Range)
+	assert.Equal(t, "p", add.Flags[0].Short)
+	assert.True(t, add.Flags[0].Required)
+	assert.Equal(t, "port of the remote", add.Flags[0].Usage)
+	assert.Equal(t, `flag.NewEnum("fast", "fast", "safe")`, add.Flags[1].Default)
+	assert.Equal(t, "mode description", add.Flags[1].Usage)
+
+	assert.Equal(t, "ping", cmds[2].Name)
+	assert.False(t, cmds[2].HasSubcommands)
+	assert.Equal(t, "ping <arg>", cmds[2].Use())
+}
+
+func TestCLIErrors(t *testing.T) {
+	testCases := map[string]struct {
+		Spec  string
+		Error string
+	}{
+		"unknown key": {
+			Spec:  "commands:\n  - name: ping\n    usage: ping it\n",
+			Error: "field usage not found",
+		},
+		"missing name": {
+			Spec:  "commands:\n  - short: ping it\n",
+			Error: `invalid command name ""`,
+		},
+		"invalid name": {
+			Spec:  "commands:\n  - name: remote/add\n",
+			Error: `invalid command name "remote/add"`,
+		},
+		"upper case name": {
+			Spec:  "commands:\n  - name: Bad-Name\n",
+			Error: `invalid command name "Bad-Name"`,
+		},
+		"duplicate command": {
+			Spec:  "commands:\n  - name: remote\n    commands: [{name: add}, {name: add}]\n",
+			Error: "duplicate command remote add",
+		},
+		"unsupported type": {
+			Spec:  "commands:\n  - name: ping\n    flags: [{name: count, type: integer}]\n",
+			Error: "command ping: invalid flag spec at column 7: unsupported flag type: integer",
+		},
+		"invalid default": {
+			Spec:  "commands:\n  - name: ping\n    flags: [{name: count, type: 'int[1..]', default: 0}]\n",
+			Error: "invalid default for count: 0 is not in range [1..]",
+		},
+		"invalid shorthand": {
+			Spec:  "commands:\n  - name: ping\n    flags: [{name: count, type: int, short: cc}]\n",
+			Error: `flag count: shorthand must be a single letter or digit, got "cc"`,
+		},
+		"duplicate flag": {
+			Spec:  "commands:\n  - name: ping\n    flags: [{name: count, type: int}, {name: count, type: uint}]\n",
+			Error: "duplicate flag name: count",
+		},
+		"invalid args": {
+			Spec:  "commands:\n  - name: ping\n    args: ['host:string?', 'count:int']\n",
+			Error: "required argument count must precede optional argument host",
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			cli, err := loadCLI(t, tc.Spec)
+			if err == nil {
+				_, err = cli.Flatten()
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.Error)
+		})
+	}
+}
+
+func TestCommandDrift(t *testing.T) {
+	cli, err := loadCLI(t, `
+commands:
+  - name: add
+    short: Add a remote
+    aliases: [new]
+    args: [name:string]
+    flags:
+      - {name: port, type: uint16, default: 22, short: p}
+      - {name: timeout, type: duration, default: 30s}
+      - {name: mode, type: enum(fast|safe)}
+      - {name: fetch, type: bool}
+  - name: list
+`)
+	require.NoError(t, err)
+	cmds, err := cli.Flatten()
+	require.NoError(t, err)
+
+	src := &source.Command{
+		Use:     "add <name>",
+		Short:   "Add a remote",
+		Aliases: []string{"new"},
+		Flags: []source.Flag{
+			{Name: "port", Shorthand: "p", Type: "uint16", Default: "22", Usage: "port description"},
+			{Name: "timeout", Type: "duration", Default: "30 * time.Second", Usage: "timeout description"},
+			{Name: "mode", Type: "value", Usage: "mode description"},
+			{Name: "fetch", Type: "bool", Default: "false", Usage: "fetch description"},
+		},
+	}
+	assert.Empty(t, cmds[0].Drift(src))
+
+	src.Use = "add <name> [url]"
+	src.Short = "Add a new remote"
+	src.Aliases = nil
+	src.Flags[0].Type = "int"
+	src.Flags[1].Default = "time.Minute"
+	src.Flags[2].Shorthand = "m"
+	src.Flags = append(src.Flags[:3], source.Flag{Name: "verbose", Type: "bool", Default: "false"})
+	assert.Equal(t, []string{
+		`usage line is "add <name>" in the spec, but "add <name> [url]" in the source`,
+		`short description is "Add a remote" in the spec, but "Add a new remote" in the source`,
+		`aliases are ["new"] in the spec, but [] in the source`,
+		`flag --port: type is "uint16" in the spec, but "int" in the source`,
+		`flag --timeout: default is "30 * time.Second" in the spec, but "time.Minute" in the source`,
+		`flag --mode: shorthand is "" in the spec, but "m" in the source`,
+		"flag --fetch is missing in the source",
+		"flag --verbose is not in the spec",
+	}, cmds[0].Drift(src))
+
+	// The placeholder flag of commands without flags is not reported, and the
+	// short description is only compared if it is set.
+	list := &source.Command{
+		Use:   "list <arg>",
+		Short: "list does amazing work!",
+		Flags: []source.Flag{{Name: "sample", Shorthand: "s", Type: "bool", Default: "false"}},
+	}
+	assert.Empty(t, cmds[1].Drift(list))
+}
+
+func TestParseCommandPath(t *testing.T) {
+	parents, name, err := gen.ParseCommandPath("/remote/add-all/")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"remote"}, parents)
+	assert.Equal(t, "add-all", name)
+
+	for _, path := range []string{"", "remote//add", "remote/Bad-Name", "a b"} {
+		_, _, err := gen.ParseCommandPath(path)
+		assert.Error(t, err, path)
+	}
+}
@@ -14,11 +14,12 @@
 package gen
 
 import (
-	"os"
-	"os/exec"
+	"fmt"
+	"sort"
+	"strconv"
 	"strings"
-	"text/template"
 
+	"github.com/oncilla/boa/pkg/source"
 	"github.com/oncilla/boa/pkg/tmpl"
 )
 
@@ -30,11 +31,64 @@ type Copyright struct {
 
 // Command generates new cobra commands.
 type Command struct {
-	Name      string
+	Name string
+	// Parents are the names of the parent commands, excluding the root
+	// command, e.g., [remote] for 'app remote add'.
+	Parents []string
+	// Short and Long are the descriptions of the command. If Short is empty,
+	// a placeholder is generated.
+	Short string
+	Long  string
+	// Aliases are the aliases of the command.
+	Aliases   []string
 	Copyright Copyright
 	License   License
 	Flags     []Flag
-	Imports   []string
+	// Args are the positional arguments. If empty, the command accepts any
+	// arguments.
+	Args []Arg
+	// HasSubcommands indicates that subcommands are registered with the
+	// command. Without positional arguments, cobra rejects any arguments as
+	// unknown subcommands then.
+	HasSubcommands bool
+	// Config indicates that the flags are fields of a config struct, which
+	// can also be set by environment variables and config files.
+	Config bool
+	// EnvPrefix is the prefix of the environment variables of config
+	// structs, e.g., MY_APP.
+	EnvPrefix string
+	// Call is the function that is called with the options built from the
+	// flags. If nil, the command does nothing.
+	Call    *Call
+	Imports []string
+	// Vars are custom variables available to the template.
+	Vars map[string]string
+	// Templates are the templates used for rendering. If nil, the built-in
+	// templates are used.
+	Templates tmpl.Templates
+}
+
+// Call describes the call of an existing function, e.g.,
+// 'server.Run(cmd.Context(), opts)'.
+type Call struct {
+	// Func is the qualified name of the function, e.g., server.Run.
+	Func string
+	// Args are the Go expressions of the arguments, e.g., cmd.Context(),
+	// positional.name or &opts.
+	Args []string
+	// Options is the qualified name of the options type, e.g.,
+	// server.Options. It is empty if the function takes no options.
+	Options string
+	// Result indicates that the function returns a value, which is printed
+	// in the format selected with the output flag.
+	Result bool
+	// Error indicates that the function returns an error.
+	Error bool
+}
+
+// Expr returns the Go expression of the call.
+func (c Call) Expr() string {
+	return c.Func + "(" + strings.Join(c.Args, ", ") + ")"
 }
 
 // UpperName returns the command name starting with a capital letter.
@@ -42,26 +96,383 @@ func (c Command) UpperName() string {
 	return strings.ToUpper(c.Name[:1]) + c.Name[1:]
 }
 
-// Create writes the templated command and formats it using 'gofmt'.
-func (c Command) Create(name string) error {
-	if err := notExists(name); err != nil {
-		return err
+// LongExpr returns the Go string literal of the long description. It is a
+// raw string literal, unless the description contains backticks.
+func (c Command) LongExpr() string {
+	if strings.Contains(c.Long, "`") {
+		return strconv.Quote(c.Long)
 	}
-	file, err := os.Create(name)
-	if err != nil {
-		return err
+	return "`" + c.Long + "`"
+}
+
+// Constructor returns the name of the function that creates the command.
+// It contains the names of all parents, e.g., newRemoteAdd for 'app remote
+// add'.
+func (c Command) Constructor() string {
+	return source.Constructor(c.path())
+}
+
+// ParentConstructor returns the name of the function that registers the
+// command with its parent. For direct children of the root command, this is
+// 'main'.
+func (c Command) ParentConstructor() string {
+	if len(c.Parents) == 0 {
+		return "main"
 	}
-	t := template.Must(template.New("").Parse(tmpl.Command))
-	if err := t.Execute(file, c); err != nil {
-		return err
+	return source.Constructor(c.Parents)
+}
+
+// FileName returns the name of the file that contains the command, e.g.,
+// remote_add.go for 'app remote add'.
+func (c Command) FileName() string {
+	return source.FileName(c.path())
+}
+
+// TestFunc returns the name of the test function of the command, e.g.,
+// TestRemoteAdd for 'app remote add'.
+func (c Command) TestFunc() string {
+	return source.TestFunc(c.path())
+}
+
+// TestFileName returns the name of the test file of the command, e.g.,
+// remote_add_test.go for 'app remote add'.
+func (c Command) TestFileName() string {
+	return source.TestFileName(c.path())
+}
+
+// OptionFlags returns the flags that set the fields of the options passed to
+// the called function.
+func (c Command) OptionFlags() []Flag {
+	var flags []Flag
+	for _, f := range c.Flags {
+		if f.field != "" {
+			flags = append(flags, f)
+		}
 	}
-	if err := file.Close(); err != nil {
-		return err
+	return flags
+}
+
+// ConfigType returns the name of the config struct of the command, e.g.,
+// remoteAddConfig for 'app remote add'.
+func (c Command) ConfigType() string {
+	return source.ConfigType(c.path())
+}
+
+// DefaultConfig returns the name of the function that returns the default
+// config of the command, e.g., defaultRemoteAddConfig for 'app remote add'.
+func (c Command) DefaultConfig() string {
+	return source.DefaultConfig(c.path())
+}
+
+// ConfigChecks indicates whether the fields of the config struct are checked
+// against the constraints of the flags.
+func (c Command) ConfigChecks() bool {
+	for _, f := range c.Flags {
+		if f.OutOfRange() != "" || f.Pattern != "" || f.Path != "" {
+			return true
+		}
 	}
-	cmd := exec.Command("gofmt", "-w", name)
-	cmd.Stderr = os.Stderr
-	if err := cmd.Run(); err != nil {
-		return err
+	return false
+}
+
+// checkConfig returns an error if the flags cannot be fields of the config
+// struct.
+func (c Command) checkConfig() error {
+	if len(c.Flags) == 0 {
+		return fmt.Errorf("config structs require at least one flag")
+	}
+	keys := map[string]string{"config": "config", "help": "help"}
+	for _, f := range c.Flags {
+		if err := f.configurable(); err != nil {
+			return err
+		}
+		if other, ok := keys[f.Key()]; ok {
+			return fmt.Errorf("flag %s: key %s is already used by %s", f.Name, f.Key(), other)
+		}
+		keys[f.Key()] = f.Name
 	}
 	return nil
 }
+
+// path returns the names of the parents followed by the command name.
+func (c Command) path() []string {
+	return append(append([]string(nil), c.Parents...), c.Name)
+}
+
+// ParseCommandPath splits the command path into the parents and the name of
+// the command, e.g., 'remote/add' results in [remote] and add.
+func ParseCommandPath(path string) ([]string, string, error) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	for _, part := range parts {
+		if part == "" {
+			return nil, "", fmt.Errorf("empty command name in path %q", path)
+		}
+		if err := source.CheckName(part); err != nil {
+			return nil, "", err
+		}
+	}
+	return parts[:len(parts)-1], parts[len(parts)-1], nil
+}
+
+// Completions indicates whether the command has flags that need
+// registered shell completions.
+func (c Command) Completions() bool {
+	for _, f := range c.Flags {
+		if len(f.Values) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Use returns the usage line of the command, e.g., 'copy <src> [dst]'.
+func (c Command) Use() string {
+	switch {
+	case len(c.Args) == 0 && c.HasSubcommands:
+		return c.Name
+	case len(c.Args) == 0:
+		return c.Name + " <arg>"
+	}
+	use := []string{c.Name}
+	for _, a := range c.Args {
+		use = append(use, a.Placeholder())
+	}
+	return strings.Join(use, " ")
+}
+
+// Example returns the example invocation of the command without the path of
+// the parent, e.g., 'copy . --force=true'. It passes valid values for the
+// required positional arguments and flags, such that it is accepted by the
+// generated command. If no flag is required, the first flag is set.
+func (c Command) Example() string {
+	example := []string{c.Name}
+	if len(c.Args) == 0 && !c.HasSubcommands {
+		example = append(example, "arg")
+	}
+	for _, a := range c.Args[:c.MinArgs()] {
+		example = append(example, shellQuote(a.Sample()))
+	}
+	switch {
+	case c.Config:
+		return strings.Join(append(example, "--config", c.Name+".yaml"), " ")
+	case len(c.Flags) == 0:
+		return strings.Join(append(example, "--sample"), " ")
+	}
+	var flags []string
+	for _, f := range c.Flags {
+		if arg := f.exampleArg(); f.Required && arg != "" {
+			flags = append(flags, arg)
+		}
+	}
+	for _, f := range c.Flags {
+		if arg := f.exampleArg(); len(flags) == 0 && arg != "" {
+			flags = append(flags, arg)
+		}
+	}
+	return strings.Join(append(example, flags...), " ")
+}
+
+// ExampleExpr returns the Go expression of the example, which is prefixed by
+// the command path of the parent.
+func (c Command) ExampleExpr() string {
+	format := "  %[1]s " + strings.Replace(c.Example(), "%", "%%", -1)
+	return fmt.Sprintf("fmt.Sprintf(%q, pather.CommandPath())", format)
+}
+
+// MinArgs returns the number of required positional arguments.
+func (c Command) MinArgs() int {
+	var n int
+	for _, a := range c.Args {
+		if !a.Optional && !a.Variadic {
+			n++
+		}
+	}
+	return n
+}
+
+// ArgsValidator returns the Go expression of the cobra.PositionalArgs that
+// validates the number of positional arguments. It is empty if the command
+// has no positional arguments.
+func (c Command) ArgsValidator() string {
+	if len(c.Args) == 0 {
+		return ""
+	}
+	min, last := c.MinArgs(), c.Args[len(c.Args)-1]
+	switch {
+	case last.Variadic && min == 0:
+		return "cobra.ArbitraryArgs"
+	case last.Variadic:
+		return fmt.Sprintf("cobra.MinimumNArgs(%d)", min)
+	case len(c.Args) > min:
+		return fmt.Sprintf("cobra.RangeArgs(%d, %d)", min, len(c.Args))
+	case min == 0:
+		return "cobra.NoArgs"
+	}
+	return fmt.Sprintf("cobra.ExactArgs(%d)", min)
+}
+
+// ParsesArgs indicates whether positional arguments other than the variadic
+// one need to be parsed.
+func (c Command) ParsesArgs() bool {
+	for _, a := range c.Args {
+		if a.Parse != "" && !a.Variadic {
+			return true
+		}
+	}
+	return false
+}
+
+// CompletePaths returns the Go condition on the already completed arguments
+// that holds if the next argument is a path. It is 'true' if all arguments are
+// paths, and empty if there are no path arguments.
+func (c Command) CompletePaths() string {
+	var conds []string
+	for _, a := range c.Args {
+		switch {
+		case a.Path && a.Variadic && a.Index == 0:
+			return "true"
+		case a.Path && a.Variadic:
+			conds = append(conds, fmt.Sprintf("len(args) >= %d", a.Index))
+		case a.Path:
+			conds = append(conds, fmt.Sprintf("len(args) == %d", a.Index))
+		}
+	}
+	return strings.Join(conds, " || ")
+}
+
+// RequiredArgs returns the Go expressions of the command line arguments that
+// are required to run the command. These are valid values for the required
+// positional arguments, and for all required flags, except the one with the
+// given name. It is used by the generated tests.
+func (c Command) RequiredArgs(except string) []string {
+	var args []string
+	if len(c.Args) == 0 && !c.HasSubcommands {
+		args = append(args, `"arg"`)
+	}
+	for _, a := range c.Args[:c.MinArgs()] {
+		args = append(args, strconv.Quote(a.Sample()))
+	}
+	return append(args, c.RequiredFlagArgs(except)...)
+}
+
+// RequiredFlagArgs returns the Go expressions of the command line arguments
+// that set all required flags, except the one with the given name, to valid
+// values. It is used by the generated tests.
+func (c Command) RequiredFlagArgs(except string) []string {
+	var args []string
+	for _, f := range c.Flags {
+		if f.Required && f.Name != except && f.SampleArg() != "" {
+			args = append(args, f.SampleArg())
+		}
+	}
+	return args
+}
+
+// ExcessArgs returns the Go expressions of command line arguments with one
+// positional argument too many. It is empty if the command accepts any number
+// of arguments. It is used by the generated tests.
+func (c Command) ExcessArgs() []string {
+	if len(c.Args) == 0 || c.Args[len(c.Args)-1].Variadic {
+		return nil
+	}
+	var args []string
+	for _, a := range c.Args {
+		args = append(args, strconv.Quote(a.Sample()))
+	}
+	return append(append(args, `"extra"`), c.RequiredFlagArgs("")...)
+}
+
+// StdImports returns the imports from the standard library.
+func (c Command) StdImports() []string {
+	var imports []string
+	for _, imp := range c.imports() {
+		if source.IsStd(imp) {
+			imports = append(imports, imp)
+		}
+	}
+	return imports
+}
+
+// ThirdPartyImports returns the imports that are not part of the standard
+// library, excluding cobra.
+func (c Command) ThirdPartyImports() []string {
+	var imports []string
+	for _, imp := range c.imports() {
+		if !source.IsStd(imp) {
+			imports = append(imports, imp)
+		}
+	}
+	return imports
+}
+
+// imports returns the sorted imports of the command file, excluding fmt and
+// cobra.
+func (c Command) imports() []string {
+	imports := append([]string(nil), c.Imports...)
+	if c.Completions() || c.Config || (c.Call != nil && c.Call.Result) {
+		imports = append(imports, boaImport)
+	}
+	if c.Config {
+		imports = append(imports, "strings", mapstructureImport, viperImport)
+	}
+	sort.Strings(imports)
+	var unique []string
+	for i, imp := range imports {
+		if i == 0 || imp != imports[i-1] {
+			unique = append(unique, imp)
+		}
+	}
+	return unique
+}
+
+// Render renders the command file and returns it as change against the file
+// on disk.
+func (c Command) Render(name string) (source.Change, error) {
+	if c.Config {
+		if err := c.checkConfig(); err != nil {
+			return source.Change{}, err
+		}
+		return c.render(name, tmpl.CommandConfigName)
+	}
+	return c.render(name, tmpl.CommandName)
+}
+
+// RenderTest renders the test file of the command and returns it as change
+// against the file on disk.
+func (c Command) RenderTest(name string) (source.Change, error) {
+	return c.render(name, tmpl.CommandTestName)
+}
+
+func (c Command) render(file, name string) (source.Change, error) {
+	if c.Config {
+		flags := make([]Flag, 0, len(c.Flags))
+		for _, f := range c.Flags {
+			f.config = true
+			flags = append(flags, f)
+		}
+		c.Flags = flags
+	}
+	templates := c.Templates
+	if templates == nil {
+		templates = tmpl.Builtin()
+	}
+	old, err := readFile(file)
+	if err != nil {
+		return source.Change{}, err
+	}
+	raw, err := render(file, name, templates[name], "", c)
+	if err != nil {
+		return source.Change{}, err
+	}
+	return source.Change{Path: file, Old: old, New: raw}, nil
+}
+
+// Create writes the rendered command to the file. An existing file is handled
+// according to the policy.
+func (c Command) Create(name string, policy source.Policy) error {
+	change, err := c.Render(name)
+	if err != nil {
+		return err
+	}
+	return create([]source.Change{change}, policy)
+}
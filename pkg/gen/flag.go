@@ -15,8 +15,11 @@ package gen
 
 import (
 	"fmt"
+	"regexp"
 	"sort"
+	"strconv"
 	"strings"
+	"unicode"
 )
 
 // Flag holds the flag description.
@@ -25,49 +28,440 @@ type Flag struct {
 	Type     string
 	Register string
 	Default  string
+	// Values holds the allowed values of enum flags.
+	Values []string
+	// Short is the one letter shorthand of the flag. It is empty if the flag
+	// has no shorthand.
+	Short string
+	// Usage is the usage text of the flag.
+	Usage string
+	// Required indicates that the flag must be set on the command line.
+	Required bool
+	// Import is the package required by the flag type, if any.
+	Import string
+	// Range is the inclusive range constraint of numeric and duration flags
+	// as specified, e.g., '1..65535' or '1s..'. Min and Max are the Go
+	// expressions of the bounds. They are empty if the bound is not set.
+	Range string
+	Min   string
+	Max   string
+	// Pattern is the regular expression that the value of string flags must
+	// match.
+	Pattern string
+	// Path is the constraint of path flags. It is one of 'exists', 'file' and
+	// 'dir', or empty if the path is not checked.
+	Path string
+
+	// config indicates that the flag is a field of a config struct, whose
+	// command line flag is named after the key.
+	config bool
+	// field is the name of the struct field that the flag was generated
+	// from. conv is the type that the value is converted to, deref indicates
+	// that the value is dereferenced when it is assigned to the field.
+	field string
+	conv  string
+	deref bool
 }
 
-// ParseFlags parses a list of flags.
-func ParseFlags(inputs []string) ([]Flag, []string, error) {
-	flags := make([]Flag, 0, len(inputs))
-	imports := map[string]struct{}{}
-	for _, input := range inputs {
-		f, imp, err := ParseFlag(input)
+// IsVar indicates whether the flag is registered with a pflag.Value.
+func (f Flag) IsVar() bool {
+	return f.Register == "Var"
+}
+
+// OutOfRange returns the Go condition that holds if the value of the flag is
+// outside its range. It is empty if the flag has no range constraint.
+func (f Flag) OutOfRange() string {
+	return f.outOfRange("flags." + f.Name)
+}
+
+// outOfRange returns the Go condition that holds if the value is outside the
+// range of the flag.
+func (f Flag) outOfRange(value string) string {
+	var conds []string
+	if f.Min != "" {
+		conds = append(conds, fmt.Sprintf("%s < %s", value, f.Min))
+	}
+	if f.Max != "" {
+		conds = append(conds, fmt.Sprintf("%s > %s", value, f.Max))
+	}
+	if len(conds) == 2 {
+		return "(" + strings.Join(conds, " || ") + ")"
+	}
+	return strings.Join(conds, "")
+}
+
+// Field returns the name of the field in the config struct, e.g., MaxSize
+// for the flag maxSize or max_size.
+func (f Flag) Field() string {
+	if f.field != "" {
+		return f.field
+	}
+	var b strings.Builder
+	for _, part := range strings.Split(f.Name, "_") {
+		if part != "" {
+			b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+		}
+	}
+	return b.String()
+}
+
+// Value returns the Go expression of the flag value that is assigned to the
+// struct field of the options, e.g., flags.addr or *flags.addr.
+func (f Flag) Value() string {
+	switch {
+	case f.conv != "":
+		return fmt.Sprintf("%s(flags.%s)", f.conv, f.Name)
+	case f.deref:
+		return "*flags." + f.Name
+	}
+	return "flags." + f.Name
+}
+
+// FlagName returns the name of the command line flag. Flags of config structs
+// and flags generated from struct fields are named after the key, e.g.,
+// shutdown-timeout for the field ShutdownTimeout. All other flags are named as
+// specified.
+func (f Flag) FlagName() string {
+	if f.config || f.field != "" {
+		return f.Key()
+	}
+	return f.Name
+}
+
+// Key returns the configuration key of the flag. It is the flag name in lower
+// case, with words separated by dashes, e.g., max-size for the flag maxSize or
+// max_size. In config structs, the key is also the name of the flag.
+func (f Flag) Key() string {
+	return dashed(f.Name)
+}
+
+// dashed returns the identifier in lower case, with words separated by dashes,
+// e.g., max-url-size for maxURLSize or max_url_size.
+func dashed(ident string) string {
+	var b strings.Builder
+	for _, part := range strings.Split(ident, "_") {
+		if part == "" {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteByte('-')
+		}
+		for i, r := range part {
+			if i > 0 && unicode.IsUpper(r) {
+				prev, next := rune(part[i-1]), rune(0)
+				if i+1 < len(part) {
+					next = rune(part[i+1])
+				}
+				if !unicode.IsUpper(prev) || unicode.IsLower(next) {
+					b.WriteByte('-')
+				}
+			}
+			b.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return b.String()
+}
+
+// ConfigType returns the type of the field in the config struct. Enums are
+// plain strings, their allowed values are listed in the struct tag.
+func (f Flag) ConfigType() string {
+	if len(f.Values) > 0 {
+		return "string"
+	}
+	return f.Type
+}
+
+// ConfigDefault returns the Go expression of the default value of the field
+// in the config struct.
+func (f Flag) ConfigDefault() string {
+	for _, v := range f.Values {
+		if strings.HasPrefix(f.Default, fmt.Sprintf("flag.NewEnum(%q,", v)) {
+			return strconv.Quote(v)
+		}
+	}
+	return f.Default
+}
+
+// ConfigTag returns the struct tag of the field in the config struct. The
+// allowed values of enums are listed in the 'oneof' validation rule, which is
+// used by boa.RegisterCompletions.
+func (f Flag) ConfigTag() string {
+	tag := fmt.Sprintf("mapstructure:%q", f.Key())
+	if len(f.Values) == 0 {
+		return tag
+	}
+	values := make([]string, 0, len(f.Values))
+	for _, v := range f.Values {
+		if strings.Contains(v, " ") {
+			v = "'" + v + "'"
+		}
+		values = append(values, v)
+	}
+	return tag + fmt.Sprintf(" validate:%q", "oneof="+strings.Join(values, " "))
+}
+
+// ConfigOutOfRange returns the Go condition that holds if the value of the
+// field in the config struct is outside its range. It is empty if the flag
+// has no range constraint.
+func (f Flag) ConfigOutOfRange() string {
+	return f.outOfRange("cfg." + f.Field())
+}
+
+// configurable returns an error if the flag cannot be part of a config
+// struct. The flags of config structs are registered by boa.AddFlags, which
+// supports neither shorthands nor all slice types. The values can be set by
+// environment variables and config files, thus they cannot be required on the
+// command line.
+func (f Flag) configurable() error {
+	switch {
+	case f.Short != "":
+		return fmt.Errorf("flag %s: shorthands are not supported in config structs", f.Name)
+	case f.Required:
+		return fmt.Errorf("flag %s: required flags are not supported in config structs", f.Name)
+	case f.Type == "[]byte" || (strings.HasPrefix(f.Type, "[]") && !configSlices[f.Type]):
+		return fmt.Errorf("flag %s: type %s is not supported in config structs", f.Name, f.Type)
+	}
+	return nil
+}
+
+// configSlices are the slice types that boa.AddFlags supports.
+var configSlices = map[string]bool{
+	"[]bool":   true,
+	"[]int":    true,
+	"[]int32":  true,
+	"[]int64":  true,
+	"[]uint":   true,
+	"[]string": true,
+}
+
+// bounds returns the bounds of the range constraint as specified.
+func (f Flag) bounds() (string, string) {
+	parts := strings.SplitN(f.Range, "..", 2)
+	if len(parts) != 2 {
+		return "", ""
+	}
+	return parts[0], parts[1]
+}
+
+// imports returns the imports required by the flag type and the generated
+// validation.
+func (f Flag) imports() []string {
+	var imports []string
+	if f.Import != "" {
+		imports = append(imports, f.Import)
+	}
+	if f.Pattern != "" {
+		imports = append(imports, "regexp")
+	}
+	if f.Path != "" {
+		imports = append(imports, "os")
+	}
+	return imports
+}
+
+// Sample returns a valid command line value for the flag. It is used by the
+// generated tests. It is empty if no static value satisfies the constraints,
+// or if the flag is of a pflag.Value type whose values are unknown, e.g., a
+// type of a struct field.
+func (f Flag) Sample() string {
+	if len(f.Values) > 0 {
+		return f.Values[len(f.Values)-1]
+	}
+	if f.IsVar() {
+		return ""
+	}
+	if min, max := f.bounds(); min != "" || max != "" {
+		if min != "" {
+			return min
+		}
+		return max
+	}
+	if f.Pattern != "" {
+		re := regexp.MustCompile(f.Pattern)
+		prefix, _ := re.LiteralPrefix()
+		for _, candidate := range []string{"value", prefix + "value", prefix, prefix + "1"} {
+			if re.MatchString(candidate) {
+				return candidate
+			}
+		}
+		return ""
+	}
+	switch f.Path {
+	case "exists", "dir":
+		return "."
+	case "file":
+		return ""
+	}
+	switch f.Register {
+	case "BytesBase64Var":
+		return "Ym9h"
+	case "BytesHexVar":
+		return "b0a0"
+	}
+	if strings.HasPrefix(f.Type, "[]") {
+		v := sample(strings.TrimPrefix(f.Type, "[]"))
+		return v + "," + v
+	}
+	return sample(f.Type)
+}
+
+// SampleArg returns the Go expression of the command line argument that sets
+// the flag to a valid value. Paths to existing files are set to the running
+// test binary. It is empty if no valid value is known.
+func (f Flag) SampleArg() string {
+	name := f.FlagName()
+	if f.Path == "file" {
+		return fmt.Sprintf("%q + os.Args[0]", "--"+name+"=")
+	}
+	sample := f.Sample()
+	if sample == "" {
+		return ""
+	}
+	return strconv.Quote("--" + name + "=" + sample)
+}
+
+// exampleArg returns the command line argument that sets the flag to a valid
+// value in the example. It is empty if no static value is known.
+func (f Flag) exampleArg() string {
+	sample := f.Sample()
+	if sample == "" {
+		return ""
+	}
+	return "--" + f.FlagName() + "=" + shellQuote(sample)
+}
+
+// shellQuote quotes the value for the shell, if it contains characters other
+// than letters, digits and punctuation that is safe in shell words.
+func shellQuote(v string) string {
+	for _, r := range v {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("-_.,:/=+@", r) {
+			return "'" + strings.Replace(v, "'", `'\''`, -1) + "'"
+		}
+	}
+	return v
+}
+
+// sample returns a valid command line value for the Go type.
+func sample(typ string) string {
+	switch {
+	case typ == "bool":
+		return "true"
+	case typ == "string":
+		return "value"
+	case typ == "time.Duration":
+		return "1s"
+	case typ == "net.IP":
+		return "127.0.0.1"
+	case strings.HasPrefix(typ, "float"):
+		return "1.5"
+	default:
+		return "1"
+	}
+}
+
+// ParseFlags parses the flags in the compact and in the long notation. Each
+// compact input is a comma separated list of flags, see ParseFlag. Each long
+// input describes a single flag, see ParseFlagSpec. The flag names and
+// shorthands must be unique. The sorted imports required by the flags are
+// returned alongside.
+func ParseFlags(compact, long []string) ([]Flag, []string, error) {
+	var flags []Flag
+	for _, input := range compact {
+		s := &specScanner{input: input}
+		for {
+			f, err := s.compact()
+			if err != nil {
+				return nil, nil, err
+			}
+			flags = append(flags, f)
+			if s.eof() {
+				break
+			}
+			// The scanner stops at the comma that separates the flags.
+			s.pos++
+		}
+	}
+	for _, input := range long {
+		f, err := ParseFlagSpec(input)
 		if err != nil {
 			return nil, nil, err
 		}
-		if imp != "" {
+		flags = append(flags, f)
+	}
+	imports, err := checkFlags(flags)
+	if err != nil {
+		return nil, nil, err
+	}
+	return flags, imports, nil
+}
+
+// checkFlags checks that the flag names and shorthands are unique, and returns
+// the sorted imports required by the flags.
+func checkFlags(flags []Flag) ([]string, error) {
+	names := map[string]bool{"help": true}
+	shorts := map[string]string{"h": "help"}
+	imports := map[string]struct{}{}
+	for _, f := range flags {
+		if names[f.Name] {
+			return nil, fmt.Errorf("duplicate flag name: %s", f.Name)
+		}
+		names[f.Name] = true
+		if other, ok := shorts[f.Short]; ok && f.Short != "" {
+			return nil, fmt.Errorf("duplicate shorthand %s for flags %s and %s",
+				f.Short, other, f.Name)
+		}
+		shorts[f.Short] = f.Name
+		for _, imp := range f.imports() {
 			imports[imp] = struct{}{}
 		}
-		flags = append(flags, f)
 	}
 	var unique []string
 	for imp := range imports {
 		unique = append(unique, imp)
 	}
 	sort.Strings(unique)
-	return flags, unique, nil
-}
-
-// ParseFlag parses a single flag description.
-func ParseFlag(input string) (Flag, string, error) {
-	s := strings.Split(input, ":")
-	if len(s) != 2 {
-		return Flag{}, "", fmt.Errorf("malformed flag: %s", input)
-	}
-	for flagType, v := range supportedFlag {
-		if strings.EqualFold(flagType, s[1]) {
-			return Flag{
-				Name:     s[0],
-				Type:     v.Type,
-				Default:  v.Default,
-				Register: v.Register,
-			}, v.Import, nil
-		}
+	return unique, nil
+}
+
+// ParseFlag parses a single flag in the compact notation:
+//
+//	name:type[=default][,short][!]
+//
+// For example, 'name:string=alice,n!' describes the required string flag
+// 'name' with the default 'alice' and the shorthand 'n'. Defaults that contain
+// a comma or an exclamation mark must be quoted, e.g., 'tags:[]string="a,b"'.
+// Parse errors are reported as *SpecError.
+func ParseFlag(input string) (Flag, error) {
+	s := &specScanner{input: input}
+	f, err := s.compact()
+	if err != nil {
+		return Flag{}, err
 	}
-	return Flag{}, "", fmt.Errorf("unsupported flag type: %s", s[1])
+	if !s.eof() {
+		return Flag{}, s.errorf(s.pos, "unexpected %q, expected a single flag", s.peek())
+	}
+	return f, nil
+}
+
+// ParseFlagSpec parses a single flag in the long notation. The name and type
+// are followed by space separated attributes:
+//
+//	name:string default=alice short=n required usage="who to greet"
+//
+// Values that contain spaces must be quoted. Parse errors are reported as
+// *SpecError.
+func ParseFlagSpec(input string) (Flag, error) {
+	s := &specScanner{input: input}
+	return s.long()
 }
 
+const (
+	boaImport          = "github.com/oncilla/boa/pkg/boa"
+	boaFlagImport      = "github.com/oncilla/boa/pkg/boa/flag"
+	mapstructureImport = "github.com/mitchellh/mapstructure"
+	viperImport        = "github.com/spf13/viper"
+)
+
 var supportedFlag = map[string]struct {
 	Type     string
 	Register string
@@ -237,6 +631,11 @@ var supportedFlag = map[string]struct {
 		Default:  `""`,
 		Register: "StringVar",
 	},
+	"path": {
+		Type:     "string",
+		Default:  `""`,
+		Register: "StringVar",
+	},
 	"[]string": {
 		Type:     "[]string",
 		Default:  "nil",
@@ -0,0 +1,503 @@
+// Copyright 2020 oncilla
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/oncilla/boa/pkg/manifest"
+)
+
+// Ref references a declaration in a Go package, e.g., ./internal/server.Options.
+type Ref struct {
+	// Package is the import path of the package, or its directory if it
+	// starts with './' or '../'.
+	Package string
+	// Name is the name of the declaration.
+	Name string
+}
+
+// ParseRef parses a reference to a declaration in a Go package. The name
+// follows the last '.' after the last '/', e.g., ./internal/server.Options.
+func ParseRef(input string) (Ref, error) {
+	i := strings.LastIndex(input, ".")
+	if i <= strings.LastIndex(input, "/") || i == 0 {
+		return Ref{}, fmt.Errorf("invalid reference %q, expected package.Name", input)
+	}
+	ref := Ref{Package: input[:i], Name: input[i+1:]}
+	if !token.IsIdentifier(ref.Name) || !token.IsExported(ref.Name) {
+		return Ref{}, fmt.Errorf("invalid reference %q: %q is not an exported identifier",
+			input, ref.Name)
+	}
+	return ref, nil
+}
+
+// GoPackage is a type-checked Go package that is loaded from source.
+type GoPackage struct {
+	// Path is the import path of the package.
+	Path  string
+	Fset  *token.FileSet
+	Files []*ast.File
+	Types *types.Package
+}
+
+// LoadPackage loads and type-checks the package with the import path. Local
+// paths that start with './' or '../' are resolved relative to the directory,
+// their import path is derived from the go.mod file. Dependencies are
+// type-checked from source, too.
+func LoadPackage(dir, path string) (*GoPackage, error) {
+	bp, err := build.Import(path, dir, build.ImportComment)
+	if err != nil {
+		return nil, err
+	}
+	importPath := bp.ImportPath
+	if build.IsLocalImport(path) {
+		if importPath, err = manifest.Module(bp.Dir); err != nil {
+			return nil, err
+		}
+		if importPath == "" {
+			return nil, fmt.Errorf("package %s is not part of a module", path)
+		}
+	}
+	p := &GoPackage{Path: importPath, Fset: token.NewFileSet()}
+	for _, name := range bp.GoFiles {
+		f, err := parser.ParseFile(p.Fset, filepath.Join(bp.Dir, name), nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		p.Files = append(p.Files, f)
+	}
+	conf := types.Config{Importer: importer.ForCompiler(p.Fset, "source", nil)}
+	if p.Types, err = conf.Check(importPath, p.Fset, p.Files, nil); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// StructFlags returns a flag for every exported field of the struct type with
+// the given name. The flag name is the field name starting with a lower case
+// letter, e.g., shutdownTimeout for ShutdownTimeout, and the usage is taken
+// from the doc comment of the field. Fields of a type that implements
+// pflag.Value, e.g., the types in pkg/boa/flag, are registered with Var. The
+// sorted imports required by the flags are returned alongside.
+func (p *GoPackage) StructFlags(name string) ([]Flag, []string, error) {
+	named, err := p.named(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	st, ok := named.Underlying().(*types.Struct)
+	if !ok {
+		return nil, nil, fmt.Errorf("%s.%s is not a struct type", p.Types.Name(), name)
+	}
+	docs := p.fieldDocs(name)
+	var flags []Flag
+	for i := 0; i < st.NumFields(); i++ {
+		field := st.Field(i)
+		if !field.Exported() {
+			continue
+		}
+		if field.Embedded() {
+			return nil, nil, fmt.Errorf("field %s: embedded fields are not supported", field.Name())
+		}
+		f, err := typeFlag(field.Type())
+		if err != nil {
+			return nil, nil, fmt.Errorf("field %s: %s", field.Name(), err)
+		}
+		f.Name = lowerIdent(field.Name())
+		f.Usage = usage(field.Name(), docs[field.Name()])
+		f.field = field.Name()
+		flags = append(flags, f)
+	}
+	if len(flags) == 0 {
+		return nil, nil, fmt.Errorf("%s.%s has no exported fields", p.Types.Name(), name)
+	}
+	imports, err := checkFlags(flags)
+	if err != nil {
+		return nil, nil, err
+	}
+	return flags, imports, nil
+}
+
+// Call returns the call of the function with the given name, which takes the
+// options of the named struct type. The supported signatures are:
+//
+//	func([context.Context, ]Options)[ error]
+//	func([context.Context, ]*Options)[ error]
+func (p *GoPackage) Call(name, options string) (*Call, error) {
+	obj := p.Types.Scope().Lookup(name)
+	fn, ok := obj.(*types.Func)
+	if !ok || !fn.Exported() {
+		return nil, fmt.Errorf("exported function %s not found in %s", name, p.Path)
+	}
+	sig := fn.Type().(*types.Signature)
+	c := &Call{
+		Func:    p.Types.Name() + "." + name,
+		Options: p.Types.Name() + "." + options,
+	}
+	params := sig.Params()
+	var i int
+	if params.Len() > 0 && isNamed(params.At(0).Type(), "context", "Context") {
+		c.Args = append(c.Args, "cmd.Context()")
+		i++
+	}
+	if params.Len() == i+1 {
+		t, arg := params.At(i).Type(), "opts"
+		if ptr, ok := t.(*types.Pointer); ok {
+			t, arg = ptr.Elem(), "&opts"
+		}
+		if isNamed(t, p.Path, options) {
+			c.Args = append(c.Args, arg)
+			i++
+		}
+	}
+	results := sig.Results()
+	if results.Len() == 1 && isNamed(results.At(0).Type(), "", "error") {
+		c.Error = true
+	}
+	if i != params.Len() || results.Len() > 1 || (results.Len() == 1 && !c.Error) {
+		return nil, fmt.Errorf("unsupported signature of %s: %s, expected func([context.Context, ]%s) error",
+			name, types.TypeString(sig, (*types.Package).Name), c.Options)
+	}
+	return c, nil
+}
+
+// WrapFunc returns the command that wraps the function with the given name.
+// The command is named after the function, e.g., do-thing for DoThing. The
+// parameters of the function are mapped as follows:
+//
+//   - A leading context.Context is the context of the command.
+//   - A struct declared in the package, or a pointer to it, is built from
+//     flags, see StructFlags.
+//   - Parameters of the types string, bool, int, int64, uint64, float64 and
+//     time.Duration are positional arguments. A variadic parameter takes all
+//     remaining arguments.
+//
+// The function returns at most one value and an optional error. The value is
+// printed in the format selected by the generated output flag.
+func (p *GoPackage) WrapFunc(name string) (Command, error) {
+	fn, ok := p.Types.Scope().Lookup(name).(*types.Func)
+	if !ok || !fn.Exported() {
+		return Command{}, fmt.Errorf("exported function %s not found in %s", name, p.Path)
+	}
+	sig := fn.Type().(*types.Signature)
+	c := Command{
+		Name:    dashed(lowerIdent(name)),
+		Imports: []string{p.Path},
+		Call:    &Call{Func: p.Types.Name() + "." + name},
+	}
+	var specs []string
+	params := sig.Params()
+	for i := 0; i < params.Len(); i++ {
+		param := params.At(i)
+		if i == 0 && isNamed(param.Type(), "context", "Context") {
+			c.Call.Args = append(c.Call.Args, "cmd.Context()")
+			continue
+		}
+		if named, expr, ok := options(param.Type()); ok {
+			if c.Call.Options != "" {
+				return Command{}, fmt.Errorf("function %s takes more than one options struct", name)
+			}
+			if named.Obj().Pkg() != p.Types {
+				return Command{}, fmt.Errorf("parameter %s: options struct %s must be declared in %s",
+					param.Name(), types.TypeString(named, (*types.Package).Name), p.Path)
+			}
+			flags, imports, err := p.StructFlags(named.Obj().Name())
+			if err != nil {
+				return Command{}, err
+			}
+			c.Flags = flags
+			c.Imports = append(c.Imports, imports...)
+			c.Call.Options = types.TypeString(named, (*types.Package).Name)
+			c.Call.Args = append(c.Call.Args, expr)
+			continue
+		}
+		arg := param.Name()
+		if arg == "" || arg == "_" {
+			arg = fmt.Sprintf("arg%d", i)
+		}
+		t, variadic := param.Type(), sig.Variadic() && i == params.Len()-1
+		if variadic {
+			t = t.(*types.Slice).Elem()
+		}
+		typ, ok := argType(t)
+		if !ok {
+			return Command{}, fmt.Errorf("parameter %s: unsupported type %s",
+				param.Name(), types.TypeString(param.Type(), (*types.Package).Name))
+		}
+		if variadic {
+			specs = append(specs, arg+":..."+typ)
+			c.Call.Args = append(c.Call.Args, "positional."+arg+"...")
+			continue
+		}
+		specs = append(specs, arg+":"+typ)
+		c.Call.Args = append(c.Call.Args, "positional."+arg)
+	}
+	if len(specs) > 0 {
+		args, imports, err := ParseArgs([]string{strings.Join(specs, ",")})
+		if err != nil {
+			return Command{}, err
+		}
+		c.Args = args
+		c.Imports = append(c.Imports, imports...)
+	}
+	results := sig.Results()
+	n := results.Len()
+	if n > 0 && isNamed(results.At(n-1).Type(), "", "error") {
+		c.Call.Error = true
+		n--
+	}
+	switch {
+	case n > 1:
+		return Command{}, fmt.Errorf("function %s returns more than one value besides the error", name)
+	case n == 1:
+		c.Call.Result = true
+		output, err := ParseFlag("output:enum(text|json),o")
+		if err != nil {
+			return Command{}, err
+		}
+		output.Usage = "output format"
+		c.Flags = append(c.Flags, output)
+		c.Imports = append(c.Imports, output.Import)
+	}
+	if _, err := checkFlags(c.Flags); err != nil {
+		return Command{}, err
+	}
+	return c, nil
+}
+
+func (p *GoPackage) named(name string) (*types.Named, error) {
+	obj, ok := p.Types.Scope().Lookup(name).(*types.TypeName)
+	if !ok || !obj.Exported() {
+		return nil, fmt.Errorf("exported type %s not found in %s", name, p.Path)
+	}
+	named, ok := obj.Type().(*types.Named)
+	if !ok {
+		return nil, fmt.Errorf("%s.%s is not a named type", p.Types.Name(), name)
+	}
+	return named, nil
+}
+
+// fieldDocs returns the doc comments of the fields of the struct type. The
+// trailing line comment is used for fields without doc comment.
+func (p *GoPackage) fieldDocs(name string) map[string]string {
+	docs := map[string]string{}
+	for _, f := range p.Files {
+		ast.Inspect(f, func(n ast.Node) bool {
+			spec, ok := n.(*ast.TypeSpec)
+			if !ok || spec.Name.Name != name {
+				return true
+			}
+			st, ok := spec.Type.(*ast.StructType)
+			if !ok {
+				return false
+			}
+			for _, field := range st.Fields.List {
+				doc := field.Doc
+				if doc == nil {
+					doc = field.Comment
+				}
+				for _, ident := range field.Names {
+					docs[ident.Name] = doc.Text()
+				}
+			}
+			return false
+		})
+	}
+	return docs
+}
+
+// pflagValue is the method set of pflag.Value.
+var pflagValue = func() *types.Interface {
+	str := types.NewSignature(nil, nil,
+		types.NewTuple(types.NewVar(token.NoPos, nil, "", types.Typ[types.String])), false)
+	set := types.NewSignature(nil,
+		types.NewTuple(types.NewVar(token.NoPos, nil, "", types.Typ[types.String])),
+		types.NewTuple(types.NewVar(token.NoPos, nil, "", types.Universe.Lookup("error").Type())),
+		false)
+	iface := types.NewInterfaceType([]*types.Func{
+		types.NewFunc(token.NoPos, nil, "Set", set),
+		types.NewFunc(token.NoPos, nil, "String", str),
+		types.NewFunc(token.NoPos, nil, "Type", str),
+	}, nil)
+	return iface.Complete()
+}()
+
+// typeFlag returns the flag for a field of the type. The type must be one of
+// the supported flag types, a named type with a supported underlying type, or
+// implement pflag.Value. Values of named types are converted.
+func typeFlag(t types.Type) (Flag, error) {
+	qualified := types.TypeString(t, (*types.Package).Name)
+	var key string
+	switch {
+	case isNamed(t, "time", "Duration"):
+		key = "duration"
+	case isNamed(t, "net", "IP"):
+		key = "ip"
+	case types.Implements(t, pflagValue):
+		ptr, ok := t.(*types.Pointer)
+		if !ok {
+			return Flag{}, fmt.Errorf("unsupported type %s: pflag.Value is implemented by value", qualified)
+		}
+		return varFlag(ptr.Elem(), false)
+	case types.Implements(types.NewPointer(t), pflagValue):
+		return varFlag(t, true)
+	default:
+		key = types.TypeString(t.Underlying(), nil)
+		switch key {
+		case "[]time.Duration":
+			key = "[]duration"
+		case "[]byte", "[]uint8":
+			key = "bytes"
+		}
+	}
+	s, ok := supportedFlag[key]
+	if !ok {
+		return Flag{}, fmt.Errorf("unsupported type %s", qualified)
+	}
+	f := Flag{
+		Type:     s.Type,
+		Register: s.Register,
+		Default:  s.Default,
+		Import:   s.Import,
+	}
+	if named, ok := t.(*types.Named); ok && s.Type != qualified {
+		if err := exportedType(named); err != nil {
+			return Flag{}, err
+		}
+		f.conv = qualified
+	}
+	return f, nil
+}
+
+// varFlag returns the flag for a field of a type whose pointer implements
+// pflag.Value. The flag holds a pointer to a new value, which is dereferenced
+// if the field is not a pointer.
+func varFlag(t types.Type, deref bool) (Flag, error) {
+	named, ok := t.(*types.Named)
+	if !ok {
+		return Flag{}, fmt.Errorf("unsupported type %s", t)
+	}
+	if err := exportedType(named); err != nil {
+		return Flag{}, err
+	}
+	qualified := types.TypeString(named, (*types.Package).Name)
+	return Flag{
+		Type:     "*" + qualified,
+		Register: "Var",
+		Default:  "new(" + qualified + ")",
+		Import:   named.Obj().Pkg().Path(),
+		deref:    deref,
+	}, nil
+}
+
+func exportedType(named *types.Named) error {
+	if !named.Obj().Exported() || named.Obj().Pkg() == nil {
+		return fmt.Errorf("unsupported type %s: not exported", named)
+	}
+	return nil
+}
+
+// options returns the named struct type of an options parameter, and the Go
+// expression of the argument, i.e., opts or &opts for pointers.
+func options(t types.Type) (*types.Named, string, bool) {
+	expr := "opts"
+	if ptr, ok := t.(*types.Pointer); ok {
+		t, expr = ptr.Elem(), "&opts"
+	}
+	named, ok := t.(*types.Named)
+	if !ok {
+		return nil, "", false
+	}
+	if _, ok := named.Underlying().(*types.Struct); !ok {
+		return nil, "", false
+	}
+	return named, expr, true
+}
+
+// argType returns the positional argument type for the Go type.
+func argType(t types.Type) (string, bool) {
+	if isNamed(t, "time", "Duration") {
+		return "duration", true
+	}
+	basic, ok := t.(*types.Basic)
+	if !ok {
+		return "", false
+	}
+	switch basic.Kind() {
+	case types.String, types.Bool, types.Int, types.Int64, types.Uint64, types.Float64:
+		return basic.Name(), true
+	}
+	return "", false
+}
+
+// isNamed indicates whether the type is the named type in the package. The
+// package path is empty for predeclared types.
+func isNamed(t types.Type, pkg, name string) bool {
+	named, ok := t.(*types.Named)
+	if !ok || named.Obj().Name() != name {
+		return false
+	}
+	if named.Obj().Pkg() == nil {
+		return pkg == ""
+	}
+	return named.Obj().Pkg().Path() == pkg
+}
+
+// lowerIdent returns the identifier starting with a lower case letter. A
+// leading initialism is lowered completely, e.g., urlPath for URLPath.
+func lowerIdent(name string) string {
+	runes := []rune(name)
+	n := 0
+	for n < len(runes) && unicode.IsUpper(runes[n]) {
+		n++
+	}
+	if n > 1 && n < len(runes) {
+		n--
+	}
+	return strings.ToLower(string(runes[:n])) + string(runes[n:])
+}
+
+// usage returns the usage text derived from the doc comment of the field. It
+// is the first sentence of the comment without the leading field name, and
+// without a following 'is the' or 'are the', e.g., 'Addr is the address to
+// listen on.' results in 'address to listen on'.
+func usage(field, doc string) string {
+	doc = strings.Join(strings.Fields(doc), " ")
+	if doc == "" {
+		return lowerIdent(field) + " description"
+	}
+	if i := strings.Index(doc, ". "); i >= 0 {
+		doc = doc[:i]
+	}
+	doc = strings.TrimSuffix(strings.TrimPrefix(doc, field+" "), ".")
+	for _, verb := range []string{"is ", "are "} {
+		if rest := strings.TrimPrefix(doc, verb); rest != doc {
+			doc = rest
+			for _, article := range []string{"the ", "a ", "an "} {
+				doc = strings.TrimPrefix(doc, article)
+			}
+			break
+		}
+	}
+	return doc
+}
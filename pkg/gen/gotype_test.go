@@ -0,0 +1,128 @@
+// Copyright 2020 oncilla
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gen_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oncilla/boa/pkg/gen"
+)
+
+func TestParseRef(t *testing.T) {
+	ref, err := gen.ParseRef("./internal/server.Options")
+	require.NoError(t, err)
+	assert.Equal(t, gen.Ref{Package: "./internal/server", Name: "Options"}, ref)
+	ref, err = gen.ParseRef("example.com/app/server.Options")
+	require.NoError(t, err)
+	assert.Equal(t, gen.Ref{Package: "example.com/app/server", Name: "Options"}, ref)
+
+	for _, input := range []string{"Options", "./internal/server", "server.options", ".Options"} {
+		_, err := gen.ParseRef(input)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestGoPackage(t *testing.T) {
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	pkg, err := gen.LoadPackage(wd, "./testdata/options")
+	require.NoError(t, err)
+	assert.Equal(t, "github.com/oncilla/boa/pkg/gen/testdata/options", pkg.Path)
+
+	t.Run("flags", func(t *testing.T) {
+		flags, imports, err := pkg.StructFlags("Options")
+		require.NoError(t, err)
+		type flag struct {
+			Name, FlagName, Type, Register, Usage, Value string
+		}
+		var actual []flag
+		for _, f := range flags {
+			actual = append(actual, flag{f.Name, f.FlagName(), f.Type, f.Register, f.Usage, f.Value()})
+		}
+		assert.Equal(t, []flag{
+			{"addr", "addr", "*flag.TCPAddr", "Var", "address to listen on", "*flags.addr"},
+			{"ip", "ip", "net.IP", "IPVar", "address of the upstream server", "flags.ip"},
+			{"shutdownTimeout", "shutdown-timeout", "time.Duration", "DurationVar",
+				"limits the time to wait for open connections", "flags.shutdownTimeout"},
+			{"level", "level", "string", "StringVar", "log level", "options.Level(flags.level)"},
+			{"mode", "mode", "*flag.Enum", "Var", "mode of operation", "flags.mode"},
+			{"tags", "tags", "[]string", "StringSliceVar", "tags attached to all requests", "flags.tags"},
+		}, actual)
+		assert.Equal(t, []string{"github.com/oncilla/boa/pkg/boa/flag", "net", "time"}, imports)
+	})
+	t.Run("unsupported flags", func(t *testing.T) {
+		_, _, err := pkg.StructFlags("Unsupported")
+		assert.EqualError(t, err, "field Nested: unsupported type struct{A int}")
+		_, _, err = pkg.StructFlags("Level")
+		assert.EqualError(t, err, "options.Level is not a struct type")
+		_, _, err = pkg.StructFlags("Missing")
+		assert.Error(t, err)
+	})
+	t.Run("call", func(t *testing.T) {
+		call, err := pkg.Call("Run", "Options")
+		require.NoError(t, err)
+		assert.Equal(t, &gen.Call{
+			Func:    "options.Run",
+			Args:    []string{"cmd.Context()", "opts"},
+			Options: "options.Options",
+			Error:   true,
+		}, call)
+		call, err = pkg.Call("Start", "Options")
+		require.NoError(t, err)
+		assert.Equal(t, &gen.Call{
+			Func:    "options.Start",
+			Args:    []string{"&opts"},
+			Options: "options.Options",
+		}, call)
+	})
+	t.Run("wrap", func(t *testing.T) {
+		c, err := pkg.WrapFunc("Greet")
+		require.NoError(t, err)
+		assert.Equal(t, "greet", c.Name)
+		assert.Equal(t, "greet <count> [names...]", c.Use())
+		assert.Equal(t, &gen.Call{
+			Func:    "options.Greet",
+			Args:    []string{"cmd.Context()", "positional.count", "&opts", "positional.names..."},
+			Options: "options.Options",
+			Result:  true,
+			Error:   true,
+		}, c.Call)
+		assert.Len(t, c.OptionFlags(), 6)
+		assert.Equal(t, "output", c.Flags[len(c.Flags)-1].Name)
+
+		c, err = pkg.WrapFunc("Version")
+		require.NoError(t, err)
+		assert.Equal(t, "version", c.Name)
+		assert.Equal(t, &gen.Call{Func: "options.Version", Result: true}, c.Call)
+		assert.Empty(t, c.Args)
+		assert.Empty(t, c.OptionFlags())
+
+		_, err = pkg.WrapFunc("Split")
+		assert.EqualError(t, err, "parameter raw: unsupported type []byte")
+		_, err = pkg.WrapFunc("Missing")
+		assert.Error(t, err)
+	})
+	t.Run("unsupported call", func(t *testing.T) {
+		_, err := pkg.Call("Invalid", "Options")
+		assert.EqualError(t, err, "unsupported signature of Invalid: "+
+			"func(opts options.Options) (int, error), "+
+			"expected func([context.Context, ]options.Options) error")
+		_, err = pkg.Call("Missing", "Options")
+		assert.Error(t, err)
+	})
+}
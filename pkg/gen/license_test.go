@@ -0,0 +1,46 @@
+// Copyright 2020 oncilla
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gen_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oncilla/boa/pkg/gen"
+)
+
+func TestLicenseText(t *testing.T) {
+	for _, name := range []string{"agpl", "apache", "freebsd", "bsd", "gpl2", "gpl3", "lgpl", "mit"} {
+		t.Run(name, func(t *testing.T) {
+			license, err := gen.FindLicense(name)
+			require.NoError(t, err)
+			p := gen.Project{
+				Copyright: gen.Copyright{Year: 2020, Author: "my-name"},
+				License:   license,
+			}
+			text, err := p.LicenseText()
+			require.NoError(t, err)
+			assert.True(t, strings.HasPrefix(text, "Copyright (c) 2020 my-name\n") ||
+				strings.Contains(text, "\nCopyright (c) 2020 my-name\n"), text)
+			assert.NotContains(t, text, "{{")
+			if !strings.Contains(license.Text, "{{ .Copyright }}") {
+				// Texts without a copyright slot must be kept verbatim.
+				assert.True(t, strings.HasSuffix(text, "\n\n"+strings.Trim(license.Text, "\n")+"\n"))
+			}
+		})
+	}
+}
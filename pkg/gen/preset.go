@@ -0,0 +1,132 @@
+// Copyright 2020 oncilla
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/oncilla/boa/pkg/tmpl"
+)
+
+// Preset describes the layout and the additional files of a project.
+type Preset struct {
+	Name        string
+	Description string
+	// Main is the directory relative to the project root that contains the
+	// main package directories, e.g., cmd. If empty, the main package is
+	// located in the project root.
+	Main string
+	// Commands are the constructors that are registered with the root command
+	// in addition to newCompletion and newVersion.
+	Commands []string
+	// Templates are added to the built-in templates, or override them.
+	Templates tmpl.Templates
+	// Shared are the names of the templates that are shared by all binaries
+	// in the project. Their files are kept if they already exist.
+	Shared []string
+	// Requires are the requirements of the go.mod file in addition to
+	// cobra and boa, e.g., "github.com/spf13/viper v1.4.0".
+	Requires []string
+	// Module indicates that the templates import packages from the project
+	// module, and thus require the module path.
+	Module bool
+}
+
+// serverRequires are the requirements of the serve command.
+var serverRequires = []string{
+	"github.com/mitchellh/mapstructure v1.1.2",
+	"github.com/spf13/viper v1.4.0",
+}
+
+// Presets are the supported project presets.
+var Presets = []Preset{
+	{
+		Name:        "cli",
+		Description: "command line application with completion and version",
+	},
+	{
+		Name:        "server",
+		Description: "server with a serve command and graceful shutdown",
+		Commands:    []string{"newServe"},
+		Requires:    serverRequires,
+		Templates: tmpl.Templates{
+			"serve.go.tmpl":      tmpl.Serve,
+			"serve_test.go.tmpl": tmpl.ServeTest,
+		},
+	},
+	{
+		Name:        "daemon",
+		Description: "server with pidfile and log file handling",
+		Commands:    []string{"newServe"},
+		Requires:    serverRequires,
+		Templates: tmpl.Templates{
+			"daemon.go.tmpl":     tmpl.Daemon,
+			"serve.go.tmpl":      tmpl.Serve,
+			"serve_test.go.tmpl": tmpl.ServeTest,
+		},
+	},
+	{
+		Name:        "multi",
+		Description: "cmd/<app> layout with a shared internal package",
+		Main:        "cmd",
+		Templates: tmpl.Templates{
+			tmpl.VersionName:                   tmpl.MultiVersion,
+			"internal/version/version.go.tmpl": tmpl.SharedVersion,
+		},
+		Shared: []string{"internal/version/version.go.tmpl"},
+		Module: true,
+	},
+}
+
+// PresetNames returns the names of the supported presets.
+func PresetNames() []string {
+	var names []string
+	for _, p := range Presets {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+// FindPreset finds the preset by name from the supported set. The empty name
+// refers to the cli preset.
+func FindPreset(name string) (Preset, error) {
+	if name == "" {
+		name = "cli"
+	}
+	for _, p := range Presets {
+		if strings.EqualFold(p.Name, name) {
+			return p, nil
+		}
+	}
+	return Preset{}, fmt.Errorf("preset not found: %s", name)
+}
+
+// Builtin returns the built-in templates extended by the preset templates.
+func (p Preset) Builtin() tmpl.Templates {
+	t := tmpl.Builtin()
+	for name, text := range p.Templates {
+		t[name] = text
+	}
+	return t
+}
+
+func (p Preset) shared(name string) bool {
+	for _, s := range p.Shared {
+		if s == name {
+			return true
+		}
+	}
+	return false
+}
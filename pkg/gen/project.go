@@ -14,11 +14,17 @@
 package gen
 
 import (
+	"bytes"
 	"fmt"
+	"io/ioutil"
 	"os"
-	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
 	"text/template"
 
+	"github.com/oncilla/boa/pkg/source"
 	"github.com/oncilla/boa/pkg/tmpl"
 	"golang.org/x/xerrors"
 )
@@ -28,50 +34,232 @@ type Project struct {
 	Name      string
 	Copyright Copyright
 	License   License
+	// Vars are custom variables available to the templates.
+	Vars map[string]string
+	// Templates are the templates used for rendering. If nil, the built-in
+	// templates of the preset are used.
+	Templates tmpl.Templates
+	// Preset is the project preset. The zero value is the cli preset.
+	Preset Preset
+	// Module is the module path of the project root. It is required by
+	// presets that import packages from the project module, and by the go.mod
+	// file.
+	Module string
+	// Scaffold are the names of the scaffolding templates that are rendered
+	// in the project root, e.g., tmpl.MakefileName. Existing files are kept.
+	Scaffold []string
+	// Boa is the version of the boa module that is required by the go.mod
+	// file, e.g., v0.0.0-20200601120000-0123456789ab. The generated code
+	// imports boa packages. If empty, boa is not pinned and must be added
+	// with go get.
+	Boa string
 }
 
-// Create writes the templated project and formats it using 'gofmt'.
-func (p Project) Create(path string) error {
-	if err := os.MkdirAll(path, 0755); err != nil {
-		return err
+// cobraRequire is the cobra requirement of the go.mod file.
+const cobraRequire = "github.com/spf13/cobra v1.0.0"
+
+// BoaModule is the module path of boa.
+const BoaModule = "github.com/oncilla/boa"
+
+// MainDir returns the directory of the main package relative to the project
+// root.
+func (p Project) MainDir() string {
+	if p.Preset.Main == "" {
+		return "."
 	}
+	return path.Join(p.Preset.Main, p.Name)
+}
+
+// Commands returns the sorted constructors that are registered with the root
+// command.
+func (p Project) Commands() []string {
+	commands := append([]string{"newCompletion", "newVersion"}, p.Preset.Commands...)
+	sort.Strings(commands)
+	return commands
+}
+
+// EnvPrefix returns the prefix for environment variables derived from the
+// application name, see EnvPrefix.
+func (p Project) EnvPrefix() string {
+	return EnvPrefix(p.Name)
+}
 
-	for name, tempStr := range map[string]string{
-		fmt.Sprintf("%s/%s.go", path, p.Name): tmpl.Root,
-		fmt.Sprintf("%s/completion.go", path): tmpl.Completion,
-		fmt.Sprintf("%s/version.go", path):    tmpl.Version,
-	} {
-		if err := notExists(name); err != nil {
-			return err
+// EnvPrefix returns the prefix for environment variables of the application
+// with the given name. It is the upper case name with all non-alphanumeric
+// characters replaced by underscores.
+func EnvPrefix(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' {
+			return r - 'a' + 'A'
+		}
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
 		}
-		file, err := os.Create(name)
+		return '_'
+	}, name)
+}
+
+// Requires returns the sorted requirements of the go.mod file.
+func (p Project) Requires() []string {
+	requires := append([]string{cobraRequire}, p.Preset.Requires...)
+	if p.Boa != "" {
+		requires = append(requires, BoaModule+" "+p.Boa)
+	}
+	sort.Strings(requires)
+	return requires
+}
+
+// copyrightSlot is the placeholder for the copyright notice in license texts.
+const copyrightSlot = "{{ .Copyright }}"
+
+// LicenseText returns the full text of the license with the copyright
+// notice filled in. Licenses without a copyright slot, e.g., the GPL, are
+// kept verbatim and the notice is put above the text.
+func (p Project) LicenseText() (string, error) {
+	notice := fmt.Sprintf("Copyright (c) %d", p.Copyright.Year)
+	if p.Copyright.Author != "" {
+		notice += " " + p.Copyright.Author
+	}
+	text := p.License.Text
+	if !strings.Contains(text, copyrightSlot) {
+		text = copyrightSlot + "\n\n" + strings.TrimLeft(text, "\n")
+	}
+	t, err := template.New("license").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var buf strings.Builder
+	if err := t.Execute(&buf, struct{ Copyright string }{notice}); err != nil {
+		return "", err
+	}
+	return strings.Trim(buf.String(), "\n") + "\n", nil
+}
+
+// Files returns the files that are rendered by Create, mapped to the names
+// of the templates they are rendered from. The files of the built-in
+// templates are located in the main package directory, all other files are
+// relative to the project root.
+func (p Project) Files() map[string]string {
+	main := filepath.FromSlash(p.MainDir())
+	files := map[string]string{
+		filepath.Join(main, p.Name+".go"):    tmpl.RootName,
+		filepath.Join(main, "completion.go"): tmpl.CompletionName,
+		filepath.Join(main, "version.go"):    tmpl.VersionName,
+	}
+	for _, name := range p.Scaffold {
+		if name == tmpl.LicenseName && p.License.Text == "" {
+			continue
+		}
+		files[tmpl.FileName(name)] = name
+	}
+	for _, name := range p.templates().Extra() {
+		files[filepath.FromSlash(tmpl.FileName(name))] = name
+	}
+	return files
+}
+
+// Render renders the project with the root at path and returns the files as
+// changes against the files on disk. Go files are formatted. Scaffolding files
+// and files that are shared by all binaries of the preset are omitted if they
+// already exist.
+func (p Project) Render(path string) ([]source.Change, error) {
+	if p.Preset.Module && p.Module == "" {
+		return nil, fmt.Errorf("preset %s requires the module path", p.Preset.Name)
+	}
+	if p.scaffold(tmpl.GoModName) && p.Module == "" {
+		return nil, fmt.Errorf("go.mod requires the module path")
+	}
+	templates := p.templates()
+	files := p.Files()
+	var names []string
+	for file := range files {
+		names = append(names, file)
+	}
+	sort.Strings(names)
+	var changes []source.Change
+	for _, file := range names {
+		name := files[file]
+		full := filepath.Join(path, file)
+		old, err := readFile(full)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		t := template.Must(template.New("").Parse(tempStr))
-		if err := t.Execute(file, p); err != nil {
-			return err
+		if old != nil && (p.Preset.shared(name) || p.scaffold(name)) {
+			continue
 		}
-		if err := file.Close(); err != nil {
-			return err
+		raw, err := render(full, name, templates[name], p.Module, p)
+		if err != nil {
+			return nil, err
 		}
+		changes = append(changes, source.Change{Path: full, Old: old, New: raw})
 	}
+	return changes, nil
+}
 
-	cmd := exec.Command("gofmt", "-w", path)
-	cmd.Stderr = os.Stderr
-	if err := cmd.Run(); err != nil {
+// Create writes the rendered project to the project root at path. Existing
+// files are handled according to the policy. Either all files are written, or
+// none of them.
+func (p Project) Create(path string, policy source.Policy) error {
+	changes, err := p.Render(path)
+	if err != nil {
 		return err
 	}
-	return nil
+	return create(changes, policy)
 }
 
-func notExists(file string) error {
-	_, err := os.Stat(file)
-	if xerrors.Is(err, os.ErrNotExist) {
-		return nil
+func (p Project) scaffold(name string) bool {
+	for _, s := range p.Scaffold {
+		if s == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (p Project) templates() tmpl.Templates {
+	if p.Templates == nil {
+		return p.Preset.Builtin()
+	}
+	return p.Templates
+}
+
+// render executes the template for the file. Go files are formatted and their
+// imports are pruned and grouped, with the imports of the local module in a
+// separate group.
+func render(file, name, text, local string, data interface{}) ([]byte, error) {
+	t, err := template.New(name).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("executing template %s: %s", name, err)
 	}
+	if filepath.Ext(file) != ".go" {
+		return buf.Bytes(), nil
+	}
+	raw, err := source.Format(buf.Bytes(), local)
+	if err != nil {
+		return nil, fmt.Errorf("formatting %s: %s", file, err)
+	}
+	return raw, nil
+}
+
+// create resolves the changes according to the policy and writes them to
+// disk.
+func create(changes []source.Change, policy source.Policy) error {
+	resolved, err := policy.Resolve(changes)
 	if err != nil {
 		return err
 	}
-	return fmt.Errorf("file already exists: %s", file)
+	return source.Apply(resolved)
+}
+
+// readFile reads the file. It returns nil if the file does not exist.
+func readFile(file string) ([]byte, error) {
+	raw, err := ioutil.ReadFile(file)
+	if xerrors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return raw, err
 }
@@ -0,0 +1,661 @@
+// Copyright 2020 oncilla
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gen
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"go/token"
+	"math/big"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// SpecError indicates a malformed flag spec. It points at the offending
+// position in the input.
+type SpecError struct {
+	Input string
+	// Offset is the byte offset of the offending position in the input.
+	Offset int
+	Msg    string
+}
+
+func (e *SpecError) Error() string {
+	col := utf8.RuneCountInString(e.Input[:e.Offset])
+	return fmt.Sprintf("invalid flag spec at column %d: %s\n  %s\n  %s^",
+		col+1, e.Msg, e.Input, strings.Repeat(" ", col))
+}
+
+// specScanner scans the compact and long flag notations.
+type specScanner struct {
+	input string
+	pos   int
+}
+
+func (s *specScanner) errorf(pos int, format string, a ...interface{}) error {
+	return &SpecError{Input: s.input, Offset: pos, Msg: fmt.Sprintf(format, a...)}
+}
+
+func (s *specScanner) eof() bool {
+	return s.pos >= len(s.input)
+}
+
+func (s *specScanner) peek() byte {
+	if s.eof() {
+		return 0
+	}
+	return s.input[s.pos]
+}
+
+// until scans until one of the stop characters or the end of the input.
+func (s *specScanner) until(stop string) string {
+	start := s.pos
+	for !s.eof() && !strings.ContainsRune(stop, rune(s.peek())) {
+		s.pos++
+	}
+	return s.input[start:s.pos]
+}
+
+// skipSpace skips spaces and tabs. It reports whether any were skipped.
+func (s *specScanner) skipSpace() bool {
+	start := s.pos
+	for s.peek() == ' ' || s.peek() == '\t' {
+		s.pos++
+	}
+	return s.pos > start
+}
+
+// ident scans an identifier made of letters, digits and underscores.
+func (s *specScanner) ident() string {
+	start := s.pos
+	for !s.eof() {
+		c := s.peek()
+		if c != '_' && !isAlnum(c) {
+			break
+		}
+		s.pos++
+	}
+	return s.input[start:s.pos]
+}
+
+// value scans a quoted or bare value. Quoted values follow the Go syntax for
+// interpreted and raw string literals. Bare values end at one of the stop
+// characters.
+func (s *specScanner) value(stop string) (string, error) {
+	quote := s.peek()
+	if quote != '"' && quote != '`' {
+		return s.until(stop), nil
+	}
+	start := s.pos
+	for s.pos++; !s.eof() && s.peek() != quote; s.pos++ {
+		if quote == '"' && s.peek() == '\\' {
+			s.pos++
+		}
+	}
+	if s.eof() {
+		return "", s.errorf(start, "unterminated quoted value")
+	}
+	s.pos++
+	v, err := strconv.Unquote(s.input[start:s.pos])
+	if err != nil {
+		return "", s.errorf(start, "invalid quoted value: %s", err)
+	}
+	return v, nil
+}
+
+// head scans the 'name:type' part that both notations start with.
+func (s *specScanner) head() (Flag, error) {
+	start := s.pos
+	name := s.ident()
+	switch {
+	case name == "":
+		return Flag{}, s.errorf(start, "expected flag name")
+	case !token.IsIdentifier(name):
+		return Flag{}, s.errorf(start, "flag name %q is not a valid identifier", name)
+	case s.peek() != ':':
+		return Flag{}, s.errorf(s.pos, "expected ':' after flag name")
+	}
+	s.pos++
+	typeStart := s.pos
+	if strings.HasPrefix(s.input[s.pos:], "enum(") {
+		s.until(")")
+		if s.eof() {
+			return Flag{}, s.errorf(typeStart, "unterminated enum")
+		}
+		s.pos++
+		return parseEnum(s, name, typeStart)
+	}
+	if strings.HasPrefix(s.input[s.pos:], "[]") {
+		s.pos += 2
+	}
+	s.until("=,!([ \t")
+	typ := s.input[typeStart:s.pos]
+	if typ == "" {
+		return Flag{}, s.errorf(typeStart, "expected flag type")
+	}
+	for flagType, v := range supportedFlag {
+		if !strings.EqualFold(flagType, typ) {
+			continue
+		}
+		f := Flag{
+			Name:     name,
+			Type:     v.Type,
+			Default:  v.Default,
+			Register: v.Register,
+			Usage:    name + " description",
+			Import:   v.Import,
+		}
+		var err error
+		switch s.peek() {
+		case '[':
+			err = s.setRange(&f)
+		case '(':
+			err = s.setConstraint(&f, flagType)
+		}
+		return f, err
+	}
+	return Flag{}, s.errorf(typeStart, "unsupported flag type: %s", typ)
+}
+
+// newFlag creates the flag with the name, the type including its constraint,
+// e.g., 'uint16[1..]', and the unquoted default. If the default is empty, the
+// default of the type is kept.
+func newFlag(name, typ, def string) (Flag, error) {
+	s := &specScanner{input: name + ":" + typ}
+	f, err := s.head()
+	if err != nil {
+		return Flag{}, err
+	}
+	if !s.eof() {
+		return Flag{}, s.errorf(s.pos, "unexpected %q after flag type", s.peek())
+	}
+	if def == "" {
+		return f, nil
+	}
+	if f.Default, err = defaultValue(f, def); err != nil {
+		return Flag{}, fmt.Errorf("invalid default for %s: %s", name, err)
+	}
+	if err := f.check(def); err != nil {
+		return Flag{}, fmt.Errorf("invalid default for %s: %s", name, err)
+	}
+	return f, nil
+}
+
+// setRange scans the range constraint, e.g., '[1..65535]', and sets it on the
+// numeric or duration flag. Either bound can be omitted.
+func (s *specScanner) setRange(f *Flag) error {
+	start := s.pos
+	s.pos++
+	body := s.until("]")
+	if s.eof() {
+		return s.errorf(start, "unterminated range")
+	}
+	s.pos++
+	if !isNumeric(f.Type) {
+		return s.errorf(start, "range constraints require a numeric or duration flag")
+	}
+	parts := strings.SplitN(body, "..", 2)
+	if len(parts) != 2 || body == ".." {
+		return s.errorf(start+1, "range must have the form min..max")
+	}
+	min, max := parts[0], parts[1]
+	var err error
+	if min != "" {
+		if f.Min, err = literal(f.Type, min); err != nil {
+			return s.errorf(start+1, "invalid range minimum: %s", err)
+		}
+	}
+	if max != "" {
+		if f.Max, err = literal(f.Type, max); err != nil {
+			return s.errorf(start+len(min)+3, "invalid range maximum: %s", err)
+		}
+	}
+	if min != "" && max != "" {
+		lower, _ := number(f.Type, min)
+		upper, _ := number(f.Type, max)
+		if lower.Cmp(upper) > 0 {
+			return s.errorf(start+1, "range minimum %s is greater than maximum %s", min, max)
+		}
+	}
+	// Bounds at the limits of integer types are always satisfied.
+	if lower, upper, ok := limits(f.Type); ok {
+		if f.Min == lower {
+			f.Min = ""
+		}
+		if f.Max == upper {
+			f.Max = ""
+		}
+	}
+	f.Range = body
+	return nil
+}
+
+// setConstraint scans the constraint in parentheses, i.e., 'path(exists)'
+// or 'string(regexp=<pattern>)', and sets it on the flag.
+func (s *specScanner) setConstraint(f *Flag, flagType string) error {
+	start := s.pos
+	s.pos++
+	switch flagType {
+	case "path":
+		constraint := s.ident()
+		switch constraint {
+		case "exists", "file", "dir":
+			f.Path = constraint
+		default:
+			return s.errorf(start+1, "unknown path constraint %q, expected exists, file or dir", constraint)
+		}
+	case "string":
+		if key := s.ident(); key != "regexp" {
+			return s.errorf(start+1, "unknown string constraint %q, expected regexp=<pattern>", key)
+		}
+		if s.peek() != '=' {
+			return s.errorf(s.pos, "expected '=' after regexp")
+		}
+		s.pos++
+		valueStart := s.pos
+		pattern, err := s.value(")")
+		if err != nil {
+			return err
+		}
+		if _, err := regexp.Compile(pattern); err != nil {
+			return s.errorf(valueStart, "invalid regexp: %s", err)
+		}
+		f.Pattern = pattern
+	default:
+		return s.errorf(start, "constraints in parentheses require a path or string flag")
+	}
+	if s.eof() {
+		return s.errorf(start, "unterminated constraint")
+	}
+	if s.peek() != ')' {
+		return s.errorf(s.pos, "expected ')'")
+	}
+	s.pos++
+	return nil
+}
+
+// parseEnum parses the enum type description, e.g., 'enum(fast|safe)', that
+// starts at offset start. The first value is the default.
+func parseEnum(s *specScanner, name string, start int) (Flag, error) {
+	offset := start + len("enum(")
+	values := strings.Split(s.input[offset:s.pos-1], "|")
+	for _, v := range values {
+		if v == "" {
+			return Flag{}, s.errorf(offset, "empty enum value")
+		}
+		offset += len(v) + 1
+	}
+	f := Flag{
+		Name:     name,
+		Type:     "*flag.Enum",
+		Register: "Var",
+		Values:   values,
+		Usage:    name + " description",
+		Import:   boaFlagImport,
+	}
+	f.Default, _ = defaultValue(f, values[0])
+	return f, nil
+}
+
+// compact scans a flag in the compact notation. It stops at the end of the
+// input, or at the comma that separates it from the next flag.
+func (s *specScanner) compact() (Flag, error) {
+	start := s.pos
+	f, err := s.head()
+	if err != nil {
+		return Flag{}, err
+	}
+	explicit := s.peek() == '='
+	if explicit {
+		s.pos++
+		if err := s.setDefault(&f, ",!"); err != nil {
+			return Flag{}, err
+		}
+	}
+	if s.peek() == ',' && s.shorthand() {
+		s.pos++
+		if err := s.setShort(&f, s.input[s.pos:s.pos+1], s.pos); err != nil {
+			return Flag{}, err
+		}
+		s.pos++
+	}
+	if s.peek() == '!' {
+		f.Required = true
+		s.pos++
+	}
+	if !s.eof() && s.peek() != ',' {
+		return Flag{}, s.errorf(s.pos, "unexpected %q", s.peek())
+	}
+	if !explicit {
+		if err := f.checkZero(); err != nil {
+			return Flag{}, s.errorf(start, "%s", err)
+		}
+	}
+	return f, nil
+}
+
+// shorthand reports whether the comma at the current position is followed by
+// a shorthand instead of the next flag.
+func (s *specScanner) shorthand() bool {
+	rest := s.input[s.pos+1:]
+	if len(rest) == 0 || !isAlnum(rest[0]) {
+		return false
+	}
+	return len(rest) == 1 || rest[1] == ',' || rest[1] == '!'
+}
+
+// long scans a flag in the long notation.
+func (s *specScanner) long() (Flag, error) {
+	s.skipSpace()
+	start := s.pos
+	f, err := s.head()
+	if err != nil {
+		return Flag{}, err
+	}
+	seen := map[string]bool{}
+	for {
+		if !s.skipSpace() && !s.eof() {
+			return Flag{}, s.errorf(s.pos, "unexpected %q", s.peek())
+		}
+		if s.eof() {
+			if !seen["default"] {
+				if err := f.checkZero(); err != nil {
+					return Flag{}, s.errorf(start, "%s", err)
+				}
+			}
+			return f, nil
+		}
+		start := s.pos
+		key := s.ident()
+		if seen[key] {
+			return Flag{}, s.errorf(start, "duplicate attribute %s", key)
+		}
+		seen[key] = true
+		if key == "required" && s.peek() != '=' {
+			f.Required = true
+			continue
+		}
+		if s.peek() != '=' {
+			return Flag{}, s.errorf(s.pos, "expected '=' after attribute %s", key)
+		}
+		s.pos++
+		switch key {
+		case "default":
+			err = s.setDefault(&f, " \t")
+		case "short":
+			valueStart := s.pos
+			var short string
+			if short, err = s.value(" \t"); err == nil {
+				err = s.setShort(&f, short, valueStart)
+			}
+		case "usage":
+			f.Usage, err = s.value(" \t")
+		case "required":
+			valueStart := s.pos
+			var required string
+			if required, err = s.value(" \t"); err == nil {
+				if f.Required, err = strconv.ParseBool(required); err != nil {
+					err = s.errorf(valueStart, "invalid value for required: %s", required)
+				}
+			}
+		default:
+			return Flag{}, s.errorf(start,
+				"unknown attribute %q, expected default, short, usage or required", key)
+		}
+		if err != nil {
+			return Flag{}, err
+		}
+	}
+}
+
+// setDefault scans the default value and sets it on the flag.
+func (s *specScanner) setDefault(f *Flag, stop string) error {
+	start := s.pos
+	v, err := s.value(stop)
+	if err != nil {
+		return err
+	}
+	if f.Default, err = defaultValue(*f, v); err != nil {
+		return s.errorf(start, "invalid default for %s: %s", f.Name, err)
+	}
+	if err := f.check(v); err != nil {
+		return s.errorf(start, "invalid default for %s: %s", f.Name, err)
+	}
+	return nil
+}
+
+// setShort sets the shorthand that starts at offset pos on the flag.
+func (s *specScanner) setShort(f *Flag, short string, pos int) error {
+	switch {
+	case len(short) != 1 || !isAlnum(short[0]):
+		return s.errorf(pos, "shorthand must be a single letter or digit, got %q", short)
+	case short == "h":
+		return s.errorf(pos, "shorthand h is reserved for the help flag")
+	}
+	f.Short = short
+	return nil
+}
+
+// check checks that the value satisfies the range and pattern constraints of
+// the flag. Path constraints are only checked by the generated code.
+func (f Flag) check(v string) error {
+	if min, max := f.bounds(); min != "" || max != "" {
+		value, err := number(f.Type, v)
+		if err != nil {
+			return err
+		}
+		if lower, _ := number(f.Type, min); min != "" && value.Cmp(lower) < 0 {
+			return fmt.Errorf("%s is not in range [%s]", v, f.Range)
+		}
+		if upper, _ := number(f.Type, max); max != "" && value.Cmp(upper) > 0 {
+			return fmt.Errorf("%s is not in range [%s]", v, f.Range)
+		}
+	}
+	if f.Pattern != "" && !regexp.MustCompile(f.Pattern).MatchString(v) {
+		return fmt.Errorf("%q does not match %s", v, f.Pattern)
+	}
+	return nil
+}
+
+// checkZero checks that the zero value, which is the default if none is
+// specified, satisfies the range and pattern constraints of the flag. The
+// generated code only checks values that are set explicitly. Required flags
+// are always set explicitly.
+func (f Flag) checkZero() error {
+	if f.Required || (f.Range == "" && f.Pattern == "") {
+		return nil
+	}
+	zero := ""
+	if isNumeric(f.Type) {
+		zero = "0"
+	}
+	if err := f.check(zero); err != nil {
+		return fmt.Errorf("zero default of %s: %s, set a default or mark the flag required",
+			f.Name, err)
+	}
+	return nil
+}
+
+func isAlnum(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// defaultValue returns the Go expression for the default value of the flag.
+func defaultValue(f Flag, v string) (string, error) {
+	if len(f.Values) > 0 {
+		for _, allowed := range f.Values {
+			if v == allowed {
+				quoted := make([]string, 0, len(f.Values)+1)
+				for _, value := range append([]string{v}, f.Values...) {
+					quoted = append(quoted, strconv.Quote(value))
+				}
+				return fmt.Sprintf("flag.NewEnum(%s)", strings.Join(quoted, ", ")), nil
+			}
+		}
+		return "", fmt.Errorf("%q is not one of %s", v, strings.Join(f.Values, ", "))
+	}
+	switch f.Register {
+	case "BytesBase64Var":
+		raw, err := base64.StdEncoding.DecodeString(v)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("[]byte(%q)", raw), nil
+	case "BytesHexVar":
+		raw, err := hex.DecodeString(v)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("[]byte(%q)", raw), nil
+	}
+	if !strings.HasPrefix(f.Type, "[]") {
+		return literal(f.Type, v)
+	}
+	if v == "" {
+		return "nil", nil
+	}
+	var elems []string
+	for _, elem := range strings.Split(v, ",") {
+		l, err := literal(strings.TrimPrefix(f.Type, "[]"), elem)
+		if err != nil {
+			return "", err
+		}
+		elems = append(elems, l)
+	}
+	return fmt.Sprintf("%s{%s}", f.Type, strings.Join(elems, ", ")), nil
+}
+
+// literal returns the Go expression for the value of the Go type.
+func literal(typ, v string) (string, error) {
+	switch {
+	case typ == "string":
+		return strconv.Quote(v), nil
+	case typ == "bool":
+		b, err := strconv.ParseBool(v)
+		return strconv.FormatBool(b), err
+	case typ == "time.Duration":
+		d, err := time.ParseDuration(v)
+		return durationLiteral(d), err
+	case typ == "net.IP":
+		if net.ParseIP(v) == nil {
+			return "", fmt.Errorf("invalid IP address %q", v)
+		}
+		return fmt.Sprintf("net.ParseIP(%q)", v), nil
+	case strings.HasPrefix(typ, "float"):
+		bits, _ := strconv.Atoi(strings.TrimPrefix(typ, "float"))
+		f, err := strconv.ParseFloat(v, bits)
+		return strconv.FormatFloat(f, 'g', -1, bits), stripNum(err)
+	case strings.HasPrefix(typ, "uint"):
+		bits, _ := strconv.Atoi(strings.TrimPrefix(typ, "uint"))
+		u, err := strconv.ParseUint(v, 0, bits)
+		return strconv.FormatUint(u, 10), stripNum(err)
+	default:
+		bits, _ := strconv.Atoi(strings.TrimPrefix(typ, "int"))
+		i, err := strconv.ParseInt(v, 0, bits)
+		return strconv.FormatInt(i, 10), stripNum(err)
+	}
+}
+
+// isNumeric reports whether the Go type supports range constraints.
+func isNumeric(typ string) bool {
+	for _, prefix := range []string{"int", "uint", "float"} {
+		if strings.HasPrefix(typ, prefix) {
+			return true
+		}
+	}
+	return typ == "time.Duration"
+}
+
+// limits returns the smallest and largest value of the integer type. Types
+// without explicit size only report the lower limit of unsigned integers.
+func limits(typ string) (string, string, bool) {
+	switch {
+	case typ == "uint":
+		return "0", "", true
+	case strings.HasPrefix(typ, "uint"):
+		bits, _ := strconv.Atoi(strings.TrimPrefix(typ, "uint"))
+		return "0", strconv.FormatUint(1<<uint(bits)-1, 10), true
+	case typ == "int":
+		return "", "", false
+	case strings.HasPrefix(typ, "int"):
+		bits, _ := strconv.Atoi(strings.TrimPrefix(typ, "int"))
+		max := int64(1<<uint(bits-1) - 1)
+		return strconv.FormatInt(-max-1, 10), strconv.FormatInt(max, 10), true
+	}
+	return "", "", false
+}
+
+// number parses the value of the numeric Go type for comparison.
+func number(typ, v string) (*big.Rat, error) {
+	switch {
+	case typ == "time.Duration":
+		d, err := time.ParseDuration(v)
+		return new(big.Rat).SetInt64(int64(d)), err
+	case strings.HasPrefix(typ, "float"):
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, stripNum(err)
+		}
+		return new(big.Rat).SetFloat64(f), nil
+	case strings.HasPrefix(typ, "uint"):
+		u, err := strconv.ParseUint(v, 0, 64)
+		return new(big.Rat).SetInt(new(big.Int).SetUint64(u)), stripNum(err)
+	default:
+		i, err := strconv.ParseInt(v, 0, 64)
+		return new(big.Rat).SetInt64(i), stripNum(err)
+	}
+}
+
+// stripNum removes the function name from strconv errors.
+func stripNum(err error) error {
+	if numErr, ok := err.(*strconv.NumError); ok {
+		return fmt.Errorf("%q: %s", numErr.Num, numErr.Err)
+	}
+	return err
+}
+
+var durationUnits = []struct {
+	unit time.Duration
+	name string
+}{
+	{time.Hour, "time.Hour"},
+	{time.Minute, "time.Minute"},
+	{time.Second, "time.Second"},
+	{time.Millisecond, "time.Millisecond"},
+	{time.Microsecond, "time.Microsecond"},
+	{time.Nanosecond, "time.Nanosecond"},
+}
+
+// durationLiteral returns the Go expression for the duration in the largest
+// unit that represents it exactly, e.g., 90 * time.Second.
+func durationLiteral(d time.Duration) string {
+	if d == 0 {
+		return "0"
+	}
+	for _, u := range durationUnits {
+		if d%u.unit != 0 {
+			continue
+		}
+		if d == u.unit {
+			return u.name
+		}
+		return fmt.Sprintf("%d * %s", d/u.unit, u.name)
+	}
+	return strconv.FormatInt(int64(d), 10)
+}
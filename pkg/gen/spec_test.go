@@ -0,0 +1,288 @@
+// Copyright 2020 oncilla
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gen_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oncilla/boa/pkg/gen"
+)
+
+func TestParseFlags(t *testing.T) {
+	testCases := map[string]struct {
+		Compact  []string
+		Long     []string
+		Expected []gen.Flag
+		Imports  []string
+	}{
+		"compact": {
+			Compact: []string{`name:string=alice,n!,tags:[]string="a,b",addr:ip`},
+			Expected: []gen.Flag{
+				{
+					Name:     "name",
+					Type:     "string",
+					Register: "StringVar",
+					Default:  `"alice"`,
+					Short:    "n",
+					Usage:    "name description",
+					Required: true,
+				},
+				{
+					Name:     "tags",
+					Type:     "[]string",
+					Register: "StringSliceVar",
+					Default:  `[]string{"a", "b"}`,
+					Usage:    "tags description",
+				},
+				{
+					Name:     "addr",
+					Type:     "net.IP",
+					Register: "IPVar",
+					Default:  "nil",
+					Usage:    "addr description",
+					Import:   "net",
+				},
+			},
+			Imports: []string{"net"},
+		},
+		"long": {
+			Long: []string{
+				`name:string default=alice short=n required usage="who to greet"`,
+				`mode:enum(fast|safe) default=safe required=false`,
+			},
+			Expected: []gen.Flag{
+				{
+					Name:     "name",
+					Type:     "string",
+					Register: "StringVar",
+					Default:  `"alice"`,
+					Short:    "n",
+					Usage:    "who to greet",
+					Required: true,
+				},
+				{
+					Name:     "mode",
+					Type:     "*flag.Enum",
+					Register: "Var",
+					Default:  `flag.NewEnum("safe", "fast", "safe")`,
+					Values:   []string{"fast", "safe"},
+					Usage:    "mode description",
+					Import:   "github.com/oncilla/boa/pkg/boa/flag",
+				},
+			},
+			Imports: []string{"github.com/oncilla/boa/pkg/boa/flag"},
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			flags, imports, err := gen.ParseFlags(tc.Compact, tc.Long)
+			require.NoError(t, err)
+			assert.Equal(t, tc.Expected, flags)
+			assert.Equal(t, tc.Imports, imports)
+		})
+	}
+}
+
+func TestParseFlagConstraints(t *testing.T) {
+	testCases := map[string]struct {
+		Input      string
+		OutOfRange string
+		Pattern    string
+		Path       string
+		Sample     string
+		SampleArg  string
+	}{
+		"range": {
+			Input:      "port:uint16[1..65535]!",
+			OutOfRange: "flags.port < 1",
+			Sample:     "1",
+			SampleArg:  `"--port=1"`,
+		},
+		"duration range": {
+			Input:      "timeout:duration[1s..1m]=30s",
+			OutOfRange: "(flags.timeout < time.Second || flags.timeout > time.Minute)",
+			Sample:     "1s",
+			SampleArg:  `"--timeout=1s"`,
+		},
+		"open range": {
+			Input:      "ratio:float64[..1.5]",
+			OutOfRange: "flags.ratio > 1.5",
+			Sample:     "1.5",
+			SampleArg:  `"--ratio=1.5"`,
+		},
+		"regexp": {
+			Input:     "url:string(regexp=^https://)=https://example.com",
+			Pattern:   "^https://",
+			Sample:    "https://value",
+			SampleArg: `"--url=https://value"`,
+		},
+		"quoted regexp": {
+			Input:     "v:string(regexp=`^v[0-9]+(\\.[0-9]+)?$`)!",
+			Pattern:   `^v[0-9]+(\.[0-9]+)?$`,
+			Sample:    "v1",
+			SampleArg: `"--v=v1"`,
+		},
+		"existing path": {
+			Input:     "file:path(exists)",
+			Path:      "exists",
+			Sample:    ".",
+			SampleArg: `"--file=."`,
+		},
+		"existing file": {
+			Input:     "file:path(file)",
+			Path:      "file",
+			SampleArg: `"--file=" + os.Args[0]`,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			f, err := gen.ParseFlag(tc.Input)
+			require.NoError(t, err)
+			assert.Equal(t, tc.OutOfRange, f.OutOfRange())
+			assert.Equal(t, tc.Pattern, f.Pattern)
+			assert.Equal(t, tc.Path, f.Path)
+			assert.Equal(t, tc.Sample, f.Sample())
+			assert.Equal(t, tc.SampleArg, f.SampleArg())
+		})
+	}
+}
+
+func TestParseFlagDefaults(t *testing.T) {
+	testCases := map[string]string{
+		"d:duration=90s":       "90 * time.Second",
+		"d:duration=1h":        "time.Hour",
+		"d:duration=0":         "0",
+		"i:int8=0x10":          "16",
+		"u:uint=7":             "7",
+		"f:float32=1.5":        "1.5",
+		"b:bool=t":             "true",
+		"p:ip=::1":             `net.ParseIP("::1")`,
+		"x:hexbytes=626f61":    `[]byte("boa")`,
+		"y:bytes=Ym9h":         `[]byte("boa")`,
+		`s:string="a,b!"`:      `"a,b!"`,
+		`l:[]duration="1s,2m"`: "[]time.Duration{time.Second, 2 * time.Minute}",
+		`l:[]int="1,2"`:        "[]int{1, 2}",
+		`l:[]string=""`:        "nil",
+	}
+	for input, expected := range testCases {
+		t.Run(input, func(t *testing.T) {
+			f, err := gen.ParseFlag(input)
+			require.NoError(t, err)
+			assert.Equal(t, expected, f.Default)
+		})
+	}
+}
+
+func TestParseFlagErrors(t *testing.T) {
+	testCases := map[string]struct {
+		Input  string
+		Long   bool
+		Offset int
+		Msg    string
+	}{
+		"missing type":     {Input: "name", Offset: 4, Msg: "expected ':' after flag name"},
+		"invalid name":     {Input: "1name:int", Offset: 0, Msg: "not a valid identifier"},
+		"unsupported type": {Input: "name:strin=alice", Offset: 5, Msg: "unsupported flag type: strin"},
+		"invalid default":  {Input: "count:uint8=256", Offset: 12, Msg: "invalid default for count"},
+		"enum default":     {Input: "m:enum(a|b)=c", Offset: 12, Msg: `"c" is not one of a, b`},
+		"empty enum value": {Input: "m:enum(a||b)", Offset: 9, Msg: "empty enum value"},
+		"reserved short":   {Input: "a:int,h", Offset: 6, Msg: "reserved for the help flag"},
+		"trailing":         {Input: "a:int!x", Offset: 6, Msg: `unexpected 'x'`},
+		"unterminated":     {Input: `a:string="x`, Offset: 9, Msg: "unterminated quoted value"},
+		"range type":       {Input: "a:string[1..2]", Offset: 8, Msg: "require a numeric or duration flag"},
+		"range form":       {Input: "a:int[1-2]", Offset: 6, Msg: "form min..max"},
+		"range maximum":    {Input: "a:int[1..x]", Offset: 9, Msg: "invalid range maximum"},
+		"range order":      {Input: "a:duration[1m..1s]", Offset: 11, Msg: "greater than maximum"},
+		"range default":    {Input: "a:uint8[1..10]=11", Offset: 15, Msg: "11 is not in range [1..10]"},
+		"path constraint":  {Input: "a:path(missing)", Offset: 7, Msg: "unknown path constraint"},
+		"invalid regexp":   {Input: "a:string(regexp=[)", Offset: 16, Msg: "invalid regexp"},
+		"regexp default":   {Input: "a:string(regexp=^x)=y", Offset: 20, Msg: `"y" does not match ^x`},
+		"constraint type":  {Input: "a:int(exists)", Offset: 5, Msg: "require a path or string flag"},
+		"range zero": {
+			Input: "port:uint16[1..65535]", Offset: 0, Msg: "zero default of port: 0 is not in range",
+		},
+		"regexp zero": {
+			Input: "a:string(regexp=^x)", Offset: 0, Msg: `zero default of a: "" does not match ^x`,
+		},
+		"long range zero": {
+			Input: "port:uint16[1..] short=p", Long: true, Offset: 0, Msg: "mark the flag required",
+		},
+		"unknown attribute": {
+			Input: `name:string usage="x" bogus=1`, Long: true, Offset: 22, Msg: `unknown attribute "bogus"`,
+		},
+		"long short": {
+			Input: "name:string short=ab", Long: true, Offset: 18, Msg: "single letter or digit",
+		},
+		"long required": {
+			Input: "name:string required=maybe", Long: true, Offset: 21, Msg: "invalid value for required",
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			var err error
+			if tc.Long {
+				_, err = gen.ParseFlagSpec(tc.Input)
+			} else {
+				_, err = gen.ParseFlag(tc.Input)
+			}
+			require.Error(t, err)
+			specErr, ok := err.(*gen.SpecError)
+			require.True(t, ok, "unexpected error type %T", err)
+			assert.Equal(t, tc.Offset, specErr.Offset)
+			assert.Contains(t, specErr.Msg, tc.Msg)
+		})
+	}
+}
+
+func TestParseFlagsDuplicates(t *testing.T) {
+	_, _, err := gen.ParseFlags([]string{"a:int"}, []string{"a:string"})
+	assert.EqualError(t, err, "duplicate flag name: a")
+	_, _, err = gen.ParseFlags([]string{"a:int,x,b:int,x"}, nil)
+	assert.EqualError(t, err, "duplicate shorthand x for flags a and b")
+}
+
+func TestFlagConfig(t *testing.T) {
+	flags, _, err := gen.ParseFlags([]string{"maxURLSize:int,shutdown_timeout:duration,mode:enum(a|b)=b"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "MaxURLSize", flags[0].Field())
+	assert.Equal(t, "max-url-size", flags[0].Key())
+	assert.Equal(t, "ShutdownTimeout", flags[1].Field())
+	assert.Equal(t, "shutdown-timeout", flags[1].Key())
+	assert.Equal(t, "string", flags[2].ConfigType())
+	assert.Equal(t, `"b"`, flags[2].ConfigDefault())
+	assert.Equal(t, `mapstructure:"mode" validate:"oneof=a b"`, flags[2].ConfigTag())
+}
+
+func TestFlagConfigErrors(t *testing.T) {
+	testCases := map[string]string{
+		"a:int,x":        "flag a: shorthands are not supported in config structs",
+		"a:int!":         "flag a: required flags are not supported in config structs",
+		"a:[]float64":    "flag a: type []float64 is not supported in config structs",
+		"a:bytes":        "flag a: type []byte is not supported in config structs",
+		"config:string":  "flag config: key config is already used by config",
+		"a_b:int,aB:int": "flag aB: key a-b is already used by a_b",
+	}
+	for input, expected := range testCases {
+		t.Run(input, func(t *testing.T) {
+			flags, _, err := gen.ParseFlags([]string{input}, nil)
+			require.NoError(t, err)
+			c := gen.Command{Name: "serve", Flags: flags, Config: true}
+			_, err = c.Render("serve.go")
+			assert.EqualError(t, err, expected)
+		})
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2020 oncilla
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package options is used to test generating commands from existing types.
+package options
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/oncilla/boa/pkg/boa/flag"
+)
+
+// Level is the verbosity of the logs.
+type Level string
+
+// Options are the options of Run.
+type Options struct {
+	// Addr is the address to listen on.
+	Addr flag.TCPAddr
+	// IP is the address of the upstream server.
+	IP net.IP
+	// ShutdownTimeout limits the time to wait for open connections.
+	ShutdownTimeout time.Duration
+	Level           Level // Level is the log level.
+	// Mode is the mode of operation. Only fast and safe are supported.
+	Mode *flag.Enum
+	// Tags are the tags attached to all requests.
+	Tags []string
+
+	internal bool
+}
+
+// Run does nothing.
+func Run(ctx context.Context, opts Options) error {
+	return ctx.Err()
+}
+
+// Start does nothing.
+func Start(opts *Options) {}
+
+// Invalid has an unsupported signature.
+func Invalid(opts Options) (int, error) {
+	return 0, nil
+}
+
+// Unsupported has an unsupported field.
+type Unsupported struct {
+	Nested struct{ A int }
+}
+
+// Greeting is the result of Greet.
+type Greeting struct {
+	Message string `json:"message"`
+}
+
+// Greet greets the names count times.
+func Greet(ctx context.Context, count int, opts *Options, names ...string) (Greeting, error) {
+	return Greeting{}, ctx.Err()
+}
+
+// Version returns the version.
+func Version() string {
+	return "v1.0.0"
+}
+
+// Split has an unsupported parameter.
+func Split(raw []byte) {}
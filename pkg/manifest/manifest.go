@@ -0,0 +1,188 @@
+// Copyright 2020 oncilla
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package manifest handles the project manifest of boa applications.
+//
+// The manifest is written by 'boa init' and records the choices made when the
+// application was created. All other commands discover the manifest by
+// walking up from the working directory, such that the path to the main
+// package, the author and the license do not need to be provided again.
+package manifest
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// FileName is the name of the manifest file.
+const FileName = ".boa.yaml"
+
+// Manifest records the settings of a boa application.
+type Manifest struct {
+	// Name is the name of the application.
+	Name string `yaml:"name"`
+	// Path is the path to the main package relative to the manifest.
+	Path string `yaml:"path"`
+	// Module is the import path of the main package, if it is part of a
+	// module.
+	Module string `yaml:"module,omitempty"`
+	// Author is the name used for the copyright attribution.
+	Author string `yaml:"author"`
+	// License is the name of the license used for the license header.
+	License string `yaml:"license"`
+	// Preset is the name of the project preset used by 'boa init'.
+	Preset string `yaml:"preset,omitempty"`
+	// Templates is the directory of the project templates relative to the
+	// manifest. If empty, DefaultTemplates is used.
+	Templates string `yaml:"templates,omitempty"`
+	// Vars are custom variables that are available to the templates.
+	Vars map[string]string `yaml:"vars,omitempty"`
+
+	// Dir is the directory that contains the manifest.
+	Dir string `yaml:"-"`
+}
+
+// DefaultTemplates is the default directory of the project templates relative
+// to the manifest.
+const DefaultTemplates = ".boa/templates"
+
+// TemplateDir returns the directory of the project templates.
+func (m *Manifest) TemplateDir() string {
+	dir := m.Templates
+	if dir == "" {
+		dir = DefaultTemplates
+	}
+	return filepath.Join(m.Dir, filepath.FromSlash(dir))
+}
+
+// MainDir returns the directory of the main package.
+func (m *Manifest) MainDir() string {
+	return filepath.Join(m.Dir, filepath.FromSlash(m.Path))
+}
+
+// Load loads the manifest from the file.
+func Load(file string) (*Manifest, error) {
+	raw, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var m Manifest
+	if err := yaml.UnmarshalStrict(raw, &m); err != nil {
+		return nil, fmt.Errorf("parsing manifest %s: %s", file, err)
+	}
+	if m.Path == "" {
+		m.Path = "."
+	}
+	abs, err := filepath.Abs(filepath.Dir(file))
+	if err != nil {
+		return nil, err
+	}
+	m.Dir = abs
+	return &m, nil
+}
+
+// Find searches the manifest in the directory and all its parents. If no
+// manifest is found, nil is returned.
+func Find(dir string) (*Manifest, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		file := filepath.Join(dir, FileName)
+		if _, err := os.Stat(file); err == nil {
+			return Load(file)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, nil
+		}
+		dir = parent
+	}
+}
+
+// Marshal returns the content of the manifest file.
+func (m *Manifest) Marshal() ([]byte, error) {
+	raw, err := yaml.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	header := "# Project manifest of boa. Flags passed on the command line take precedence.\n"
+	return append([]byte(header), raw...), nil
+}
+
+// Write writes the manifest to the directory of the manifest. Existing
+// manifests are not overwritten.
+func (m *Manifest) Write() error {
+	raw, err := m.Marshal()
+	if err != nil {
+		return err
+	}
+	file := filepath.Join(m.Dir, FileName)
+	if _, err := os.Stat(file); err == nil {
+		return fmt.Errorf("manifest %s already exists", file)
+	}
+	return ioutil.WriteFile(file, raw, 0644)
+}
+
+// Module returns the import path of the package in the directory based on the
+// go.mod file in the directory or one of its parents. If the directory is not
+// part of a module, the empty string is returned.
+func Module(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for root := dir; ; {
+		raw, err := ioutil.ReadFile(filepath.Join(root, "go.mod"))
+		if err == nil {
+			module := modulePath(raw)
+			if module == "" {
+				return "", fmt.Errorf("no module directive in %s", filepath.Join(root, "go.mod"))
+			}
+			rel, err := filepath.Rel(root, dir)
+			if err != nil {
+				return "", err
+			}
+			return path.Join(module, filepath.ToSlash(rel)), nil
+		}
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+		parent := filepath.Dir(root)
+		if parent == root {
+			return "", nil
+		}
+		root = parent
+	}
+}
+
+// modulePath returns the module path declared in the go.mod file.
+func modulePath(mod []byte) string {
+	s := bufio.NewScanner(bytes.NewReader(mod))
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) >= 2 && fields[0] == "module" {
+			return strings.Trim(fields[1], `"`)
+		}
+	}
+	return ""
+}
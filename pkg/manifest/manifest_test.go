@@ -0,0 +1,91 @@
+// Copyright 2020 oncilla
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manifest_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oncilla/boa/pkg/manifest"
+)
+
+func TestFind(t *testing.T) {
+	dir, err := ioutil.TempDir("", "manifest")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	nested := filepath.Join(dir, "cmd", "app", "internal")
+	require.NoError(t, os.MkdirAll(nested, 0755))
+
+	m, err := manifest.Find(nested)
+	require.NoError(t, err)
+	assert.Nil(t, m)
+
+	written := &manifest.Manifest{
+		Name:    "app",
+		Path:    "cmd/app",
+		Author:  "oncilla",
+		License: "mit",
+		Dir:     dir,
+	}
+	require.NoError(t, written.Write())
+	assert.Error(t, written.Write())
+
+	m, err = manifest.Find(nested)
+	require.NoError(t, err)
+	require.NotNil(t, m)
+	assert.Equal(t, written, m)
+	assert.Equal(t, filepath.Join(dir, "cmd", "app"), m.MainDir())
+}
+
+func TestLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "manifest")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, manifest.FileName)
+
+	require.NoError(t, ioutil.WriteFile(file, []byte("name: app\n"), 0644))
+	m, err := manifest.Load(file)
+	require.NoError(t, err)
+	assert.Equal(t, dir, m.MainDir())
+
+	require.NoError(t, ioutil.WriteFile(file, []byte("name: app\nauthr: typo\n"), 0644))
+	_, err = manifest.Load(file)
+	assert.Error(t, err)
+}
+
+func TestModule(t *testing.T) {
+	dir, err := ioutil.TempDir("", "module")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	nested := filepath.Join(dir, "cmd", "app")
+	require.NoError(t, os.MkdirAll(nested, 0755))
+
+	module, err := manifest.Module(nested)
+	require.NoError(t, err)
+	assert.Empty(t, module)
+
+	mod := []byte("// comment\nmodule example.com/project\n\ngo 1.13\n")
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "go.mod"), mod, 0644))
+	module, err = manifest.Module(nested)
+	require.NoError(t, err)
+	assert.Equal(t, "example.com/project/cmd/app", module)
+	module, err = manifest.Module(dir)
+	require.NoError(t, err)
+	assert.Equal(t, "example.com/project", module)
+}
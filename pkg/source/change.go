@@ -0,0 +1,244 @@
+// Copyright 2020 oncilla
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package source
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/oncilla/boa/pkg/diff"
+)
+
+// Change is the modification of a single file.
+type Change struct {
+	// Path is the path of the file.
+	Path string
+	// Old is the content before the change. It is nil for created files.
+	Old []byte
+	// New is the content after the change. It is nil for removed files.
+	New []byte
+}
+
+// Changed reports whether the change modifies the file on disk.
+func (c Change) Changed() bool {
+	return c.Old == nil || c.New == nil || !bytes.Equal(c.Old, c.New)
+}
+
+// Name returns the slash separated path of the file relative to dir. If the
+// path cannot be made relative, it is returned as is.
+func (c Change) Name(dir string) string {
+	if rel, err := filepath.Rel(dir, c.Path); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return c.Path
+}
+
+// Diff returns the unified diff of the change. The file names in the header
+// are relative to dir.
+func (c Change) Diff(dir string) string {
+	name := c.Name(dir)
+	oldName, newName := "a/"+name, "b/"+name
+	if c.Old == nil {
+		oldName = "/dev/null"
+	}
+	if c.New == nil {
+		newName = "/dev/null"
+	}
+	return diff.Unified(oldName, newName, c.Old, c.New)
+}
+
+// Policy determines how changes to existing files are handled when
+// generating files.
+type Policy int
+
+const (
+	// Fail rejects the changes if any of the files already exists.
+	Fail Policy = iota
+	// Force overwrites existing files.
+	Force
+	// SkipExisting keeps existing files and only creates the missing ones.
+	SkipExisting
+	// Backup copies existing files to a file with the BackupSuffix before
+	// they are overwritten.
+	Backup
+)
+
+// BackupSuffix is appended to the name of backed up files.
+const BackupSuffix = ".bak"
+
+// Resolve applies the policy to the changes. All files are checked up front,
+// such that either all changes or none of them are applied.
+func (p Policy) Resolve(changes []Change) ([]Change, error) {
+	var resolved []Change
+	var existing []string
+	for _, c := range changes {
+		if c.Old == nil {
+			resolved = append(resolved, c)
+			continue
+		}
+		switch p {
+		case Fail:
+			existing = append(existing, c.Path)
+		case Force:
+			resolved = append(resolved, c)
+		case SkipExisting:
+		case Backup:
+			if !c.Changed() {
+				continue
+			}
+			backup := c.Path + BackupSuffix
+			if _, err := os.Stat(backup); err == nil {
+				return nil, fmt.Errorf("backup file already exists: %s", backup)
+			}
+			resolved = append(resolved, Change{Path: backup, New: c.Old}, c)
+		default:
+			return nil, fmt.Errorf("unknown policy: %d", p)
+		}
+	}
+	switch len(existing) {
+	case 0:
+		return resolved, nil
+	case 1:
+		return nil, fmt.Errorf("file already exists: %s", existing[0])
+	default:
+		return nil, fmt.Errorf("files already exist: %s", strings.Join(existing, ", "))
+	}
+}
+
+// Apply writes the changes to disk. Files are created or overwritten with
+// their new content, and removed if there is none. Missing directories are
+// created. Changes that do not modify the content are skipped.
+//
+// The changes are applied as a transaction. The new content is first written
+// to temporary files next to the target files, which are then renamed to the
+// targets. If any step fails, the changes that have already been applied are
+// rolled back, and the created temporary files and directories are removed.
+func Apply(changes []Change) (err error) {
+	// undo contains the steps to roll back the applied changes. They are
+	// executed in reverse order.
+	var undo []func()
+	defer func() {
+		if err == nil {
+			return
+		}
+		for i := len(undo) - 1; i >= 0; i-- {
+			undo[i]()
+		}
+	}()
+
+	type staged struct {
+		Change
+		tmp string
+	}
+	var writes []staged
+	for _, c := range changes {
+		if !c.Changed() || c.New == nil {
+			continue
+		}
+		dirs, err := mkdirAll(filepath.Dir(c.Path))
+		if err != nil {
+			return err
+		}
+		undo = append(undo, func() {
+			for i := len(dirs) - 1; i >= 0; i-- {
+				os.Remove(dirs[i])
+			}
+		})
+		tmp, err := writeTemp(c.Path, c.New)
+		if err != nil {
+			return err
+		}
+		undo = append(undo, func() { os.Remove(tmp) })
+		writes = append(writes, staged{Change: c, tmp: tmp})
+	}
+	for _, w := range writes {
+		if err := os.Rename(w.tmp, w.Path); err != nil {
+			return err
+		}
+		undo = append(undo, restore(w.Change))
+	}
+	for _, c := range changes {
+		if !c.Changed() || c.New != nil {
+			continue
+		}
+		if err := os.Remove(c.Path); err != nil {
+			return err
+		}
+		undo = append(undo, restore(c))
+	}
+	return nil
+}
+
+// writeTemp writes the content to a temporary file in the directory of the
+// file. The permissions of the file are used if it exists.
+func writeTemp(file string, content []byte) (string, error) {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(file); err == nil {
+		mode = info.Mode().Perm()
+	}
+	f, err := ioutil.TempFile(filepath.Dir(file), "."+filepath.Base(file)+".tmp")
+	if err != nil {
+		return "", err
+	}
+	_, err = f.Write(content)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(f.Name(), mode)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// restore returns the function that restores the file to the state before the
+// change.
+func restore(c Change) func() {
+	return func() {
+		if c.Old == nil {
+			os.Remove(c.Path)
+			return
+		}
+		ioutil.WriteFile(c.Path, c.Old, 0644)
+	}
+}
+
+// mkdirAll creates the directory and all missing parents. It returns the
+// created directories, starting with the outermost.
+func mkdirAll(dir string) ([]string, error) {
+	var missing []string
+	for d := dir; ; d = filepath.Dir(d) {
+		if _, err := os.Stat(d); err == nil {
+			break
+		}
+		missing = append(missing, d)
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(missing)-1; i < j; i, j = i+1, j-1 {
+		missing[i], missing[j] = missing[j], missing[i]
+	}
+	return missing, nil
+}
@@ -0,0 +1,126 @@
+// Copyright 2020 oncilla
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package source_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oncilla/boa/pkg/source"
+)
+
+func TestResolve(t *testing.T) {
+	dir, err := ioutil.TempDir("", "resolve")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	created := source.Change{Path: filepath.Join(dir, "new.go"), New: []byte("new")}
+	existing := source.Change{Path: filepath.Join(dir, "old.go"), Old: []byte("old"), New: []byte("new")}
+	unchanged := source.Change{Path: filepath.Join(dir, "same.go"), Old: []byte("same"), New: []byte("same")}
+	changes := []source.Change{created, existing, unchanged}
+
+	_, err = source.Fail.Resolve(changes)
+	assert.EqualError(t, err, "files already exist: "+existing.Path+", "+unchanged.Path)
+	resolved, err := source.Fail.Resolve([]source.Change{created})
+	require.NoError(t, err)
+	assert.Equal(t, []source.Change{created}, resolved)
+
+	resolved, err = source.Force.Resolve(changes)
+	require.NoError(t, err)
+	assert.Equal(t, changes, resolved)
+
+	resolved, err = source.SkipExisting.Resolve(changes)
+	require.NoError(t, err)
+	assert.Equal(t, []source.Change{created}, resolved)
+
+	resolved, err = source.Backup.Resolve(changes)
+	require.NoError(t, err)
+	backup := source.Change{Path: existing.Path + ".bak", New: existing.Old}
+	assert.Equal(t, []source.Change{created, backup, existing}, resolved)
+
+	require.NoError(t, ioutil.WriteFile(backup.Path, nil, 0644))
+	_, err = source.Backup.Resolve(changes)
+	assert.EqualError(t, err, "backup file already exists: "+backup.Path)
+}
+
+func TestApply(t *testing.T) {
+	dir, err := ioutil.TempDir("", "apply")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	write := func(name, content string, mode os.FileMode) string {
+		file := filepath.Join(dir, name)
+		require.NoError(t, ioutil.WriteFile(file, []byte(content), mode))
+		return file
+	}
+	read := func(file string) string {
+		raw, err := ioutil.ReadFile(file)
+		require.NoError(t, err)
+		return string(raw)
+	}
+	names := func() []string {
+		var names []string
+		err := filepath.Walk(dir, func(file string, _ os.FileInfo, err error) error {
+			rel, _ := filepath.Rel(dir, file)
+			names = append(names, rel)
+			return err
+		})
+		require.NoError(t, err)
+		return names
+	}
+
+	t.Run("success", func(t *testing.T) {
+		script := write("script.sh", "old", 0755)
+		removed := write("removed.go", "removed", 0644)
+		created := filepath.Join(dir, "sub", "dir", "created.go")
+		require.NoError(t, source.Apply([]source.Change{
+			{Path: script, Old: []byte("old"), New: []byte("new")},
+			{Path: removed, Old: []byte("removed")},
+			{Path: created, New: []byte("created")},
+		}))
+		assert.Equal(t, "new", read(script))
+		info, err := os.Stat(script)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
+		assert.Equal(t, "created", read(created))
+		_, err = os.Stat(removed)
+		assert.True(t, os.IsNotExist(err))
+		assert.Equal(t, []string{".", "script.sh", "sub", "sub/dir", "sub/dir/created.go"}, names())
+	})
+
+	t.Run("rollback", func(t *testing.T) {
+		require.NoError(t, os.RemoveAll(dir))
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "blocked", "child"), 0755))
+		existing := write("existing.go", "old", 0644)
+		removed := write("removed.go", "removed", 0644)
+		before := names()
+
+		err := source.Apply([]source.Change{
+			{Path: filepath.Join(dir, "new", "created.go"), New: []byte("created")},
+			{Path: existing, Old: []byte("old"), New: []byte("new")},
+			{Path: removed, Old: []byte("removed")},
+			// Renaming a file onto a non-empty directory fails.
+			{Path: filepath.Join(dir, "blocked"), Old: []byte("dir"), New: []byte("file")},
+		})
+		require.Error(t, err)
+		assert.Equal(t, before, names())
+		assert.Equal(t, "old", read(existing))
+		assert.Equal(t, "removed", read(removed))
+	})
+}
@@ -0,0 +1,200 @@
+// Copyright 2020 oncilla
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package source
+
+import (
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// majorVersion matches the major version suffix of an import path.
+var majorVersion = regexp.MustCompile(`^v[0-9]+$`)
+
+// Format formats the Go source in the same way as goimports, without adding
+// missing imports. Unused imports are removed, and the imports are sorted and
+// grouped into standard library imports, third party imports, and imports
+// with the local prefix, e.g., the module path of the project. If local is
+// empty, there is no local group.
+func Format(src []byte, local string) ([]byte, error) {
+	src, err := pruneImports(src)
+	if err != nil {
+		return nil, err
+	}
+	if src, err = groupImports(src, local); err != nil {
+		return nil, err
+	}
+	return format.Source(src)
+}
+
+// IsStd indicates whether the import path belongs to the standard library.
+func IsStd(path string) bool {
+	return !strings.Contains(strings.Split(path, "/")[0], ".")
+}
+
+// groupImports sorts and groups the imports of the parenthesized import
+// declarations. Declarations that contain comments are left untouched, such
+// that no comment is lost or moved.
+func groupImports(src []byte, local string) ([]byte, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", src, parser.ImportsOnly|parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	offset := func(pos token.Pos) int { return fset.Position(pos).Offset }
+	var edits []edit
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT || !gen.Lparen.IsValid() || commented(f, gen) {
+			continue
+		}
+		var groups [3][]string
+		seen := map[string]bool{}
+		for _, spec := range gen.Specs {
+			imp := spec.(*ast.ImportSpec)
+			line := imp.Path.Value
+			if imp.Name != nil {
+				line = imp.Name.Name + " " + line
+			}
+			if seen[line] {
+				continue
+			}
+			seen[line] = true
+			path, err := strconv.Unquote(imp.Path.Value)
+			if err != nil {
+				return nil, err
+			}
+			group := 1
+			switch {
+			case IsStd(path):
+				group = 0
+			case local != "" && (path == local || strings.HasPrefix(path, local+"/")):
+				group = 2
+			}
+			groups[group] = append(groups[group], line)
+		}
+		var blocks []string
+		for _, group := range groups {
+			if len(group) == 0 {
+				continue
+			}
+			sort.Slice(group, func(i, j int) bool {
+				return importPath(group[i]) < importPath(group[j])
+			})
+			blocks = append(blocks, "\t"+strings.Join(group, "\n\t")+"\n")
+		}
+		edits = append(edits, edit{
+			start: offset(gen.Pos()),
+			end:   offset(gen.End()),
+			text:  "import (\n" + strings.Join(blocks, "\n") + ")",
+		})
+	}
+	if len(edits) == 0 {
+		return src, nil
+	}
+	return splice(src, edits...)
+}
+
+// commented indicates whether the declaration contains comments.
+func commented(f *ast.File, decl *ast.GenDecl) bool {
+	for _, c := range f.Comments {
+		if c.Pos() >= decl.Pos() && c.End() <= decl.End() {
+			return true
+		}
+	}
+	return false
+}
+
+// importPath returns the quoted path of an import line with an optional name.
+func importPath(line string) string {
+	return line[strings.Index(line, `"`):]
+}
+
+// pruneImports removes the imports that are no longer used in the source. The
+// package name is assumed to be the last element of the import path. Imports
+// where this is obviously not the case, e.g., 'gopkg.in/yaml.v2', are kept.
+func pruneImports(src []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	used := map[string]bool{}
+	ast.Inspect(f, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if ident, ok := sel.X.(*ast.Ident); ok {
+				used[ident.Name] = true
+			}
+		}
+		return true
+	})
+
+	offset := func(pos token.Pos) int { return fset.Position(pos).Offset }
+	var edits []edit
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+		var unused []*ast.ImportSpec
+		for _, spec := range gen.Specs {
+			imp := spec.(*ast.ImportSpec)
+			name, ok := importedName(imp)
+			if ok && !used[name] {
+				unused = append(unused, imp)
+			}
+		}
+		if len(unused) == len(gen.Specs) {
+			edits = append(edits, edit{start: offset(gen.Pos()), end: offset(gen.End())})
+			continue
+		}
+		for _, imp := range unused {
+			start, end := offset(imp.Pos()), offset(imp.End())
+			if imp.Comment != nil {
+				end = offset(imp.Comment.End())
+			}
+			if end < len(src) && src[end] == '\n' {
+				end++
+			}
+			edits = append(edits, edit{start: start, end: end})
+		}
+	}
+	if len(edits) == 0 {
+		return src, nil
+	}
+	return splice(src, edits...)
+}
+
+// importedName returns the name under which the import is available in the
+// file. If the name cannot be determined reliably, false is returned.
+func importedName(imp *ast.ImportSpec) (string, bool) {
+	if imp.Name != nil {
+		name := imp.Name.Name
+		return name, name != "_" && name != "."
+	}
+	path, err := strconv.Unquote(imp.Path.Value)
+	if err != nil {
+		return "", false
+	}
+	name := path[strings.LastIndex(path, "/")+1:]
+	if majorVersion.MatchString(name) || !token.IsIdentifier(name) {
+		return "", false
+	}
+	return name, true
+}
@@ -0,0 +1,162 @@
+// Copyright 2020 oncilla
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package source_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oncilla/boa/pkg/source"
+)
+
+func TestFormat(t *testing.T) {
+	testCases := map[string]struct {
+		Input    string
+		Local    string
+		Expected string
+		Error    bool
+	}{
+		"prune and group": {
+			Input: `package main
+import (
+	"github.com/spf13/cobra"
+	"os"
+	"fmt"
+	"example.com/app/internal/version"
+)
+func main() { fmt.Println(version.Version, cobra.NoArgs) }
+`,
+			Local: "example.com/app",
+			Expected: `package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"example.com/app/internal/version"
+)
+
+func main() { fmt.Println(version.Version, cobra.NoArgs) }
+`,
+		},
+		"no local group": {
+			Input: `package main
+
+import (
+	"example.com/app/internal/version"
+	"github.com/spf13/cobra"
+	"fmt"
+	"fmt"
+)
+
+func main() { fmt.Println(version.Version, cobra.NoArgs) }
+`,
+			Expected: `package main
+
+import (
+	"fmt"
+
+	"example.com/app/internal/version"
+	"github.com/spf13/cobra"
+)
+
+func main() { fmt.Println(version.Version, cobra.NoArgs) }
+`,
+		},
+		"named and unknown names": {
+			Input: `package main
+
+import (
+	"strings"
+	yaml "gopkg.in/yaml.v2"
+	"gopkg.in/check.v1"
+	_ "net/http/pprof"
+	str "strconv"
+)
+
+func main() { yaml.Marshal(str.Itoa(1)) }
+`,
+			Expected: `package main
+
+import (
+	_ "net/http/pprof"
+	str "strconv"
+
+	"gopkg.in/check.v1"
+	yaml "gopkg.in/yaml.v2"
+)
+
+func main() { yaml.Marshal(str.Itoa(1)) }
+`,
+		},
+		"comments are kept": {
+			Input: `package main
+
+import (
+	"os"
+	// fmt is used for printing.
+	"fmt"
+)
+
+func main() { fmt.Println(os.Args) }
+`,
+			Expected: `package main
+
+import (
+	"os"
+	// fmt is used for printing.
+	"fmt"
+)
+
+func main() { fmt.Println(os.Args) }
+`,
+		},
+		"all unused": {
+			Input: `package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func main() {}
+`,
+			Expected: `package main
+
+func main() {}
+`,
+		},
+		"syntax error": {
+			Input: `package main
+
+func main() {
+`,
+			Error: true,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			out, err := source.Format([]byte(tc.Input), tc.Local)
+			if tc.Error {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.Expected, string(out))
+		})
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2020 oncilla
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package source
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// commandName matches the valid command names, e.g., greet or add-remote.
+var commandName = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+
+// CheckName checks that the name is a valid command name. Command names start
+// with a lower case letter, followed by lower case letters, digits, dashes
+// and underscores. This keeps the command line, the constructor and the file
+// names consistent.
+func CheckName(name string) error {
+	if !commandName.MatchString(name) {
+		return fmt.Errorf("invalid command name %q", name)
+	}
+	return nil
+}
+
+// Constructor returns the name of the constructor for the command path. The
+// path contains the names of all parents, excluding the root command, followed
+// by the command name, e.g., [remote add] results in newRemoteAdd. Dashes and
+// underscores in the names are removed and the following letter is
+// capitalized.
+func Constructor(path []string) string {
+	var b strings.Builder
+	b.WriteString("new")
+	for _, name := range path {
+		for _, part := range strings.FieldsFunc(name, func(r rune) bool {
+			return r == '-' || r == '_'
+		}) {
+			b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+		}
+	}
+	return b.String()
+}
+
+// FileName returns the name of the file that contains the command with the
+// given path, e.g., [remote add] results in remote_add.go.
+func FileName(path []string) string {
+	return strings.Join(path, "_") + ".go"
+}
+
+// TestFileName returns the name of the test file for the command with the
+// given path, e.g., [remote add] results in remote_add_test.go.
+func TestFileName(path []string) string {
+	return strings.Join(path, "_") + "_test.go"
+}
+
+// TestFunc returns the name of the test function for the command with the
+// given path, e.g., [remote add] results in TestRemoteAdd.
+func TestFunc(path []string) string {
+	return "Test" + strings.TrimPrefix(Constructor(path), "new")
+}
+
+// ConfigType returns the name of the config struct for the command with the
+// given path, e.g., [remote add] results in remoteAddConfig.
+func ConfigType(path []string) string {
+	name := strings.TrimPrefix(Constructor(path), "new")
+	return strings.ToLower(name[:1]) + name[1:] + "Config"
+}
+
+// DefaultConfig returns the name of the function that returns the default
+// config for the command with the given path, e.g., [remote add] results in
+// defaultRemoteAddConfig.
+func DefaultConfig(path []string) string {
+	return "default" + strings.TrimPrefix(Constructor(path), "new") + "Config"
+}
@@ -0,0 +1,367 @@
+// Copyright 2020 oncilla
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package source
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// node is a command in the command tree of the package.
+type node struct {
+	path []string
+	ctor string
+	file *File
+	fn   *ast.FuncDecl
+}
+
+// Rename renames the command with the given path. The constructors of the
+// command and all its subcommands are renamed, including all references in
+// the package and its tests. Generated test functions, e.g., TestRemoteAdd,
+// are renamed as well. Files that follow the naming convention are
+// moved, and the Use and Example of the command are updated. The package
+// itself is not modified.
+func (p *Package) Rename(path []string, name string) ([]Change, error) {
+	nodes, err := p.subtree(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := CheckName(name); err != nil {
+		return nil, err
+	}
+	oldPath := nodes[0].path
+	newPath := append(append([]string(nil), oldPath[:len(oldPath)-1]...), name)
+	oldCtor, newCtor := nodes[0].ctor, Constructor(newPath)
+	if oldCtor == newCtor {
+		return nil, fmt.Errorf("command %s already has the name %q", oldCtor, name)
+	}
+
+	renames := map[string]string{}
+	moves := map[*File]string{}
+	for _, n := range nodes {
+		to := newCtor + strings.TrimPrefix(n.ctor, oldCtor)
+		if _, fn, ok := p.Func(to); ok {
+			return nil, fmt.Errorf("constructor %s already exists in %s", to, p.Position(fn))
+		}
+		renames[n.ctor] = to
+		rel := append(append([]string(nil), newPath...), n.path[len(oldPath):]...)
+		renames[TestFunc(n.path)] = TestFunc(rel)
+		renames[TestFunc(n.path)+"Config"] = TestFunc(rel) + "Config"
+		renames[ConfigType(n.path)] = ConfigType(rel)
+		renames[DefaultConfig(n.path)] = DefaultConfig(rel)
+		if filepath.Base(n.file.Path) == FileName(n.path) {
+			moves[n.file] = filepath.Join(p.Dir, FileName(rel))
+		}
+		if test := p.file(TestFileName(n.path)); test != nil {
+			moves[test] = filepath.Join(p.Dir, TestFileName(rel))
+		}
+	}
+
+	edits := map[*File][]edit{}
+	for _, f := range p.Files {
+		f := f
+		ast.Inspect(f.AST, func(n ast.Node) bool {
+			ident, ok := n.(*ast.Ident)
+			if !ok {
+				return true
+			}
+			if to, ok := renames[ident.Name]; ok {
+				edits[f] = append(edits[f], edit{
+					start: p.offset(ident.Pos()),
+					end:   p.offset(ident.End()),
+					text:  to,
+				})
+			}
+			return true
+		})
+	}
+	root := nodes[0]
+	oldName := regexp.QuoteMeta(oldPath[len(oldPath)-1])
+	if lit := stringField(root.fn, "Use"); lit != nil {
+		expr := regexp.MustCompile("^([\"`]\\s*)" + oldName + `\b`)
+		edits[root.file] = append(edits[root.file], p.replaceLit(lit, expr, name))
+	}
+	if lit := stringField(root.fn, "Example"); lit != nil {
+		expr := regexp.MustCompile(`(%(?:\[\d+\])?s )` + oldName + `\b`)
+		edits[root.file] = append(edits[root.file], p.replaceLit(lit, expr, name))
+	}
+
+	var changes []Change
+	for _, f := range p.Files {
+		to, moved := moves[f]
+		if len(edits[f]) == 0 && !moved {
+			continue
+		}
+		out, err := splice(f.Src, edits[f]...)
+		if err != nil {
+			return nil, fmt.Errorf("renaming results in invalid source in %s: %s", f.Path, err)
+		}
+		if !moved || to == f.Path {
+			changes = append(changes, Change{Path: f.Path, Old: f.Src, New: out})
+			continue
+		}
+		if _, err := os.Stat(to); err == nil {
+			return nil, fmt.Errorf("file %s already exists", to)
+		}
+		changes = append(changes,
+			Change{Path: f.Path, Old: f.Src},
+			Change{Path: to, New: out},
+		)
+	}
+	return changes, nil
+}
+
+// Remove removes the command with the given path and all its subcommands. The
+// command is removed from the AddCommand call of its parent. Files that follow
+// the naming convention are removed, including the test files. In other
+// files, only the constructor is removed. If a removed constructor is still
+// referenced afterwards, an error is returned. The package itself is not
+// modified.
+func (p *Package) Remove(path []string) ([]Change, error) {
+	nodes, err := p.subtree(path)
+	if err != nil {
+		return nil, err
+	}
+	removed := map[string]bool{}
+	deleted := map[*File]bool{}
+	edits := map[*File][]edit{}
+	for _, n := range nodes {
+		removed[n.ctor] = true
+		if filepath.Base(n.file.Path) == FileName(n.path) && onlyFunc(n.file, n.fn) {
+			deleted[n.file] = true
+		} else {
+			start := n.fn.Pos()
+			if n.fn.Doc != nil {
+				start = n.fn.Doc.Pos()
+			}
+			edits[n.file] = append(edits[n.file], p.removeLines(n.file, start, n.fn.End()))
+		}
+		if test := p.file(TestFileName(n.path)); test != nil {
+			deleted[test] = true
+		}
+	}
+
+	parent := "main"
+	if len(path) > 1 {
+		parent = Constructor(path[:len(path)-1])
+	}
+	if file, fn, ok := p.Func(parent); ok {
+		for _, call := range addCommandCalls(fn) {
+			for i, arg := range call.Args {
+				if calledFunc(arg) == nodes[0].ctor {
+					edits[file] = append(edits[file], p.removeArg(file, fn, call, i))
+				}
+			}
+		}
+	}
+
+	for _, f := range p.Files {
+		if deleted[f] {
+			continue
+		}
+		var referenced ast.Node
+		ast.Inspect(f.AST, func(n ast.Node) bool {
+			ident, ok := n.(*ast.Ident)
+			if referenced != nil || !ok || !removed[ident.Name] {
+				return referenced == nil
+			}
+			for _, e := range edits[f] {
+				if e.start <= p.offset(ident.Pos()) && p.offset(ident.End()) <= e.end {
+					return true
+				}
+			}
+			referenced = ident
+			return false
+		})
+		if referenced != nil {
+			return nil, fmt.Errorf("%s is still referenced in %s",
+				referenced.(*ast.Ident).Name, p.Position(referenced))
+		}
+	}
+
+	var changes []Change
+	for _, f := range p.Files {
+		if deleted[f] {
+			changes = append(changes, Change{Path: f.Path, Old: f.Src})
+			continue
+		}
+		if len(edits[f]) == 0 {
+			continue
+		}
+		out, err := splice(f.Src, edits[f]...)
+		if err == nil {
+			out, err = pruneImports(out)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("removing results in invalid source in %s: %s", f.Path, err)
+		}
+		changes = append(changes, Change{Path: f.Path, Old: f.Src, New: out})
+	}
+	return changes, nil
+}
+
+// subtree returns the command with the given path, followed by all its
+// subcommands. Only subcommands that are registered in the AddCommand call of
+// the parent and follow the naming convention are considered.
+func (p *Package) subtree(path []string) ([]node, error) {
+	ctor := Constructor(path)
+	file, fn, ok := p.Func(ctor)
+	if !ok {
+		return nil, fmt.Errorf("command %q not found: constructor %s does not exist",
+			strings.Join(path, " "), ctor)
+	}
+	nodes := []node{{path: path, ctor: ctor, file: file, fn: fn}}
+	for _, child := range children(fn) {
+		if !strings.HasPrefix(child, ctor) || child == ctor {
+			continue
+		}
+		_, childFn, ok := p.Func(child)
+		if !ok {
+			continue
+		}
+		lit := stringField(childFn, "Use")
+		if lit == nil {
+			continue
+		}
+		use := strings.Fields(strings.Trim(lit.Value, "\"`"))
+		if len(use) == 0 {
+			continue
+		}
+		childPath := append(append([]string(nil), path...), use[0])
+		if Constructor(childPath) != child {
+			continue
+		}
+		sub, err := p.subtree(childPath)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, sub...)
+	}
+	return nodes, nil
+}
+
+// file returns the file with the given base name.
+func (p *Package) file(name string) *File {
+	for _, f := range p.Files {
+		if filepath.Base(f.Path) == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// replaceLit returns the edit that replaces the matches of the expression in
+// the literal with the text.
+func (p *Package) replaceLit(lit *ast.BasicLit, expr *regexp.Regexp, text string) edit {
+	return edit{
+		start: p.offset(lit.Pos()),
+		end:   p.offset(lit.End()),
+		text:  expr.ReplaceAllString(lit.Value, "${1}"+text),
+	}
+}
+
+// removeLines returns the edit that removes the source between start and end,
+// including the indentation before start and the line break after end.
+func (p *Package) removeLines(file *File, start, end token.Pos) edit {
+	s, e := p.offset(start), p.offset(end)
+	for s > 0 && (file.Src[s-1] == ' ' || file.Src[s-1] == '\t') {
+		s--
+	}
+	if e < len(file.Src) && file.Src[e] == '\n' {
+		e++
+	}
+	return edit{start: s, end: e}
+}
+
+// removeArg returns the edit that removes the i-th argument from the call.
+// If it is the only argument, the whole statement is removed.
+func (p *Package) removeArg(file *File, fn *ast.FuncDecl, call *ast.CallExpr, i int) edit {
+	args := call.Args
+	switch {
+	case len(args) == 1:
+		var stmt ast.Stmt
+		ast.Inspect(fn.Body, func(n ast.Node) bool {
+			if s, ok := n.(*ast.ExprStmt); ok && s.X == call {
+				stmt = s
+			}
+			return stmt == nil
+		})
+		if stmt != nil {
+			return p.removeLines(file, stmt.Pos(), stmt.End())
+		}
+		return edit{start: p.offset(args[0].Pos()), end: p.offset(args[0].End())}
+	case i < len(args)-1:
+		return edit{start: p.offset(args[i].Pos()), end: p.offset(args[i+1].Pos())}
+	default:
+		return edit{start: p.offset(args[i-1].End()), end: p.offset(args[i].End())}
+	}
+}
+
+// onlyFunc indicates whether the function is the only declaration in the
+// file, apart from imports.
+func onlyFunc(file *File, fn *ast.FuncDecl) bool {
+	for _, decl := range file.AST.Decls {
+		if decl == fn {
+			continue
+		}
+		if gen, ok := decl.(*ast.GenDecl); !ok || gen.Tok != token.IMPORT {
+			return false
+		}
+	}
+	return true
+}
+
+// stringField returns the string literal that is assigned to the key in a
+// composite literal in the function, e.g., 'Use: "greet"'.
+func stringField(fn *ast.FuncDecl, key string) *ast.BasicLit {
+	var lit *ast.BasicLit
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		kv, ok := n.(*ast.KeyValueExpr)
+		if !ok || lit != nil {
+			return lit == nil
+		}
+		if ident, ok := kv.Key.(*ast.Ident); !ok || ident.Name != key {
+			return true
+		}
+		ast.Inspect(kv.Value, func(n ast.Node) bool {
+			if l, ok := n.(*ast.BasicLit); ok && l.Kind == token.STRING && lit == nil {
+				lit = l
+			}
+			return lit == nil
+		})
+		return false
+	})
+	return lit
+}
+
+// addCommandCalls returns all AddCommand calls in the function.
+func addCommandCalls(fn *ast.FuncDecl) []*ast.CallExpr {
+	var calls []*ast.CallExpr
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		c, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		if sel, ok := c.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "AddCommand" {
+			calls = append(calls, c)
+		}
+		return true
+	})
+	return calls
+}
@@ -0,0 +1,187 @@
+// Copyright 2020 oncilla
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package source_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oncilla/boa/pkg/source"
+)
+
+var treeFiles = map[string]string{
+	"app.go": `package main
+
+func main() {
+	cmd := &cobra.Command{Use: "app"}
+	cmd.AddCommand(
+		newGreet(cmd),
+		newRemote(cmd),
+	)
+	cmd.Execute()
+}
+`,
+	"greet.go": `package main
+
+import "fmt"
+
+func newGreet(pather CommandPather) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:     "greet <arg>",
+		Example: fmt.Sprintf("  %[1]s greet --sample", pather.CommandPath()),
+	}
+	return cmd
+}
+`,
+	"greet_test.go": `package main
+
+func TestGreet(t *testing.T) {
+	newGreet(boa.Pather("app"))
+}
+`,
+	"remote.go": `package main
+
+import (
+	"github.com/oncilla/boa/pkg/boa"
+	"github.com/spf13/cobra"
+)
+
+func newRemote(pather CommandPather) *cobra.Command {
+	var cmd = &cobra.Command{Use: "remote"}
+	cmd.AddCommand(newRemoteAdd(boa.Pather(pather.CommandPath() + " " + cmd.Name())))
+	return cmd
+}
+`,
+	"remote_add.go": `package main
+
+func newRemoteAdd(pather CommandPather) *cobra.Command {
+	return &cobra.Command{Use: "add"}
+}
+`,
+}
+
+// changes indexes the changes by the base name of the file.
+func changes(t *testing.T, c []source.Change) map[string]source.Change {
+	m := map[string]source.Change{}
+	for _, change := range c {
+		name := filepath.Base(change.Path)
+		_, ok := m[name]
+		require.False(t, ok, "duplicate change for %s", name)
+		m[name] = change
+	}
+	return m
+}
+
+func TestRename(t *testing.T) {
+	p, cleanup := load(t, treeFiles)
+	defer cleanup()
+
+	t.Run("command", func(t *testing.T) {
+		c, err := p.Rename([]string{"greet"}, "hello")
+		require.NoError(t, err)
+		m := changes(t, c)
+		require.Len(t, m, 5)
+		assert.Contains(t, string(m["app.go"].New), "newHello(cmd),\n\t\tnewRemote(cmd),")
+		assert.Nil(t, m["greet.go"].New)
+		assert.Nil(t, m["greet_test.go"].New)
+		hello := string(m["hello.go"].New)
+		assert.Contains(t, hello, "func newHello(pather CommandPather)")
+		assert.Contains(t, hello, `Use:     "hello <arg>",`)
+		assert.Contains(t, hello, `"  %[1]s hello --sample"`)
+		assert.Contains(t, string(m["hello_test.go"].New), `newHello(boa.Pather("app"))`)
+		assert.Contains(t, string(m["hello_test.go"].New), "func TestHello(t *testing.T) {")
+		assert.Contains(t, m["hello.go"].Diff(p.Dir), "--- /dev/null\n+++ b/hello.go\n")
+	})
+	t.Run("subcommands", func(t *testing.T) {
+		c, err := p.Rename([]string{"remote"}, "origin")
+		require.NoError(t, err)
+		m := changes(t, c)
+		require.Len(t, m, 5)
+		assert.Contains(t, string(m["app.go"].New), "newOrigin(cmd),")
+		assert.Contains(t, string(m["origin.go"].New), "cmd.AddCommand(newOriginAdd(")
+		assert.Contains(t, string(m["origin_add.go"].New), "func newOriginAdd(")
+		assert.Nil(t, m["remote.go"].New)
+		assert.Nil(t, m["remote_add.go"].New)
+	})
+	t.Run("existing", func(t *testing.T) {
+		_, err := p.Rename([]string{"remote"}, "greet")
+		assert.Error(t, err)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		for _, name := range []string{"a b", "remote/add", "Bad-Name", "1st", ""} {
+			_, err := p.Rename([]string{"remote"}, name)
+			assert.Error(t, err, name)
+		}
+	})
+	t.Run("missing", func(t *testing.T) {
+		_, err := p.Rename([]string{"origin"}, "remote")
+		assert.Error(t, err)
+	})
+}
+
+func TestRemove(t *testing.T) {
+	p, cleanup := load(t, treeFiles)
+	defer cleanup()
+
+	t.Run("subcommands", func(t *testing.T) {
+		c, err := p.Remove([]string{"remote"})
+		require.NoError(t, err)
+		m := changes(t, c)
+		require.Len(t, m, 3)
+		assert.Contains(t, string(m["app.go"].New), "cmd.AddCommand(\n\t\tnewGreet(cmd),\n\t)")
+		assert.Nil(t, m["remote.go"].New)
+		assert.Nil(t, m["remote_add.go"].New)
+	})
+	t.Run("subcommand", func(t *testing.T) {
+		c, err := p.Remove([]string{"remote", "add"})
+		require.NoError(t, err)
+		m := changes(t, c)
+		require.Len(t, m, 2)
+		assert.Equal(t, `package main
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func newRemote(pather CommandPather) *cobra.Command {
+	var cmd = &cobra.Command{Use: "remote"}
+	return cmd
+}
+`, string(m["remote.go"].New))
+	})
+	t.Run("test file", func(t *testing.T) {
+		c, err := p.Remove([]string{"greet"})
+		require.NoError(t, err)
+		m := changes(t, c)
+		require.Len(t, m, 3)
+		assert.Nil(t, m["greet_test.go"].New)
+	})
+	t.Run("referenced", func(t *testing.T) {
+		files := map[string]string{"util.go": `package main
+
+var greet = newGreet
+`}
+		for name, src := range treeFiles {
+			files[name] = src
+		}
+		p, cleanup := load(t, files)
+		defer cleanup()
+		_, err := p.Remove([]string{"greet"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "newGreet is still referenced in")
+	})
+}
@@ -0,0 +1,322 @@
+// Copyright 2020 oncilla
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package source
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// BoaImport is the import path of the boa package.
+const BoaImport = "github.com/oncilla/boa/pkg/boa"
+
+// Register registers the command created by the child constructor with the
+// command created by the parent function. The parent is either 'main' for
+// the root command, or the constructor of the parent command. The call is
+// inserted in sorted position into the first AddCommand call of the parent.
+// If there is none, a new AddCommand call is added before the command is
+// returned. The modified and formatted source of the file is returned. The
+// package itself is not modified.
+//
+// The root command is passed to the child constructor as CommandPather. A
+// parent command is not yet attached to its own parent while it is
+// constructed, thus its command path is incomplete. Instead, the child gets a
+// boa.Pather that extends the path of the parent constructor's pather, e.g.,
+// 'boa.Pather(pather.CommandPath() + " " + cmd.Name())'.
+//
+// Commands generated without positional arguments have the usage line
+// 'name <arg>'. Cobra treats the argument as unknown subcommand once the
+// command has subcommands, thus the placeholder is removed from the usage line
+// of the parent. See NestedTest for the generated test of the parent.
+func (p *Package) Register(parent, child string) (*File, []byte, error) {
+	file, fn, ok := p.Func(parent)
+	if !ok {
+		return nil, nil, fmt.Errorf("parent function %s not found", parent)
+	}
+	if fn.Body == nil {
+		return nil, nil, fmt.Errorf("parent function %s has no body", parent)
+	}
+	var use []edit
+	if lit := argPlaceholder(fn); lit != nil {
+		use = append(use, p.replaceLit(lit, regexp.MustCompile(" <arg>([\"`])$"), ""))
+	}
+
+	if call, recv, ok := findAddCommand(fn); ok {
+		for _, arg := range call.Args {
+			if calledFunc(arg) == child {
+				return nil, nil, fmt.Errorf("%s already registered in %s", child, p.Position(arg))
+			}
+		}
+		text, edits := p.childCall(file, fn, child, recv)
+		edits = append(edits, use...)
+		if len(call.Args) == 0 {
+			out, err := splice(file.Src, append(edits, insert(p.offset(call.Rparen), text))...)
+			return file, out, err
+		}
+		sep := ", "
+		if p.Fset.Position(call.Lparen).Line != p.Fset.Position(call.Rparen).Line {
+			sep = ",\n"
+		}
+		for _, arg := range call.Args {
+			if calledFunc(arg) > child {
+				e := insert(p.offset(arg.Pos()), text+sep)
+				out, err := splice(file.Src, append(edits, e)...)
+				return file, out, err
+			}
+		}
+		last := call.Args[len(call.Args)-1]
+		e := insert(p.offset(last.End()), sep+text)
+		out, err := splice(file.Src, append(edits, e)...)
+		return file, out, err
+	}
+
+	ret, recv, ok := findReturnCommand(fn)
+	if !ok {
+		return nil, nil, fmt.Errorf("no AddCommand call or returned command found in %s", parent)
+	}
+	text, edits := p.childCall(file, fn, child, recv)
+	edits = append(edits, use...)
+	e := insert(p.offset(ret.Pos()), fmt.Sprintf("%s.AddCommand(%s)\n", recv, text))
+	out, err := splice(file.Src, append(edits, e)...)
+	return file, out, err
+}
+
+// Parent returns the function that registers the command created by the child
+// constructor in an AddCommand call, i.e., 'main' or the constructor of the
+// parent command. Test files are ignored.
+func (p *Package) Parent(child string) (string, bool) {
+	for _, f := range p.Files {
+		if p.isTest(f) {
+			continue
+		}
+		for _, decl := range f.AST.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv != nil || fn.Body == nil {
+				continue
+			}
+			for _, name := range children(fn) {
+				if name == child {
+					return fn.Name.Name, true
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+// NestedTest returns the change that removes the "arg" argument from the
+// tests of the parent constructor, if the parent still has the '<arg>'
+// placeholder in its usage line. It must be called before the source updated
+// by Register is applied to the package. It returns false, if there is
+// nothing to change.
+func (p *Package) NestedTest(parent string) (Change, bool, error) {
+	_, fn, ok := p.Func(parent)
+	if !ok || fn.Body == nil || argPlaceholder(fn) == nil {
+		return Change{}, false, nil
+	}
+	for _, f := range p.Files {
+		if !p.isTest(f) {
+			continue
+		}
+		var edits []edit
+		for _, decl := range f.AST.Decls {
+			test, ok := decl.(*ast.FuncDecl)
+			if !ok || test.Body == nil || !calls(test, parent) {
+				continue
+			}
+			ast.Inspect(test.Body, func(n ast.Node) bool {
+				if lit, ok := n.(*ast.CompositeLit); ok {
+					edits = append(edits, p.removeArgPlaceholder(lit)...)
+				}
+				return true
+			})
+		}
+		if len(edits) == 0 {
+			continue
+		}
+		out, err := splice(f.Src, edits...)
+		if err != nil {
+			return Change{}, false, err
+		}
+		return Change{Path: f.Path, Old: f.Src, New: out}, true, nil
+	}
+	return Change{}, false, nil
+}
+
+// argPlaceholder returns the Use literal of the constructor, if it ends with
+// the '<arg>' placeholder.
+func argPlaceholder(fn *ast.FuncDecl) *ast.BasicLit {
+	lit := stringField(fn, "Use")
+	if lit == nil {
+		return nil
+	}
+	use, err := strconv.Unquote(lit.Value)
+	if err != nil || !strings.HasSuffix(use, " <arg>") {
+		return nil
+	}
+	return lit
+}
+
+// removeArgPlaceholder returns the edits that remove the "arg" elements from the string
+// slice literal.
+func (p *Package) removeArgPlaceholder(lit *ast.CompositeLit) []edit {
+	if t, ok := lit.Type.(*ast.ArrayType); !ok || t.Len != nil || !isIdent(t.Elt, "string") {
+		return nil
+	}
+	var edits []edit
+	for i, elt := range lit.Elts {
+		if l, ok := elt.(*ast.BasicLit); !ok || l.Value != `"arg"` {
+			continue
+		}
+		end := elt.End()
+		if i+1 < len(lit.Elts) {
+			end = lit.Elts[i+1].Pos()
+		}
+		edits = append(edits, edit{start: p.offset(elt.Pos()), end: p.offset(end)})
+	}
+	return edits
+}
+
+// calls indicates whether the function calls the function with the name.
+func calls(fn *ast.FuncDecl, name string) bool {
+	found := false
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		if expr, ok := n.(ast.Expr); ok && calledFunc(expr) == name {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+func isIdent(expr ast.Expr, name string) bool {
+	ident, ok := expr.(*ast.Ident)
+	return ok && ident.Name == name
+}
+
+// childCall returns the call of the child constructor and the edits that are
+// required for it to compile.
+func (p *Package) childCall(file *File, fn *ast.FuncDecl, child, recv string) (string, []edit) {
+	pather := patherParam(fn)
+	if pather == "" {
+		return fmt.Sprintf("%s(%s)", child, recv), nil
+	}
+	name, edits := p.importName(file, BoaImport)
+	return fmt.Sprintf("%s(%s.Pather(%s.CommandPath() + \" \" + %s.Name()))",
+		child, name, pather, recv), edits
+}
+
+// patherParam returns the name of the CommandPather parameter of the
+// function. If there is none, the empty string is returned.
+func patherParam(fn *ast.FuncDecl) string {
+	for _, field := range fn.Type.Params.List {
+		ident, ok := field.Type.(*ast.Ident)
+		if !ok || ident.Name != "CommandPather" {
+			continue
+		}
+		for _, name := range field.Names {
+			if name.Name != "_" {
+				return name.Name
+			}
+		}
+	}
+	return ""
+}
+
+// importName returns the name under which the import path is available in the
+// file. If the file does not import the path yet, the edits add it to the
+// last import group.
+func (p *Package) importName(file *File, path string) (string, []edit) {
+	name := path[strings.LastIndex(path, "/")+1:]
+	for _, spec := range file.AST.Imports {
+		if imp, err := strconv.Unquote(spec.Path.Value); err != nil || imp != path {
+			continue
+		}
+		if spec.Name != nil {
+			return spec.Name.Name, nil
+		}
+		return name, nil
+	}
+	text := strconv.Quote(path)
+	if len(file.AST.Imports) == 0 {
+		return name, []edit{insert(p.offset(file.AST.Name.End()), "\n\nimport "+text+"\n")}
+	}
+	last := file.AST.Imports[len(file.AST.Imports)-1]
+	for _, decl := range file.AST.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if ok && gen.Tok == token.IMPORT && !gen.Lparen.IsValid() && gen.Specs[0] == last {
+			return name, []edit{insert(p.offset(gen.End()), "\nimport "+text)}
+		}
+	}
+	return name, []edit{insert(p.offset(last.End()), "\n"+text)}
+}
+
+// findAddCommand finds the first AddCommand call in the function and returns
+// the name of the receiver.
+func findAddCommand(fn *ast.FuncDecl) (*ast.CallExpr, string, bool) {
+	var call *ast.CallExpr
+	var recv string
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		if call != nil {
+			return false
+		}
+		c, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		sel, ok := c.Fun.(*ast.SelectorExpr)
+		if !ok || sel.Sel.Name != "AddCommand" {
+			return true
+		}
+		ident, ok := sel.X.(*ast.Ident)
+		if !ok {
+			return true
+		}
+		call, recv = c, ident.Name
+		return false
+	})
+	return call, recv, call != nil
+}
+
+// findReturnCommand finds the top-level return statement of the function that
+// returns a single identifier, e.g., 'return cmd'.
+func findReturnCommand(fn *ast.FuncDecl) (*ast.ReturnStmt, string, bool) {
+	for i := len(fn.Body.List) - 1; i >= 0; i-- {
+		ret, ok := fn.Body.List[i].(*ast.ReturnStmt)
+		if !ok || len(ret.Results) != 1 {
+			continue
+		}
+		if ident, ok := ret.Results[0].(*ast.Ident); ok {
+			return ret, ident.Name, true
+		}
+	}
+	return nil, "", false
+}
+
+// calledFunc returns the name of the called function, if the expression is a
+// call to a top-level function, e.g., 'newGreet(cmd)'.
+func calledFunc(expr ast.Expr) string {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok {
+		return ""
+	}
+	if ident, ok := call.Fun.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
@@ -0,0 +1,273 @@
+// Copyright 2020 oncilla
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package source_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oncilla/boa/pkg/source"
+)
+
+const mainSrc = `package main
+
+func main() {
+	cmd := &cobra.Command{}
+	cmd.AddCommand(
+		newCompletion(cmd),
+		newVersion(cmd),
+	)
+	cmd.Execute()
+}
+`
+
+const remoteSrc = `package main
+
+func newRemote(pather CommandPather) *cobra.Command {
+	var cmd = &cobra.Command{}
+	return cmd
+}
+`
+
+// load writes the files to a temporary directory and loads the package. The
+// returned function removes the directory.
+func load(t *testing.T, files map[string]string) (*source.Package, func()) {
+	dir, err := ioutil.TempDir("", "source")
+	require.NoError(t, err)
+	for name, src := range files {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(src), 0644))
+	}
+	p, err := source.Load(dir)
+	require.NoError(t, err)
+	return p, func() { os.RemoveAll(dir) }
+}
+
+func TestRegister(t *testing.T) {
+	testCases := map[string]struct {
+		Files    map[string]string
+		Parent   string
+		Child    string
+		Expected string
+		Error    bool
+	}{
+		"sorted middle": {
+			Files:  map[string]string{"app.go": mainSrc},
+			Parent: "main",
+			Child:  "newGreet",
+			Expected: `cmd.AddCommand(
+		newCompletion(cmd),
+		newGreet(cmd),
+		newVersion(cmd),
+	)`,
+		},
+		"sorted first": {
+			Files:  map[string]string{"app.go": mainSrc},
+			Parent: "main",
+			Child:  "newAdd",
+			Expected: `cmd.AddCommand(
+		newAdd(cmd),
+		newCompletion(cmd),`,
+		},
+		"sorted last": {
+			Files:  map[string]string{"app.go": mainSrc},
+			Parent: "main",
+			Child:  "newZap",
+			Expected: `		newVersion(cmd),
+		newZap(cmd),
+	)`,
+		},
+		"single line": {
+			Files: map[string]string{"app.go": `package main
+
+func main() {
+	root := &cobra.Command{}
+	root.AddCommand(newVersion(root))
+}
+`},
+			Parent:   "main",
+			Child:    "newGreet",
+			Expected: `root.AddCommand(newGreet(root), newVersion(root))`,
+		},
+		"no AddCommand": {
+			Files:  map[string]string{"app.go": mainSrc, "remote.go": remoteSrc},
+			Parent: "newRemote",
+			Child:  "newRemoteAdd",
+			Expected: `import "github.com/oncilla/boa/pkg/boa"
+
+func newRemote(pather CommandPather) *cobra.Command {
+	var cmd = &cobra.Command{}
+	cmd.AddCommand(newRemoteAdd(boa.Pather(pather.CommandPath() + " " + cmd.Name())))
+	return cmd`,
+		},
+		"import group": {
+			Files: map[string]string{"remote.go": `package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func newRemote(p CommandPather) *cobra.Command {
+	var cmd = &cobra.Command{Example: fmt.Sprint(p.CommandPath())}
+	cmd.AddCommand(newRemoteList(cmd))
+	return cmd
+}
+`},
+			Parent: "newRemote",
+			Child:  "newRemoteAdd",
+			Expected: `	"github.com/oncilla/boa/pkg/boa"
+	"github.com/spf13/cobra"
+)
+
+func newRemote(p CommandPather) *cobra.Command {
+	var cmd = &cobra.Command{Example: fmt.Sprint(p.CommandPath())}
+	cmd.AddCommand(newRemoteAdd(boa.Pather(p.CommandPath()+" "+cmd.Name())), newRemoteList(cmd))`,
+		},
+		"named import": {
+			Files: map[string]string{"remote.go": `package main
+
+import b "github.com/oncilla/boa/pkg/boa"
+
+func newRemote(pather CommandPather) *cobra.Command {
+	var cmd = &cobra.Command{}
+	b.RegisterCompletions(cmd)
+	return cmd
+}
+`},
+			Parent:   "newRemote",
+			Child:    "newRemoteAdd",
+			Expected: `newRemoteAdd(b.Pather(pather.CommandPath() + " " + cmd.Name()))`,
+		},
+		"already registered": {
+			Files:  map[string]string{"app.go": mainSrc},
+			Parent: "main",
+			Child:  "newVersion",
+			Error:  true,
+		},
+		"missing parent": {
+			Files:  map[string]string{"app.go": mainSrc},
+			Parent: "newRemote",
+			Child:  "newRemoteAdd",
+			Error:  true,
+		},
+	}
+	for name, tc := range testCases {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			p, cleanup := load(t, tc.Files)
+			defer cleanup()
+			_, out, err := p.Register(tc.Parent, tc.Child)
+			if tc.Error {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Contains(t, string(out), tc.Expected)
+		})
+	}
+}
+
+func TestFunc(t *testing.T) {
+	p, cleanup := load(t, map[string]string{
+		"app.go":         mainSrc,
+		"remote_cmd.go":  remoteSrc,
+		"remote_test.go": "package main\n\nfunc newTest() {}\n",
+	})
+	defer cleanup()
+	f, fn, ok := p.Func("newRemote")
+	require.True(t, ok)
+	assert.Equal(t, "remote_cmd.go", filepath.Base(f.Path))
+	assert.Equal(t, "remote_cmd.go:3", filepath.Base(p.Position(fn)))
+	_, _, ok = p.Func("newTest")
+	assert.False(t, ok)
+}
+
+func TestParent(t *testing.T) {
+	p, cleanup := load(t, map[string]string{
+		"app.go":    mainSrc,
+		"remote.go": remoteSrc,
+	})
+	defer cleanup()
+	parent, ok := p.Parent("newVersion")
+	assert.True(t, ok)
+	assert.Equal(t, "main", parent)
+	_, ok = p.Parent("newRemoteAdd")
+	assert.False(t, ok)
+
+	// Registering with an updated file that is not on disk yet.
+	add := filepath.Join(p.Dir, "remote_add.go")
+	require.NoError(t, p.Update(add, []byte(`package main
+
+func newRemoteAdd(pather CommandPather) *cobra.Command {
+	return &cobra.Command{}
+}
+`)))
+	f, out, err := p.Register("newRemote", "newRemoteAdd")
+	require.NoError(t, err)
+	require.NoError(t, p.Update(f.Path, out))
+	parent, ok = p.Parent("newRemoteAdd")
+	assert.True(t, ok)
+	assert.Equal(t, "newRemote", parent)
+	_, _, ok = p.Func("newRemoteAdd")
+	assert.True(t, ok)
+	_, err = os.Stat(add)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestNestedTest(t *testing.T) {
+	p, cleanup := load(t, map[string]string{
+		"app.go": mainSrc,
+		"remote.go": `package main
+
+func newRemote(pather CommandPather) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use: "remote <arg>",
+	}
+	return cmd
+}
+
+func newRemoteAdd(pather CommandPather) *cobra.Command {
+	return &cobra.Command{Use: "add <arg>"}
+}
+`,
+		"remote_test.go": `package main
+
+func TestRemote(t *testing.T) {
+	newRemote(boa.Pather("app")).SetArgs([]string{"arg", "--sample"})
+	newRemote(boa.Pather("app")).SetArgs([]string{"other", "arg"})
+}
+`,
+	})
+	defer cleanup()
+
+	change, ok, err := p.NestedTest("newRemote")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Contains(t, string(change.New), `SetArgs([]string{"--sample"})`)
+	assert.Contains(t, string(change.New), `SetArgs([]string{"other"})`)
+
+	f, out, err := p.Register("newRemote", "newRemoteAdd")
+	require.NoError(t, err)
+	assert.Contains(t, string(out), `Use: "remote",`)
+	require.NoError(t, p.Update(f.Path, out))
+	_, ok, err = p.NestedTest("newRemote")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
@@ -0,0 +1,151 @@
+// Copyright 2020 oncilla
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package source analyses and modifies the source of boa-style main packages.
+//
+// In a boa-style application, every command is created by a constructor
+// function that takes the parent as CommandPather and returns the command,
+// e.g., 'func newGreet(pather CommandPather) *cobra.Command'. Subcommands are
+// registered with the parent in an AddCommand call, either in the main
+// function for the root command, or in the constructor of the parent.
+package source
+
+import (
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// File is a parsed Go source file.
+type File struct {
+	// Path is the path to the file.
+	Path string
+	// Src is the source of the file.
+	Src []byte
+	// AST is the parsed file.
+	AST *ast.File
+}
+
+// Package is a parsed main package.
+type Package struct {
+	Dir   string
+	Fset  *token.FileSet
+	Files []*File
+}
+
+// Load parses all Go files in the directory, including test files.
+func Load(dir string) (*Package, error) {
+	names, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+	p := &Package{Dir: dir, Fset: token.NewFileSet()}
+	for _, name := range names {
+		src, err := ioutil.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		f, err := parser.ParseFile(p.Fset, name, src, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		p.Files = append(p.Files, &File{Path: name, Src: src, AST: f})
+	}
+	return p, nil
+}
+
+// Update replaces the source of the file with the given path, or adds the file
+// if the package does not contain it yet. The file on disk is not modified.
+// This allows building on generated files before they are written.
+func (p *Package) Update(path string, src []byte) error {
+	f, err := parser.ParseFile(p.Fset, path, src, parser.ParseComments)
+	if err != nil {
+		return err
+	}
+	file := &File{Path: path, Src: src, AST: f}
+	for i, existing := range p.Files {
+		if existing.Path == path {
+			p.Files[i] = file
+			return nil
+		}
+	}
+	p.Files = append(p.Files, file)
+	sort.Slice(p.Files, func(i, j int) bool {
+		return p.Files[i].Path < p.Files[j].Path
+	})
+	return nil
+}
+
+// Func finds the top-level function declaration with the given name. Test
+// files are ignored.
+func (p *Package) Func(name string) (*File, *ast.FuncDecl, bool) {
+	for _, f := range p.Files {
+		if strings.HasSuffix(f.Path, "_test.go") {
+			continue
+		}
+		for _, decl := range f.AST.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if ok && fn.Recv == nil && fn.Name.Name == name {
+				return f, fn, true
+			}
+		}
+	}
+	return nil, nil, false
+}
+
+// Position returns the position of the node in the form 'file:line'.
+func (p *Package) Position(n ast.Node) string {
+	pos := p.Fset.Position(n.Pos())
+	return fmt.Sprintf("%s:%d", pos.Filename, pos.Line)
+}
+
+// offset returns the byte offset of the position in its file.
+func (p *Package) offset(pos token.Pos) int {
+	return p.Fset.Position(pos).Offset
+}
+
+// edit replaces the bytes between start and end with the text.
+type edit struct {
+	start int
+	end   int
+	text  string
+}
+
+// insert returns the edit that inserts the text at the offset.
+func insert(offset int, text string) edit {
+	return edit{start: offset, end: offset, text: text}
+}
+
+// splice applies the edits to the source and formats the result. The edits
+// must not overlap.
+func splice(src []byte, edits ...edit) ([]byte, error) {
+	sort.SliceStable(edits, func(i, j int) bool {
+		return edits[i].start < edits[j].start
+	})
+	var out []byte
+	last := 0
+	for _, e := range edits {
+		out = append(out, src[last:e.start]...)
+		out = append(out, e.text...)
+		last = e.end
+	}
+	out = append(out, src[last:]...)
+	return format.Source(out)
+}
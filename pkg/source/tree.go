@@ -0,0 +1,395 @@
+// Copyright 2020 oncilla
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package source
+
+import (
+	"bytes"
+	"go/ast"
+	"go/printer"
+	"go/token"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Tree is the statically analysed command tree of a package.
+type Tree struct {
+	// Root is the root command created in the main function.
+	Root *Command `json:"root"`
+	// Orphans are the commands whose constructors are not registered with
+	// any command in the tree.
+	Orphans []*Command `json:"orphans,omitempty"`
+}
+
+// Command is a command in the command tree.
+type Command struct {
+	// Constructor is the name of the function that creates the command. For
+	// the root command, this is 'main'.
+	Constructor string `json:"constructor"`
+	// Use is the one-line usage message.
+	Use string `json:"use"`
+	// Short is the short description.
+	Short string `json:"short,omitempty"`
+	// Aliases are the aliases of the command.
+	Aliases []string `json:"aliases,omitempty"`
+	// Flags are the flags that are registered in the constructor.
+	Flags []Flag `json:"flags,omitempty"`
+	// Position is the position of the constructor in the form 'file:line'.
+	// The file is relative to the package directory.
+	Position string `json:"position"`
+	// Commands are the registered subcommands.
+	Commands []*Command `json:"commands,omitempty"`
+}
+
+// Name returns the command name, i.e., the first word of Use.
+func (c *Command) Name() string {
+	if fields := strings.Fields(c.Use); len(fields) > 0 {
+		return fields[0]
+	}
+	return ""
+}
+
+// Flag is a flag registered with a command.
+type Flag struct {
+	Name      string `json:"name"`
+	Shorthand string `json:"shorthand,omitempty"`
+	// Type is the pflag type name, e.g., stringSlice. For flags registered
+	// with Var, the type cannot be determined and is reported as 'value'.
+	Type string `json:"type"`
+	// Default is the default value as written in the source, e.g., "name"
+	// including the quotes for a string literal.
+	Default    string `json:"default,omitempty"`
+	Usage      string `json:"usage,omitempty"`
+	Persistent bool   `json:"persistent,omitempty"`
+}
+
+// Tree statically analyses the package and returns the command tree. The
+// tree starts at the command created in the main function and follows the
+// constructors registered in AddCommand calls. Every function that returns a
+// *cobra.Command and takes a CommandPather is considered a constructor.
+func (p *Package) Tree() *Tree {
+	visited := map[string]bool{"main": true}
+	tree := &Tree{}
+	if file, fn, ok := p.Func("main"); ok {
+		tree.Root = p.command(file, fn, visited)
+	}
+
+	// Orphans are the unvisited constructors that are not registered by
+	// another unvisited constructor.
+	registered := map[string]bool{}
+	var unvisited []string
+	for _, f := range p.Files {
+		for _, decl := range f.AST.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || visited[fn.Name.Name] || !isConstructor(fn) || p.isTest(f) {
+				continue
+			}
+			unvisited = append(unvisited, fn.Name.Name)
+			for _, child := range children(fn) {
+				if child != fn.Name.Name {
+					registered[child] = true
+				}
+			}
+		}
+	}
+	for _, name := range unvisited {
+		if registered[name] || visited[name] {
+			continue
+		}
+		file, fn, _ := p.Func(name)
+		tree.Orphans = append(tree.Orphans, p.command(file, fn, visited))
+	}
+	return tree
+}
+
+// command analyses the constructor and its registered subcommands.
+func (p *Package) command(file *File, fn *ast.FuncDecl, visited map[string]bool) *Command {
+	visited[fn.Name.Name] = true
+	c := &Command{
+		Constructor: fn.Name.Name,
+		Position:    p.relPosition(fn),
+	}
+	if lit := cobraCommand(fn); lit != nil {
+		for _, elt := range lit.Elts {
+			kv, ok := elt.(*ast.KeyValueExpr)
+			if !ok {
+				continue
+			}
+			key, ok := kv.Key.(*ast.Ident)
+			if !ok {
+				continue
+			}
+			switch key.Name {
+			case "Use":
+				c.Use = p.text(kv.Value)
+			case "Short":
+				c.Short = p.text(kv.Value)
+			case "Aliases":
+				if aliases, ok := kv.Value.(*ast.CompositeLit); ok {
+					for _, alias := range aliases.Elts {
+						c.Aliases = append(c.Aliases, p.text(alias))
+					}
+				}
+			}
+		}
+	}
+	c.Flags = p.flags(fn)
+	for _, child := range children(fn) {
+		if visited[child] {
+			continue
+		}
+		if childFile, childFn, ok := p.Func(child); ok && isConstructor(childFn) {
+			c.Commands = append(c.Commands, p.command(childFile, childFn, visited))
+		}
+	}
+	return c
+}
+
+// flags returns the flags registered in the function with calls such as
+// 'cmd.Flags().StringVarP(&flags.name, "name", "n", "", "usage")'.
+func (p *Package) flags(fn *ast.FuncDecl) []Flag {
+	var flags []Flag
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		set, ok := sel.X.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		setSel, ok := set.Fun.(*ast.SelectorExpr)
+		if !ok || (setSel.Sel.Name != "Flags" && setSel.Sel.Name != "PersistentFlags") {
+			return true
+		}
+		if f, ok := p.flag(sel.Sel.Name, call.Args); ok {
+			f.Persistent = setSel.Sel.Name == "PersistentFlags"
+			flags = append(flags, f)
+		}
+		return true
+	})
+	return flags
+}
+
+// flag analyses the arguments of a flag definition. The method is the pflag
+// method that is called, e.g., StringVarP.
+func (p *Package) flag(method string, args []ast.Expr) (Flag, bool) {
+	typ, ptr, shorthand, ok := parseMethod(method)
+	if !ok {
+		return Flag{}, false
+	}
+	if ptr {
+		// The first argument is the pointer to the variable.
+		if len(args) == 0 {
+			return Flag{}, false
+		}
+		args = args[1:]
+	}
+	if typ == "" {
+		// Var and VarP take the value instead of the default.
+		if len(args) < 2 {
+			return Flag{}, false
+		}
+		f := Flag{Name: p.text(args[0]), Type: "value", Usage: p.text(args[len(args)-1])}
+		if shorthand && len(args) == 3 {
+			f.Shorthand = p.text(args[1])
+		}
+		return f, true
+	}
+	want := 3
+	if shorthand {
+		want = 4
+	}
+	if typ == "Count" {
+		// Count flags do not take a default value.
+		want--
+	}
+	if len(args) != want {
+		return Flag{}, false
+	}
+	f := Flag{
+		Name:  p.text(args[0]),
+		Type:  FlagType(method),
+		Usage: p.text(args[want-1]),
+	}
+	if typ != "Count" {
+		f.Default = p.source(args[want-2])
+	}
+	if shorthand {
+		f.Shorthand = p.text(args[1])
+	}
+	return f, true
+}
+
+// FlagType returns the type name that is reported for flags registered with
+// the pflag method, e.g., stringSlice for StringSliceVarP and value for Var.
+// If the method does not define a flag, the empty string is returned.
+func FlagType(method string) string {
+	typ, _, _, ok := parseMethod(method)
+	switch {
+	case !ok:
+		return ""
+	case typ == "":
+		return "value"
+	}
+	return lowerCamel(typ)
+}
+
+// pflagTypes contains the types of the typed pflag methods. For each type,
+// e.g., IP, the methods IP, IPP, IPVar and IPVarP exist.
+var pflagTypes = map[string]bool{
+	"Bool": true, "BoolSlice": true, "BytesBase64": true, "BytesHex": true,
+	"Count": true, "Duration": true, "DurationSlice": true,
+	"Float32": true, "Float32Slice": true, "Float64": true, "Float64Slice": true,
+	"IP": true, "IPMask": true, "IPNet": true, "IPSlice": true,
+	"Int": true, "Int8": true, "Int16": true, "Int32": true, "Int64": true,
+	"IntSlice": true, "Int32Slice": true, "Int64Slice": true,
+	"String": true, "StringArray": true, "StringSlice": true,
+	"StringToInt": true, "StringToInt64": true, "StringToString": true,
+	"Uint": true, "Uint8": true, "Uint16": true, "Uint32": true, "Uint64": true,
+	"UintSlice": true,
+}
+
+// parseMethod splits the pflag method into the type and the variant. The type
+// is empty for Var and VarP. The suffixes are only stripped if the remainder
+// is a known type, such that IP is not mistaken for the shorthand variant of
+// a type I. It returns false, if the method does not define a flag.
+func parseMethod(method string) (typ string, ptr, shorthand, ok bool) {
+	variants := []struct {
+		suffix         string
+		ptr, shorthand bool
+	}{
+		{"VarP", true, true}, {"Var", true, false}, {"P", false, true}, {"", false, false},
+	}
+	for _, v := range variants {
+		if !strings.HasSuffix(method, v.suffix) {
+			continue
+		}
+		typ = strings.TrimSuffix(method, v.suffix)
+		if (typ == "" && v.ptr) || pflagTypes[typ] {
+			return typ, v.ptr, v.shorthand, true
+		}
+	}
+	return "", false, false, false
+}
+
+// text returns the value of a string literal, or the source of any other
+// expression.
+func (p *Package) text(expr ast.Expr) string {
+	if lit, ok := expr.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+		if s, err := strconv.Unquote(lit.Value); err == nil {
+			return s
+		}
+	}
+	return p.source(expr)
+}
+
+// source returns the source of the expression.
+func (p *Package) source(expr ast.Expr) string {
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, p.Fset, expr); err != nil {
+		return ""
+	}
+	return buf.String()
+}
+
+// relPosition returns the position of the node in the form 'file:line' with
+// the file relative to the package directory.
+func (p *Package) relPosition(n ast.Node) string {
+	pos := p.Fset.Position(n.Pos())
+	name := pos.Filename
+	if rel, err := filepath.Rel(p.Dir, name); err == nil {
+		name = rel
+	}
+	return name + ":" + strconv.Itoa(pos.Line)
+}
+
+// isTest indicates whether the file is a test file.
+func (p *Package) isTest(f *File) bool {
+	return strings.HasSuffix(f.Path, "_test.go")
+}
+
+// isConstructor indicates whether the function is a command constructor, i.e.,
+// it returns a *cobra.Command and takes a CommandPather.
+func isConstructor(fn *ast.FuncDecl) bool {
+	if fn.Recv != nil || fn.Body == nil || patherParam(fn) == "" {
+		return false
+	}
+	results := fn.Type.Results
+	if results == nil || len(results.List) != 1 {
+		return false
+	}
+	star, ok := results.List[0].Type.(*ast.StarExpr)
+	if !ok {
+		return false
+	}
+	sel, ok := star.X.(*ast.SelectorExpr)
+	return ok && sel.Sel.Name == "Command"
+}
+
+// children returns the constructors registered in AddCommand calls in the
+// function in order of appearance.
+func children(fn *ast.FuncDecl) []string {
+	var names []string
+	for _, call := range addCommandCalls(fn) {
+		for _, arg := range call.Args {
+			if name := calledFunc(arg); name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// cobraCommand returns the first cobra.Command composite literal in the
+// function.
+func cobraCommand(fn *ast.FuncDecl) *ast.CompositeLit {
+	var lit *ast.CompositeLit
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		if lit != nil {
+			return false
+		}
+		c, ok := n.(*ast.CompositeLit)
+		if !ok {
+			return true
+		}
+		if sel, ok := c.Type.(*ast.SelectorExpr); ok && sel.Sel.Name == "Command" {
+			lit = c
+			return false
+		}
+		return true
+	})
+	return lit
+}
+
+// lowerCamel converts the pflag method type to the pflag type name, e.g.,
+// StringSlice to stringSlice and IPNet to ipNet.
+func lowerCamel(s string) string {
+	r := []rune(s)
+	for i := range r {
+		if !unicode.IsUpper(r[i]) {
+			break
+		}
+		if i > 0 && i+1 < len(r) && unicode.IsLower(r[i+1]) {
+			break
+		}
+		r[i] = unicode.ToLower(r[i])
+	}
+	return string(r)
+}
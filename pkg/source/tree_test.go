@@ -0,0 +1,115 @@
+// Copyright 2020 oncilla
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package source_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oncilla/boa/pkg/source"
+)
+
+func TestTree(t *testing.T) {
+	files := map[string]string{
+		"serve.go": `package main
+
+func newServe(pather CommandPather) *cobra.Command {
+	var flags struct {
+		addr net.IP
+		mode *flag.Enum
+	}
+	cmd := &cobra.Command{
+		Use:     "serve <arg>",
+		Short:   "Serve " + "requests",
+		Aliases: []string{"run", "start"},
+	}
+	cmd.Flags().IPVar(&flags.addr, "addr", net.IPv4zero, "listen address")
+	cmd.Flags().VarP(flags.mode, "mode", "m", "serving mode")
+	cmd.Flags().IP("bind", nil, "bind address")
+	cmd.Flags().IPP("peer", "p", nil, "peer address")
+	cmd.Flags().CountP("verbose", "v", "verbosity")
+	cmd.PersistentFlags().StringP("log", "l", "info", "log level")
+	cmd.AddCommand(newOrphan(cmd))
+	return cmd
+}
+
+func newOrphan(pather CommandPather) *cobra.Command {
+	return &cobra.Command{Use: "orphan"}
+}
+
+func newHelper() *cobra.Command {
+	return &cobra.Command{Use: "helper"}
+}
+`,
+	}
+	for name, src := range treeFiles {
+		files[name] = src
+	}
+	p, cleanup := load(t, files)
+	defer cleanup()
+
+	tree := p.Tree()
+	require.NotNil(t, tree.Root)
+	assert.Equal(t, "main", tree.Root.Constructor)
+	assert.Equal(t, "app", tree.Root.Name())
+	assert.Equal(t, "app.go:3", tree.Root.Position)
+	require.Len(t, tree.Root.Commands, 2)
+
+	greet := tree.Root.Commands[0]
+	assert.Equal(t, "newGreet", greet.Constructor)
+	assert.Equal(t, "greet <arg>", greet.Use)
+	remote := tree.Root.Commands[1]
+	require.Len(t, remote.Commands, 1)
+	assert.Equal(t, "add", remote.Commands[0].Name())
+	assert.Equal(t, "remote_add.go:3", remote.Commands[0].Position)
+
+	// newServe is not registered, newOrphan is registered by newServe, and
+	// newHelper is not a constructor.
+	require.Len(t, tree.Orphans, 1)
+	serve := tree.Orphans[0]
+	assert.Equal(t, "newServe", serve.Constructor)
+	assert.Equal(t, `"Serve " + "requests"`, serve.Short)
+	assert.Equal(t, []string{"run", "start"}, serve.Aliases)
+	assert.Equal(t, []source.Flag{
+		{Name: "addr", Type: "ip", Default: "net.IPv4zero", Usage: "listen address"},
+		{Name: "mode", Shorthand: "m", Type: "value", Usage: "serving mode"},
+		{Name: "bind", Type: "ip", Default: "nil", Usage: "bind address"},
+		{Name: "peer", Shorthand: "p", Type: "ip", Default: "nil", Usage: "peer address"},
+		{Name: "verbose", Shorthand: "v", Type: "count", Usage: "verbosity"},
+		{Name: "log", Shorthand: "l", Type: "string", Default: `"info"`, Usage: "log level",
+			Persistent: true},
+	}, serve.Flags)
+	require.Len(t, serve.Commands, 1)
+	assert.Equal(t, "newOrphan", serve.Commands[0].Constructor)
+}
+
+func TestFlagType(t *testing.T) {
+	testCases := map[string]string{
+		"IP":               "ip",
+		"IPP":              "ip",
+		"IPVarP":           "ip",
+		"IPMask":           "ipMask",
+		"StringSliceVarP":  "stringSlice",
+		"Uint8P":           "uint8",
+		"Var":              "value",
+		"VarP":             "value",
+		"MarkHidden":       "",
+		"SetNormalizeFunc": "",
+	}
+	for method, typ := range testCases {
+		assert.Equal(t, typ, source.FlagType(method), method)
+	}
+}
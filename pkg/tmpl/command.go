@@ -20,13 +20,14 @@ const Command = `{{ if .Copyright.Author }}// Copyright {{.Copyright.Year}} {{ .
 package main
 
 import (
-	"fmt"{{ range $element := .Imports }}
+	"fmt"{{ range $element := .StdImports }}
 	"{{$element}}"{{end}}
 
-	"github.com/spf13/cobra"
+	"github.com/spf13/cobra"{{ range $element := .ThirdPartyImports }}
+	"{{$element}}"{{end}}
 )
 
-func new{{ .UpperName }}(pather CommandPather) *cobra.Command {
+func {{ .Constructor }}(pather CommandPather) *cobra.Command {
 	{{ if eq (len .Flags) 0 }}var flags struct{
 		sample bool
 	} {{ else }} var flags struct { {{ range .Flags }}
@@ -34,21 +35,123 @@ func new{{ .UpperName }}(pather CommandPather) *cobra.Command {
 	} {{ end }}
 
 	var cmd = &cobra.Command{
-		Use:     "{{.Name}} <arg>",
-		Short:   "{{.Name}} does amazing work!",
-		Example: fmt.Sprintf("  %[1]s {{.Name}} --sample", pather.CommandPath()),
+		Use:     "{{.Use}}",
+		{{- if .Aliases }}
+		Aliases: []string{ {{- range $i, $alias := .Aliases }}{{ if $i }}, {{ end }}{{ printf "%q" $alias }}{{ end }}},
+		{{- end }}
+		Short:   {{ if .Short }}{{ printf "%q" .Short }}{{ else }}"{{.Name}} does amazing work!"{{ end }},
+		{{- if .Long }}
+		Long:    {{ .LongExpr }},
+		{{- end }}
+		Example: {{ .ExampleExpr }},
+		{{- if .Args }}
+		Args:    {{ .ArgsValidator }},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			{{- if eq .CompletePaths "true" }}
+			return nil, cobra.ShellCompDirectiveDefault
+			{{- else }}
+			{{- if .CompletePaths }}
+			if {{ .CompletePaths }} {
+				return nil, cobra.ShellCompDirectiveDefault
+			}
+			{{- end }}
+			return nil, cobra.ShellCompDirectiveNoFileComp
+			{{- end }}
+		},
+		{{- end }}
 		RunE: func(cmd *cobra.Command, args []string) error {
+			{{- if .Args }}
+			var positional struct { {{ range .Args }}
+				{{.Name}} {{.FieldType}}{{ end }}
+			}
+			{{- if .ParsesArgs }}
+			var err error
+			{{- end }}
+			{{- range .Args }}
+			{{- if .Variadic }}
+			{{ if gt .Index $.MinArgs }}if len(args) > {{.Index}} {
+			{{ end }}
+			{{- if .Parse }}for _, arg := range args{{ if .Index }}[{{.Index}}:]{{ end }} {
+				v, err := {{ .ParseExpr "arg" }}
+				if err != nil {
+					return fmt.Errorf("invalid argument <{{.Name}}>: %s", err)
+				}
+				positional.{{.Name}} = append(positional.{{.Name}}, v)
+			}{{ else }}positional.{{.Name}} = args{{ if .Index }}[{{.Index}}:]{{ end }}{{ end }}
+			{{- if gt .Index $.MinArgs }}
+			}{{ end }}
+			{{- else }}
+			{{ if .Optional }}if len(args) > {{.Index}} {
+			{{ end }}
+			{{- if .Parse }}if positional.{{.Name}}, err = {{ .ParseExpr (printf "args[%d]" .Index) }}; err != nil {
+				return fmt.Errorf("invalid argument <{{.Name}}>: %s", err)
+			}{{ else }}positional.{{.Name}} = args[{{.Index}}]{{ end }}
+			{{- if .Optional }}
+			}{{ end }}
+			{{- end }}
+			{{- end }}
+			{{ end }}
+			{{- range .Flags }}{{ if .OutOfRange }}
+			if cmd.Flags().Changed("{{.FlagName}}") && {{ .OutOfRange }} {
+				return fmt.Errorf("--{{.FlagName}} must be in range [{{.Range}}], got %v", flags.{{.Name}})
+			}
+			{{ end }}{{ if .Pattern }}
+			if cmd.Flags().Changed("{{.FlagName}}") && !regexp.MustCompile({{ printf "%#q" .Pattern }}).MatchString(flags.{{.Name}}) {
+				return fmt.Errorf("--{{.FlagName}} must match %s, got %q", {{ printf "%#q" .Pattern }}, flags.{{.Name}})
+			}
+			{{ end }}{{ if eq .Path "exists" }}
+			if cmd.Flags().Changed("{{.FlagName}}") {
+				if _, err := os.Stat(flags.{{.Name}}); err != nil {
+					return fmt.Errorf("--{{.FlagName}}: %s", err)
+				}
+			}
+			{{ else if .Path }}
+			if cmd.Flags().Changed("{{.FlagName}}") {
+				info, err := os.Stat(flags.{{.Name}})
+				if err != nil {
+					return fmt.Errorf("--{{.FlagName}}: %s", err)
+				}
+				if {{ if eq .Path "dir" }}!{{ end }}info.IsDir() {
+					return fmt.Errorf("--{{.FlagName}}: %s is {{ if eq .Path "file" }}a{{ else }}not a{{ end }} directory", flags.{{.Name}})
+				}
+			}
+			{{ end }}{{ end }}
 			// Add basic sanity checks, where the usage help message should be
 			// printed on error, before this line. After this line, the usage
 			// message is no longer printed on error.
 			cmd.SilenceUsage = true
+			{{- if .Call }}
+
+			{{ if .Call.Options }}opts := {{ .Call.Options }}{ {{ range .OptionFlags }}
+				{{ .Field }}: {{ .Value }},{{ end }}
+			}
+			{{ end }}
+			{{- if and .Call.Result .Call.Error }}result, err := {{ .Call.Expr }}
+			if err != nil {
+				return err
+			}
+			return boa.Print(cmd.OutOrStdout(), flags.output.String(), result)
+			{{- else if .Call.Result }}result := {{ .Call.Expr }}
+			return boa.Print(cmd.OutOrStdout(), flags.output.String(), result)
+			{{- else if .Call.Error }}return {{ .Call.Expr }}
+			{{- else }}{{ .Call.Expr }}
+			return nil
+			{{- end }}
+			{{- else }}
 
 			// TODO: Amazing work goes here!
 			return nil
+			{{- end }}
 		},
 	}
-	{{ if eq (len .Flags) 0 }}cmd.Flags().BoolVarP(&flags.sample, "sample", "s", false, "sample flag"){{else}} {{ range .Flags }}
-	cmd.Flags().{{.Register}}(&flags.{{.Name}}, "{{.Name}}", {{.Default}}, "{{.Name}} description") {{ end }} {{ end }}
-	return cmd
+	{{ if eq (len .Flags) 0 }}cmd.Flags().BoolVarP(&flags.sample, "sample", "s", false, "sample flag"){{else}} {{ range .Flags }}{{ if .IsVar }}
+	flags.{{.Name}} = {{.Default}}
+	cmd.Flags().Var{{ if .Short }}P{{ end }}(flags.{{.Name}}, "{{.FlagName}}", {{ if .Short }}"{{.Short}}", {{ end }}{{ printf "%q" .Usage }}) {{ else }}
+	cmd.Flags().{{.Register}}{{ if .Short }}P{{ end }}(&flags.{{.Name}}, "{{.FlagName}}", {{ if .Short }}"{{.Short}}", {{ end }}{{.Default}}, {{ printf "%q" .Usage }}) {{ end }}{{ end }} {{ end }}
+	{{ range .Flags }}{{ if .Required }}if err := cmd.MarkFlagRequired("{{.FlagName}}"); err != nil {
+		panic(err)
+	}
+	{{ end }}{{ end }}{{ if .Completions }}boa.RegisterCompletions(cmd)
+	{{ end }}return cmd
 }
 `
@@ -0,0 +1,189 @@
+// Copyright 2020 oncilla
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tmpl
+
+// CommandConfig is the template for creating new commands whose flags are
+// fields of a config struct.
+const CommandConfig = `{{ if .Copyright.Author }}// Copyright {{.Copyright.Year}} {{ .Copyright.Author }}{{ end }}
+{{ if .License.Commented }}{{ .License.Commented }}{{ end }}
+
+package main
+
+import (
+	"fmt"{{ range $element := .StdImports }}
+	"{{$element}}"{{end}}
+
+	"github.com/spf13/cobra"{{ range $element := .ThirdPartyImports }}
+	"{{$element}}"{{end}}
+)
+
+// {{ .ConfigType }} is the configuration of the {{ .Name }} command. The values
+// are set by flags, environment variables prefixed with {{ .EnvPrefix }}_, or the
+// config file, in this order of precedence.
+type {{ .ConfigType }} struct { {{ range .Flags }}
+	{{ .Field }} {{ .ConfigType }} ` + "`" + `{{ .ConfigTag }}` + "`" + `{{ end }}
+}
+
+func {{ .DefaultConfig }}() {{ .ConfigType }} {
+	return {{ .ConfigType }}{ {{ range .Flags }}
+		{{ .Field }}: {{ .ConfigDefault }},{{ end }}
+	}
+}
+
+func {{ .Constructor }}(pather CommandPather) *cobra.Command {
+	var flags struct {
+		config string
+	}
+	cfg := {{ .DefaultConfig }}()
+	v := viper.New()
+	v.SetEnvPrefix("{{ .EnvPrefix }}")
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_", "-", "_"))
+
+	var cmd = &cobra.Command{
+		Use:     "{{.Use}}",
+		{{- if .Aliases }}
+		Aliases: []string{ {{- range $i, $alias := .Aliases }}{{ if $i }}, {{ end }}{{ printf "%q" $alias }}{{ end }}},
+		{{- end }}
+		Short:   {{ if .Short }}{{ printf "%q" .Short }}{{ else }}"{{.Name}} does amazing work!"{{ end }},
+		{{- if .Long }}
+		Long:    {{ .LongExpr }},
+		{{- end }}
+		Example: {{ .ExampleExpr }},
+		{{- if .Args }}
+		Args:    {{ .ArgsValidator }},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			{{- if eq .CompletePaths "true" }}
+			return nil, cobra.ShellCompDirectiveDefault
+			{{- else }}
+			{{- if .CompletePaths }}
+			if {{ .CompletePaths }} {
+				return nil, cobra.ShellCompDirectiveDefault
+			}
+			{{- end }}
+			return nil, cobra.ShellCompDirectiveNoFileComp
+			{{- end }}
+		},
+		{{- end }}
+		RunE: func(cmd *cobra.Command, args []string) error {
+			{{- if .Args }}
+			var positional struct { {{ range .Args }}
+				{{.Name}} {{.FieldType}}{{ end }}
+			}
+			{{- if .ParsesArgs }}
+			var err error
+			{{- end }}
+			{{- range .Args }}
+			{{- if .Variadic }}
+			{{ if gt .Index $.MinArgs }}if len(args) > {{.Index}} {
+			{{ end }}
+			{{- if .Parse }}for _, arg := range args{{ if .Index }}[{{.Index}}:]{{ end }} {
+				v, err := {{ .ParseExpr "arg" }}
+				if err != nil {
+					return fmt.Errorf("invalid argument <{{.Name}}>: %s", err)
+				}
+				positional.{{.Name}} = append(positional.{{.Name}}, v)
+			}{{ else }}positional.{{.Name}} = args{{ if .Index }}[{{.Index}}:]{{ end }}{{ end }}
+			{{- if gt .Index $.MinArgs }}
+			}{{ end }}
+			{{- else }}
+			{{ if .Optional }}if len(args) > {{.Index}} {
+			{{ end }}
+			{{- if .Parse }}if positional.{{.Name}}, err = {{ .ParseExpr (printf "args[%d]" .Index) }}; err != nil {
+				return fmt.Errorf("invalid argument <{{.Name}}>: %s", err)
+			}{{ else }}positional.{{.Name}} = args[{{.Index}}]{{ end }}
+			{{- if .Optional }}
+			}{{ end }}
+			{{- end }}
+			{{- end }}
+			{{ end }}
+			if flags.config != "" {
+				v.SetConfigFile(flags.config)
+				if err := v.ReadInConfig(); err != nil {
+					return err
+				}
+			}
+			hooks := viper.DecodeHook(
+				mapstructure.ComposeDecodeHookFunc(boa.DefaultDecodeHooks()...),
+			)
+			if err := v.Unmarshal(&cfg, hooks); err != nil {
+				return err
+			}
+			{{- if .ConfigChecks }}
+			// Values that differ from the defaults are checked against the
+			// constraints. The defaults have been checked on generation.
+			defaults := {{ .DefaultConfig }}()
+			{{- end }}
+			{{- range .Flags }}{{ if .Values }}
+			if err := {{ .Default }}.Set(cfg.{{.Field}}); err != nil {
+				return fmt.Errorf("{{.Key}}: %s", err)
+			}
+			{{ end }}{{ if .OutOfRange }}
+			if cfg.{{.Field}} != defaults.{{.Field}} && {{ .ConfigOutOfRange }} {
+				return fmt.Errorf("{{.Key}} must be in range [{{.Range}}], got %v", cfg.{{.Field}})
+			}
+			{{ end }}{{ if .Pattern }}
+			if cfg.{{.Field}} != defaults.{{.Field}} && !regexp.MustCompile({{ printf "%#q" .Pattern }}).MatchString(cfg.{{.Field}}) {
+				return fmt.Errorf("{{.Key}} must match %s, got %q", {{ printf "%#q" .Pattern }}, cfg.{{.Field}})
+			}
+			{{ end }}{{ if eq .Path "exists" }}
+			if cfg.{{.Field}} != defaults.{{.Field}} {
+				if _, err := os.Stat(cfg.{{.Field}}); err != nil {
+					return fmt.Errorf("{{.Key}}: %s", err)
+				}
+			}
+			{{ else if .Path }}
+			if cfg.{{.Field}} != defaults.{{.Field}} {
+				info, err := os.Stat(cfg.{{.Field}})
+				if err != nil {
+					return fmt.Errorf("{{.Key}}: %s", err)
+				}
+				if {{ if eq .Path "dir" }}!{{ end }}info.IsDir() {
+					return fmt.Errorf("{{.Key}}: %s is {{ if eq .Path "file" }}a{{ else }}not a{{ end }} directory", cfg.{{.Field}})
+				}
+			}
+			{{ end }}{{ end }}
+			// Add basic sanity checks, where the usage help message should be
+			// printed on error, before this line. After this line, the usage
+			// message is no longer printed on error.
+			cmd.SilenceUsage = true
+
+			// TODO: Amazing work goes here!
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&flags.config, "config", "", "path to the config file")
+	// The config struct is static. Errors are programming errors that are
+	// detected by boa.Check in the tests. The schema is compiled once and
+	// shared by the flags, defaults and environment variables.
+	schema, err := boa.Compile(&cfg)
+	if err != nil {
+		panic(err)
+	}
+	if err := schema.AddFlags(cmd.Flags()); err != nil {
+		panic(err)
+	}
+	schema.SetDefaults(v)
+	if err := schema.BindEnv(v); err != nil {
+		panic(err)
+	}
+	if err := v.BindPFlags(cmd.Flags()); err != nil {
+		panic(err)
+	}
+	{{- range .Flags }}
+	cmd.Flags().Lookup("{{.Key}}").Usage = {{ printf "%q" .Usage }}
+	{{- end }}
+	{{ if .Completions }}boa.RegisterCompletions(cmd)
+	{{ end }}return cmd
+}
+`
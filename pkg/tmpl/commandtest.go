@@ -0,0 +1,106 @@
+// Copyright 2020 oncilla
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tmpl
+
+// CommandTest is the template for creating the test file of new commands.
+const CommandTest = `{{ if .Copyright.Author }}// Copyright {{.Copyright.Year}} {{ .Copyright.Author }}{{ end }}
+{{ if .License.Commented }}{{ .License.Commented }}{{ end }}
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"testing"
+{{ if .Config }}
+	"github.com/oncilla/boa/pkg/boa"{{ end }}
+	"github.com/spf13/cobra"
+)
+
+func {{ .TestFunc }}(t *testing.T) {
+	testCases := map[string]struct {
+		Args  []string
+		Error bool
+	}{
+		{{- if not .Call }}
+		{{ if .RequiredFlagArgs "" }}"required flags"{{ else }}"no flags"{{ end }}: {
+			Args: []string{ {{- range .RequiredArgs "" }}{{ . }}, {{ end }}},
+		},
+		{{- if eq (len .Flags) 0 }}
+		"sample": {
+			Args: []string{ {{- range .RequiredArgs "" }}{{ . }}, {{ end }}"--sample"},
+		},
+		{{- end }}
+		{{- range .Flags }}{{ if .SampleArg }}
+		"{{ .Name }}": {
+			Args: []string{ {{- range $.RequiredArgs .Name }}{{ . }}, {{ end }}{{ .SampleArg }}},
+		},
+		{{- end }}{{ end }}
+		{{- end }}
+		{{- range .Flags }}{{ if .Required }}
+		"missing {{ .Name }}": {
+			Args:  []string{ {{- range $.RequiredArgs .Name }}{{ . }}, {{ end }}},
+			Error: true,
+		},
+		{{- end }}{{ end }}
+		{{- if .MinArgs }}
+		"missing arguments": {
+			Args:  []string{ {{- range .RequiredFlagArgs "" }}{{ . }}, {{ end }}},
+			Error: true,
+		},
+		{{- end }}
+		{{- if .ExcessArgs }}
+		"too many arguments": {
+			Args:  []string{ {{- range .ExcessArgs }}{{ . }}, {{ end }}},
+			Error: true,
+		},
+		{{- end }}
+		"unknown flag": {
+			Args:  []string{ {{- range .RequiredArgs "" }}{{ . }}, {{ end }}"--unknown"},
+			Error: true,
+		},
+		{{- if .Config }}
+		"missing config file": {
+			Args:  []string{ {{- range .RequiredArgs "" }}{{ . }}, {{ end }}"--config=missing.yaml"},
+			Error: true,
+		},
+		{{- end }}
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			var out bytes.Buffer
+			cmd := {{ .Constructor }}(&cobra.Command{Use: "app"})
+			cmd.SetOut(&out)
+			cmd.SetErr(&out)
+			cmd.SetArgs(tc.Args)
+			err := cmd.Execute()
+			if tc.Error && err == nil {
+				t.Fatalf("expected error, output:\n%s", out.String())
+			}
+			if !tc.Error && err != nil {
+				t.Fatalf("unexpected error: %s\noutput:\n%s", err, out.String())
+			}
+		})
+	}
+}
+{{- if .Config }}
+
+func {{ .TestFunc }}Config(t *testing.T) {
+	cfg := {{ .DefaultConfig }}()
+	if err := boa.Check(&cfg); err != nil {
+		t.Fatal(err)
+	}
+}
+{{- end }}
+`
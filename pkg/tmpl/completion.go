@@ -58,6 +58,10 @@ To permanently add bash autocompletion, run:
 		},
 	}
 	cmd.Flags().StringVar(&flags.shell, "shell", "bash", "Shell type (bash|zsh|fish)")
+	// The error is ignored, the flag is registered above.
+	_ = cmd.RegisterFlagCompletionFunc("shell", func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+		return []string{"bash", "zsh", "fish"}, cobra.ShellCompDirectiveNoFileComp
+	})
 	return cmd
 }
 `
@@ -0,0 +1,57 @@
+// Copyright 2020 oncilla
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tmpl
+
+// MultiVersion is the template for the version command of the multi preset.
+// It reports the version of the shared version package.
+const MultiVersion = `{{ if .Copyright.Author }}// Copyright {{.Copyright.Year}} {{ .Copyright.Author }}{{ end }}
+{{ if .License.Commented }}{{ .License.Commented }}{{ end }}
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"{{ .Module }}/internal/version"
+)
+
+func newVersion(pather CommandPather) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:     "version",
+		Short:   "Show the version information",
+		Example: fmt.Sprintf("  %[1]s version", pather.CommandPath()),
+		Run: func(cmd *cobra.Command, args []string) {
+			fmt.Println(version.Version)
+		},
+	}
+	return cmd
+}
+`
+
+// SharedVersion is the template for the version package that is shared by all
+// binaries of the multi preset.
+const SharedVersion = `{{ if .Copyright.Author }}// Copyright {{.Copyright.Year}} {{ .Copyright.Author }}{{ end }}
+{{ if .License.Commented }}{{ .License.Commented }}{{ end }}
+
+// Package version contains the version information that is shared by all
+// binaries in this project.
+package version
+
+// Version is the version of the binaries. It can be set at build time:
+//
+//	go build -ldflags "-X {{ .Module }}/internal/version.Version=v1.0.0" ./cmd/...
+var Version = "v0.1.0"
+`
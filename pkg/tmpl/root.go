@@ -20,6 +20,7 @@ const Root = `{{ if .Copyright.Author }}// Copyright {{.Copyright.Year}} {{ .Cop
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
@@ -37,13 +38,23 @@ func main() {
 		Short:         "{{.Name}} does amazing work!",
 		SilenceErrors: true,
 	}
-	cmd.AddCommand(
-		newCompletion(cmd),
-		newVersion(cmd),
+	cmd.AddCommand({{ range .Commands }}
+		{{ . }}(cmd),{{ end }}
 	)
 	if err := cmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 }
+
+// exitCode returns the exit code for the error. Errors that implement
+// 'ExitCode() int' determine the exit code themselves, e.g., *exec.ExitError.
+// All other errors result in the exit code 1.
+func exitCode(err error) int {
+	var coder interface{ ExitCode() int }
+	if errors.As(err, &coder) {
+		return coder.ExitCode()
+	}
+	return 1
+}
 `
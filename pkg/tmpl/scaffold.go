@@ -0,0 +1,61 @@
+// Copyright 2020 oncilla
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tmpl
+
+// GoMod is the template for the go.mod file of the project. The requirements
+// are pinned, such that no network access is needed to create the project.
+const GoMod = `module {{ .Module }}
+
+go 1.13
+
+require (
+{{- range .Requires }}
+	{{ . }}
+{{- end }}
+)
+`
+
+// License is the template for the LICENSE file of the project.
+const License = `{{ .LicenseText }}`
+
+// Gitignore is the template for the .gitignore file of the project.
+const Gitignore = `# Binaries built by 'make build'.
+/bin/
+
+# Test binaries and coverage profiles.
+*.test
+*.out
+`
+
+// Makefile is the template for the Makefile of the project.
+const Makefile = `build:
+{{- if .Preset.Main }}
+	for cmd in {{ .Preset.Main }}/*; do go build -v -o bin/$$(basename $$cmd) ./$$cmd; done
+{{- else }}
+	go build -v -o bin/{{ .Name }} .
+{{- end }}
+
+install:
+{{- if .Preset.Main }}
+	go install -v ./{{ .Preset.Main }}/...
+{{- else }}
+	go install -v .
+{{- end }}
+
+test:
+	go test -v ./...
+
+lint:
+	golangci-lint run
+`
@@ -0,0 +1,288 @@
+// Copyright 2020 oncilla
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tmpl
+
+// Serve is the template for the serve command of the server and daemon
+// presets.
+const Serve = `{{ if .Copyright.Author }}// Copyright {{.Copyright.Year}} {{ .Copyright.Author }}{{ end }}
+{{ if .License.Commented }}{{ .License.Commented }}{{ end }}
+
+package main
+
+import (
+	"context"
+	"fmt"
+	{{- if eq .Preset.Name "daemon" }}
+	"log"
+	{{- end }}
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/oncilla/boa/pkg/boa"
+	"github.com/oncilla/boa/pkg/boa/flag"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// serveConfig is the configuration of the serve command. The values are set
+// by flags, environment variables prefixed with {{ .EnvPrefix }}_, or the
+// config file, in this order of precedence.
+type serveConfig struct {
+	Addr            flag.TCPAddr  ` + "`" + `mapstructure:"addr"` + "`" + `
+	ShutdownTimeout time.Duration ` + "`" + `mapstructure:"shutdown-timeout"` + "`" + `
+	{{- if eq .Preset.Name "daemon" }}
+	Pidfile         string        ` + "`" + `mapstructure:"pidfile"` + "`" + `
+	LogFile         string        ` + "`" + `mapstructure:"log-file"` + "`" + `
+	{{- end }}
+}
+
+func defaultServeConfig() serveConfig {
+	return serveConfig{
+		Addr:            flag.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 8080},
+		ShutdownTimeout: 10 * time.Second,
+	}
+}
+
+func newServe(pather CommandPather) *cobra.Command {
+	var flags struct {
+		config string
+	}
+	cfg := defaultServeConfig()
+	v := viper.New()
+	v.SetEnvPrefix("{{ .EnvPrefix }}")
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_", "-", "_"))
+
+	var cmd = &cobra.Command{
+		Use:     "serve",
+		Short:   "Serve requests until interrupted",
+		Example: fmt.Sprintf("  %[1]s serve --addr 127.0.0.1:8080", pather.CommandPath()),
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if flags.config != "" {
+				v.SetConfigFile(flags.config)
+				if err := v.ReadInConfig(); err != nil {
+					return err
+				}
+			}
+			err := v.Unmarshal(&cfg, viper.DecodeHook(
+				mapstructure.ComposeDecodeHookFunc(boa.DefaultDecodeHooks()...),
+			))
+			if err != nil {
+				return err
+			}
+			cmd.SilenceUsage = true
+			{{- if eq .Preset.Name "daemon" }}
+
+			logs, err := openLog(cfg.LogFile)
+			if err != nil {
+				return err
+			}
+			defer logs.Close()
+			if cfg.Pidfile != "" {
+				remove, err := writePidfile(cfg.Pidfile)
+				if err != nil {
+					return err
+				}
+				defer remove()
+			}
+			{{- end }}
+
+			server := &http.Server{
+				Addr:    cfg.Addr.String(),
+				Handler: http.NotFoundHandler(),
+			}
+			errs := make(chan error, 1)
+			go func() {
+				errs <- server.ListenAndServe()
+			}()
+			{{- if eq .Preset.Name "daemon" }}
+			log.Printf("Listening on %s", cfg.Addr.String())
+
+			signals := make(chan os.Signal, 1)
+			signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+			defer signal.Stop(signals)
+			for {
+				select {
+				case err := <-errs:
+					return err
+				case s := <-signals:
+					if s == syscall.SIGHUP {
+						// Reopen the log file, e.g., after it has been rotated.
+						if err := logs.Reopen(); err != nil {
+							log.Printf("Reopening log file failed: %s", err)
+						}
+						continue
+					}
+					log.Printf("Received %s, shutting down", s)
+				}
+				break
+			}
+			{{- else }}
+			fmt.Fprintln(cmd.OutOrStdout(), "Listening on", cfg.Addr.String())
+
+			signals := make(chan os.Signal, 1)
+			signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+			defer signal.Stop(signals)
+			select {
+			case err := <-errs:
+				return err
+			case s := <-signals:
+				fmt.Fprintf(cmd.OutOrStdout(), "Received %s, shutting down\n", s)
+			}
+			{{- end }}
+
+			// Stop accepting new connections and wait for the active ones
+			// to finish.
+			ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+			defer cancel()
+			return server.Shutdown(ctx)
+		},
+	}
+	cmd.Flags().StringVar(&flags.config, "config", "", "path to the config file")
+	// The config struct is static. Errors are programming errors that are
+	// detected by boa.Check in the tests. The schema is compiled once and
+	// shared by the flags, defaults and environment variables.
+	schema, err := boa.Compile(&cfg)
+	if err != nil {
+		panic(err)
+	}
+	if err := schema.AddFlags(cmd.Flags()); err != nil {
+		panic(err)
+	}
+	schema.SetDefaults(v)
+	if err := schema.BindEnv(v); err != nil {
+		panic(err)
+	}
+	if err := v.BindPFlags(cmd.Flags()); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+`
+
+// ServeTest is the template for the tests of the serve command.
+const ServeTest = `{{ if .Copyright.Author }}// Copyright {{.Copyright.Year}} {{ .Copyright.Author }}{{ end }}
+{{ if .License.Commented }}{{ .License.Commented }}{{ end }}
+
+package main
+
+import (
+	"testing"
+
+	"github.com/oncilla/boa/pkg/boa"
+)
+
+func TestServeConfig(t *testing.T) {
+	cfg := defaultServeConfig()
+	if err := boa.Check(&cfg); err != nil {
+		t.Fatal(err)
+	}
+}
+`
+
+// Daemon is the template for the pidfile and log file handling of the daemon
+// preset.
+const Daemon = `{{ if .Copyright.Author }}// Copyright {{.Copyright.Year}} {{ .Copyright.Author }}{{ end }}
+{{ if .License.Commented }}{{ .License.Commented }}{{ end }}
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+)
+
+// writePidfile writes the process ID to the file. It fails if the file
+// already exists, which indicates that another instance is running. The
+// returned function removes the file.
+func writePidfile(file string) (func(), error) {
+	f, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		if os.IsExist(err) {
+			raw, _ := ioutil.ReadFile(file)
+			return nil, fmt.Errorf("pidfile %s exists, is process %s still running?", file, raw)
+		}
+		return nil, err
+	}
+	if _, err := f.WriteString(strconv.Itoa(os.Getpid()) + "\n"); err != nil {
+		f.Close()
+		os.Remove(file)
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(file)
+		return nil, err
+	}
+	return func() { os.Remove(file) }, nil
+}
+
+// logFile is the destination of the standard logger. It can be reopened to
+// support log rotation.
+type logFile struct {
+	mtx  sync.Mutex
+	path string
+	file *os.File
+}
+
+// openLog sets the output of the standard logger to the file. If the path is
+// empty, the logs are written to stderr.
+func openLog(path string) (*logFile, error) {
+	l := &logFile{path: path}
+	if err := l.Reopen(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Reopen closes and reopens the log file.
+func (l *logFile) Reopen() error {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	if l.path == "" {
+		log.SetOutput(os.Stderr)
+		return nil
+	}
+	f, err := os.OpenFile(l.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	log.SetOutput(f)
+	if l.file != nil {
+		l.file.Close()
+	}
+	l.file = f
+	return nil
+}
+
+// Close restores the output of the standard logger and closes the log file.
+func (l *logFile) Close() error {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	log.SetOutput(os.Stderr)
+	if l.file == nil {
+		return nil
+	}
+	return l.file.Close()
+}
+`
@@ -0,0 +1,197 @@
+// Copyright 2020 oncilla
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tmpl contains the templates for generating cobra applications.
+//
+// The built-in templates can be overridden by placing a template with the same
+// name in a template directory. Templates with other names are extra files
+// that are rendered by 'boa init' in addition to the built-in ones. The file
+// name of the rendered file is the template name without the '.tmpl' suffix.
+//
+// Presets add templates to the built-in ones, e.g., serve.go.tmpl for the
+// server preset. The names of preset templates may contain a directory, which
+// is relative to the project root.
+//
+// The scaffolding templates, i.e., go.mod.tmpl, LICENSE.tmpl, .gitignore.tmpl
+// and Makefile.tmpl, are rendered in the project root. Each of them can be
+// disabled with a flag of 'boa init'.
+//
+// The project templates, i.e., root.go.tmpl, completion.go.tmpl,
+// version.go.tmpl, the scaffolding templates and all extra templates, are
+// executed with gen.Project as data:
+//
+//	.Name            name of the application
+//	.Copyright.Year  year of the copyright attribution
+//	.Copyright.Author
+//	                 author of the copyright attribution, empty if disabled
+//	.License.Name    name of the license
+//	.License.Text    full text of the license
+//	.License.Commented
+//	                 license header as Go comment, empty if disabled
+//	.Vars            custom variables from the project manifest
+//	.Module          module path of the project root, if known
+//	.EnvPrefix       prefix of the environment variables, e.g., MY_APP
+//	.Preset.Name     name of the project preset, e.g., cli or server
+//	.Commands        constructors that are registered with the root command
+//	.Requires        pinned requirements of the go.mod file
+//	.LicenseText     full text of the license with the copyright filled in
+//
+// The command templates, i.e., command.go.tmpl, command_config.go.tmpl and
+// command_test.go.tmpl, are executed with gen.Command as data. The command is
+// rendered from command_config.go.tmpl instead of command.go.tmpl, if its
+// flags are fields of a config struct. In addition to .Copyright, .License and
+// .Vars, they provide:
+//
+//	.Name            name of the command
+//	.Parents         names of the parent commands, excluding the root
+//	.Constructor     name of the constructor, e.g., newRemoteAdd
+//	.TestFunc        name of the test function, e.g., TestRemoteAdd
+//	.Short           short description, empty for the placeholder
+//	.Long            long description, with .LongExpr as Go string literal
+//	.Aliases         aliases of the command
+//	.Flags           flags with .Name, .Type, .Default, .Register, .Values,
+//	                 .Short, .Usage, .Required, the constraints .Range,
+//	                 .OutOfRange, .Pattern and .Path, and .Sample and
+//	                 .SampleArg, a valid value and argument for tests. In
+//	                 config structs, the flags are fields with .Field, .Key,
+//	                 .ConfigType, .ConfigDefault, .ConfigTag and
+//	                 .ConfigOutOfRange
+//	.Args            positional arguments with .Name, .Type, .Index,
+//	                 .Optional, .Variadic, .Path, .Placeholder, .FieldType,
+//	                 .ParseExpr and .Sample
+//	.Use             usage line, e.g., copy <src> [dst]
+//	.ArgsValidator   cobra.PositionalArgs for the positional arguments
+//	.MinArgs         number of required positional arguments
+//	.ParsesArgs      whether non-variadic positional arguments are parsed
+//	.CompletePaths   condition on args for completing file names
+//	.RequiredArgs    arguments that are required to run the command, except
+//	                 the flag with the provided name
+//	.RequiredFlagArgs
+//	                 arguments that set the required flags, except the one
+//	                 with the provided name, to valid values
+//	.ExcessArgs      arguments with one positional argument too many
+//	.StdImports      imports from the standard library
+//	.ThirdPartyImports
+//	                 other imports, excluding cobra
+//	.Completions     whether flag completions need to be registered
+//	.Config          whether the flags are fields of a config struct
+//	.ConfigType      name of the config struct, e.g., remoteAddConfig
+//	.DefaultConfig   name of the function returning the default config
+//	.ConfigChecks    whether the config values are checked against the
+//	                 constraints of the flags
+//	.EnvPrefix       prefix of the environment variables, e.g., MY_APP
+//	.Call            function that is called by the command, if any, with
+//	                 .Func, .Args, .Options, .Result, .Error and .Expr, the
+//	                 Go expression of the call
+//	.OptionFlags     flags that set the fields of the options passed to the
+//	                 function, with .Field and .Value, the Go expression of
+//	                 the field value
+package tmpl
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Names of the built-in templates.
+const (
+	RootName          = "root.go.tmpl"
+	CommandName       = "command.go.tmpl"
+	CommandConfigName = "command_config.go.tmpl"
+	CommandTestName   = "command_test.go.tmpl"
+	CompletionName    = "completion.go.tmpl"
+	VersionName       = "version.go.tmpl"
+	GoModName         = "go.mod.tmpl"
+	LicenseName       = "LICENSE.tmpl"
+	GitignoreName     = ".gitignore.tmpl"
+	MakefileName      = "Makefile.tmpl"
+)
+
+// Suffix is the file name suffix of templates.
+const Suffix = ".tmpl"
+
+// Templates maps the template names to the template text.
+type Templates map[string]string
+
+// Builtin returns the built-in templates.
+func Builtin() Templates {
+	return Templates{
+		RootName:          Root,
+		CommandName:       Command,
+		CommandConfigName: CommandConfig,
+		CommandTestName:   CommandTest,
+		CompletionName:    Completion,
+		VersionName:       Version,
+		GoModName:         GoMod,
+		LicenseName:       License,
+		GitignoreName:     Gitignore,
+		MakefileName:      Makefile,
+	}
+}
+
+// Load returns the built-in templates overridden by the templates found in
+// the directories. Templates in later directories take precedence. Only files
+// with the '.tmpl' suffix are considered. Missing directories are ignored.
+func Load(dirs ...string) (Templates, error) {
+	return Overlay(Builtin(), dirs...)
+}
+
+// Overlay returns a copy of the base templates overridden by the templates
+// found in the directories, in the same way as Load.
+func Overlay(base Templates, dirs ...string) (Templates, error) {
+	t := make(Templates, len(base))
+	for name, text := range base {
+		t[name] = text
+	}
+	for _, dir := range dirs {
+		files, err := ioutil.ReadDir(dir)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			if file.IsDir() || !strings.HasSuffix(file.Name(), Suffix) {
+				continue
+			}
+			raw, err := ioutil.ReadFile(filepath.Join(dir, file.Name()))
+			if err != nil {
+				return nil, err
+			}
+			t[file.Name()] = string(raw)
+		}
+	}
+	return t, nil
+}
+
+// Extra returns the sorted names of the templates that are not built-in.
+func (t Templates) Extra() []string {
+	builtin := Builtin()
+	var names []string
+	for name := range t {
+		if _, ok := builtin[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// FileName returns the name of the file rendered from the template.
+func FileName(name string) string {
+	return strings.TrimSuffix(name, Suffix)
+}
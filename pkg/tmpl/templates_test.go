@@ -0,0 +1,52 @@
+// Copyright 2020 oncilla
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tmpl_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oncilla/boa/pkg/tmpl"
+)
+
+func TestLoad(t *testing.T) {
+	user, err := ioutil.TempDir("", "user")
+	require.NoError(t, err)
+	defer os.RemoveAll(user)
+	project, err := ioutil.TempDir("", "project")
+	require.NoError(t, err)
+	defer os.RemoveAll(project)
+
+	write := func(dir, name, text string) {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(text), 0644))
+	}
+	write(user, tmpl.VersionName, "user version")
+	write(user, tmpl.CommandName, "user command")
+	write(user, "README.md.tmpl", "user readme")
+	write(project, tmpl.CommandName, "project command")
+	write(project, "notes.txt", "ignored")
+
+	templates, err := tmpl.Load(user, project, filepath.Join(project, "missing"))
+	require.NoError(t, err)
+	assert.Equal(t, tmpl.Root, templates[tmpl.RootName])
+	assert.Equal(t, "user version", templates[tmpl.VersionName])
+	assert.Equal(t, "project command", templates[tmpl.CommandName])
+	assert.Equal(t, []string{"README.md.tmpl"}, templates.Extra())
+	assert.Equal(t, "README.md", tmpl.FileName("README.md.tmpl"))
+}